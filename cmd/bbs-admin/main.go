@@ -2,12 +2,16 @@ package main
 
 import (
 	"bufio"
+	"database/sql"
 	"fmt"
 	"log"
+	"modern-bbs/internal/control"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/database/migrations"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 )
@@ -20,35 +24,94 @@ func getEnv(key, fallback string) string {
 }
 
 func main() {
-	dbPath := getEnv("BBS_DB_PATH", "bbs.db")
-	if err := database.InitDB(dbPath); err != nil {
-		log.Fatalf("Erro ao inicializar o banco de dados em '%s': %v", dbPath, err)
-	}
-
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
+	dbPath := getEnv("BBS_DB_PATH", "bbs.db")
+
+	// O comando migrate gerencia o próprio esquema, então não pode depender do InitDB,
+	// que aplica as migrações pendentes e se recusa a abrir bancos inconsistentes.
+	if os.Args[1] == "migrate" {
+		db, err := database.OpenDB(dbPath)
+		if err != nil {
+			log.Fatalf("Erro ao abrir o banco de dados em '%s': %v", dbPath, err)
+		}
+		defer db.Close()
+		handleMigrate(db, os.Args[2:])
+		return
+	}
+
+	// Os comandos de sessão falam com o servidor em execução, e não com o banco de dados.
+	switch os.Args[1] {
+	case "who":
+		handleWho()
+		return
+	case "kick":
+		handleKick(os.Args[2:])
+		return
+	case "broadcast":
+		handleBroadcast(os.Args[2:])
+		return
+	case "shutdown":
+		handleShutdown(os.Args[2:])
+		return
+	}
+
+	store, err := database.InitDB(dbPath)
+	if err != nil {
+		log.Fatalf("Erro ao inicializar o banco de dados em '%s': %v", dbPath, err)
+	}
+	defer store.Close()
+
 	switch os.Args[1] {
 	case "adduser":
-		handleAddUser()
+		handleAddUser(store)
 	case "addforum":
-		handleAddForum()
+		handleAddForum(store)
 	case "setrole":
-		handleSetRole()
+		handleSetRole(store)
 	case "deleteuser":
-		handleDeleteUser()
+		handleDeleteUser(store)
 	case "resetpassword":
-		handleResetPassword()
+		handleResetPassword(store)
 	case "editforum":
-		handleEditForum()
+		handleEditForum(store)
 	case "deleteforum":
-		handleDeleteForum()
+		handleDeleteForum(store)
 	case "deletetopic":
-		handleDeleteTopic()
+		handleDeleteTopic(store)
 	case "deletepost":
-		handleDeletePost()
+		handleDeletePost(store)
+	case "addkey":
+		handleAddKey(store)
+	case "listkeys":
+		handleListKeys(store)
+	case "removekey":
+		handleRemoveKey(store)
+	case "passwordlogin":
+		handlePasswordLogin(store)
+	case "pending":
+		handlePending(store)
+	case "approve":
+		handleApprove(store, os.Args[2:])
+	case "lockouts":
+		handleLockouts(store)
+	case "unlock":
+		handleUnlock(store, os.Args[2:])
+	case "invite":
+		handleInvite(store, os.Args[2:])
+	case "reset2fa":
+		handleReset2FA(store, os.Args[2:])
+	case "guestforum":
+		handleGuestForum(store, os.Args[2:])
+	case "forumperm":
+		handleForumPerm(store, os.Args[2:])
+	case "forummod":
+		handleForumMod(store, os.Args[2:])
+	case "trash":
+		handleTrash(store, os.Args[2:])
 	default:
 		fmt.Printf("Comando desconhecido: %s\n", os.Args[1])
 		printUsage()
@@ -65,12 +128,38 @@ func printUsage() {
 	fmt.Println("  deleteuser - Deleta um usuário")
 	fmt.Println("  resetpassword - Reseta a senha de um usuário")
 	fmt.Println("  editforum     - Edita um fórum existente")
-	fmt.Println("  deleteforum   - Deleta um fórum")
-	fmt.Println("  deletetopic   - Deleta um tópico")
-	fmt.Println("  deletepost    - Deleta um post")
+	fmt.Println("  deleteforum   - Move um fórum para a lixeira")
+	fmt.Println("  deletetopic   - Move um tópico para a lixeira")
+	fmt.Println("  deletepost    - Move um post para a lixeira")
+	fmt.Println("  addkey        - Adiciona uma chave SSH pública a um usuário")
+	fmt.Println("  listkeys      - Lista as chaves SSH de um usuário")
+	fmt.Println("  removekey     - Remove uma chave SSH de um usuário")
+	fmt.Println("  passwordlogin - Habilita ou desabilita o login por senha de um usuário")
+	fmt.Println("  pending       - Lista os cadastros que aguardam aprovação")
+	fmt.Println("  approve <usuário> - Aprova o cadastro de um usuário")
+	fmt.Println("  lockouts      - Lista as falhas de login e os bloqueios por IP e por usuário, e os cadastros por IP")
+	fmt.Println("  unlock ip|user|register <alvo> - Apaga as falhas de login ou os cadastros de um IP ou usuário, desbloqueando-o")
+	fmt.Println("  invite create [usos] [dias] | invite list | invite revoke <código>")
+	fmt.Println("                - Cria (padrão: 1 uso, 7 dias; 0 dias para sem prazo), lista ou revoga convites de cadastro")
+	fmt.Println("  reset2fa <usuário> - Desativa a verificação em duas etapas de quem perdeu o aplicativo e os códigos")
+	fmt.Println("  guestforum [<id do fórum> on|off]")
+	fmt.Println("                - Lista os fóruns abertos a visitantes, ou abre e fecha um fórum a eles")
+	fmt.Println("  forumperm list <id do fórum> | forumperm grant|revoke <id do fórum> <permissão> role|user <nome>")
+	fmt.Println("                - Lista, concede ou revoga permissões no fórum (view, topic, reply, moderate)")
+	fmt.Println("  forummod list <id do fórum> | forummod add|remove <id do fórum> <usuário>")
+	fmt.Println("                - Lista, designa ou retira os moderadores de um fórum")
+	fmt.Println("  trash list | trash restore|purge forum|topic|post <id> | trash empty [dias]")
+	fmt.Println("                - Lista a lixeira, restaura ou remove um item, ou remove os apagados há mais de [dias] (padrão: todos)")
+	fmt.Println("  migrate status|up|down [n]|force <versão> [applied|pending]")
+	fmt.Println("                - Gerencia as migrações do esquema do banco de dados")
+	fmt.Println("  who           - Lista as sessões conectadas ao servidor em execução")
+	fmt.Println("  kick <id>     - Desconecta uma sessão do servidor em execução")
+	fmt.Println("  broadcast <aviso> - Exibe um aviso para todas as sessões conectadas")
+	fmt.Println("  shutdown <minutos> [motivo] | shutdown cancel")
+	fmt.Println("                - Programa ou cancela o desligamento do servidor, com contagem regressiva nas sessões")
 }
 
-func handleAddUser() {
+func handleAddUser(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o nome do usuário: ")
@@ -85,7 +174,7 @@ func handleAddUser() {
 	password := string(bytePassword)
 	fmt.Println()
 
-	_, err = database.CreateUser(username, password)
+	_, err = store.CreateUser(username, password)
 	if err != nil {
 		log.Fatalf("Erro ao criar usuário: %v", err)
 	}
@@ -93,7 +182,7 @@ func handleAddUser() {
 	fmt.Printf("Usuário '%s' criado com sucesso!\n", username)
 }
 
-func handleAddForum() {
+func handleAddForum(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o nome do fórum: ")
@@ -104,7 +193,7 @@ func handleAddForum() {
 	description, _ := reader.ReadString('\n')
 	description = strings.TrimSpace(description)
 
-	forum, err := database.CreateForum(name, description)
+	forum, err := store.CreateForum(name, description)
 	if err != nil {
 		log.Fatalf("Erro ao criar fórum: %v", err)
 	}
@@ -112,7 +201,7 @@ func handleAddForum() {
 	fmt.Printf("Fórum '%s' (ID: %d) criado com sucesso!\n", forum.Name, forum.ID)
 }
 
-func handleSetRole() {
+func handleSetRole(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o nome do usuário: ")
@@ -123,28 +212,28 @@ func handleSetRole() {
 	role, _ := reader.ReadString('\n')
 	role = strings.TrimSpace(role)
 
-	if err := database.SetUserRole(username, role); err != nil {
+	if err := store.SetUserRole(username, role); err != nil {
 		log.Fatalf("Erro ao definir o papel do usuário: %v", err)
 	}
 
 	fmt.Printf("O papel do usuário '%s' foi definido como '%s' com sucesso!\n", username, role)
 }
 
-func handleDeleteUser() {
+func handleDeleteUser(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o nome do usuário a ser deletado: ")
 	username, _ := reader.ReadString('\n')
 	username = strings.TrimSpace(username)
 
-	if err := database.DeleteUser(username); err != nil {
+	if err := store.DeleteUser(username); err != nil {
 		log.Fatalf("Erro ao deletar usuário: %v", err)
 	}
 
 	fmt.Printf("Usuário '%s' deletado com sucesso!\n", username)
 }
 
-func handleResetPassword() {
+func handleResetPassword(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o nome do usuário: ")
@@ -159,14 +248,14 @@ func handleResetPassword() {
 	password := string(bytePassword)
 	fmt.Println()
 
-	if err := database.AdminResetPassword(username, password); err != nil {
+	if err := store.AdminResetPassword(username, password); err != nil {
 		log.Fatalf("Erro ao resetar a senha: %v", err)
 	}
 
 	fmt.Printf("Senha do usuário '%s' resetada com sucesso!\n", username)
 }
 
-func handleEditForum() {
+func handleEditForum(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o ID do fórum a ser editado: ")
@@ -185,14 +274,14 @@ func handleEditForum() {
 	description, _ := reader.ReadString('\n')
 	description = strings.TrimSpace(description)
 
-	if err := database.UpdateForum(id, name, description); err != nil {
+	if err := store.UpdateForum(id, name, description); err != nil {
 		log.Fatalf("Erro ao editar fórum: %v", err)
 	}
 
 	fmt.Printf("Fórum ID %d atualizado com sucesso!\n", id)
 }
 
-func handleDeleteForum() {
+func handleDeleteForum(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o ID do fórum a ser deletado: ")
@@ -203,14 +292,18 @@ func handleDeleteForum() {
 		log.Fatalf("ID do fórum inválido: %v", err)
 	}
 
-	if err := database.DeleteForum(id); err != nil {
+	fmt.Print("Motivo (opcional): ")
+	reason, _ := reader.ReadString('\n')
+	reason = strings.TrimSpace(reason)
+
+	if err := store.DeleteForum(id, 0, reason); err != nil {
 		log.Fatalf("Erro ao deletar fórum: %v", err)
 	}
 
-	fmt.Printf("Fórum ID %d deletado com sucesso!\n", id)
+	fmt.Printf("Fórum ID %d movido para a lixeira.\n", id)
 }
 
-func handleDeleteTopic() {
+func handleDeleteTopic(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o ID do tópico a ser deletado: ")
@@ -221,14 +314,18 @@ func handleDeleteTopic() {
 		log.Fatalf("ID do tópico inválido: %v", err)
 	}
 
-	if err := database.DeleteTopic(id); err != nil {
+	fmt.Print("Motivo (opcional): ")
+	reason, _ := reader.ReadString('\n')
+	reason = strings.TrimSpace(reason)
+
+	if err := store.AdminDeleteTopic(id, reason); err != nil {
 		log.Fatalf("Erro ao deletar tópico: %v", err)
 	}
 
-	fmt.Printf("Tópico ID %d deletado com sucesso!\n", id)
+	fmt.Printf("Tópico ID %d movido para a lixeira.\n", id)
 }
 
-func handleDeletePost() {
+func handleDeletePost(store database.Store) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Digite o ID do post a ser deletado: ")
@@ -239,9 +336,621 @@ func handleDeletePost() {
 		log.Fatalf("ID do post inválido: %v", err)
 	}
 
-	if err := database.DeletePost(id); err != nil {
+	fmt.Print("Motivo (opcional): ")
+	reason, _ := reader.ReadString('\n')
+	reason = strings.TrimSpace(reason)
+
+	if err := store.AdminDeletePost(id, reason); err != nil {
 		log.Fatalf("Erro ao deletar post: %v", err)
 	}
 
-	fmt.Printf("Post ID %d deletado com sucesso!\n", id)
+	fmt.Printf("Post ID %d movido para a lixeira.\n", id)
+}
+
+func handleAddKey(store database.Store) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Digite o nome do usuário: ")
+	username, _ := reader.ReadString('\n')
+	username = strings.TrimSpace(username)
+
+	fmt.Print("Cole a chave pública (formato authorized_keys): ")
+	authorizedKey, _ := reader.ReadString('\n')
+	authorizedKey = strings.TrimSpace(authorizedKey)
+
+	fmt.Print("Digite um comentário (opcional): ")
+	comment, _ := reader.ReadString('\n')
+	comment = strings.TrimSpace(comment)
+
+	key, err := store.AddUserKey(username, authorizedKey, comment)
+	if err != nil {
+		log.Fatalf("Erro ao adicionar chave: %v", err)
+	}
+
+	fmt.Printf("Chave %s adicionada ao usuário '%s' com sucesso!\n", key.Fingerprint, username)
+}
+
+func handleListKeys(store database.Store) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Digite o nome do usuário: ")
+	username, _ := reader.ReadString('\n')
+	username = strings.TrimSpace(username)
+
+	keys, err := store.GetUserKeys(username)
+	if err != nil {
+		log.Fatalf("Erro ao listar chaves: %v", err)
+	}
+
+	disabled, err := store.IsPasswordLoginDisabled(username)
+	if err != nil {
+		log.Fatalf("Erro ao consultar login por senha: %v", err)
+	}
+
+	if len(keys) == 0 {
+		fmt.Printf("O usuário '%s' não possui chaves cadastradas.\n", username)
+	}
+	for _, key := range keys {
+		lastUsed := "nunca"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s %s %s (adicionada: %s, último uso: %s)\n",
+			key.KeyType, key.Fingerprint, key.Comment, key.AddedAt.Format("2006-01-02 15:04:05"), lastUsed)
+	}
+
+	if disabled {
+		fmt.Println("Login por senha: desabilitado")
+	} else {
+		fmt.Println("Login por senha: habilitado")
+	}
+}
+
+func handleRemoveKey(store database.Store) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Digite o nome do usuário: ")
+	username, _ := reader.ReadString('\n')
+	username = strings.TrimSpace(username)
+
+	fmt.Print("Digite o fingerprint da chave (SHA256:...): ")
+	fingerprint, _ := reader.ReadString('\n')
+	fingerprint = strings.TrimSpace(fingerprint)
+
+	if err := store.RemoveUserKey(username, fingerprint); err != nil {
+		log.Fatalf("Erro ao remover chave: %v", err)
+	}
+
+	fmt.Printf("Chave %s removida do usuário '%s' com sucesso!\n", fingerprint, username)
+}
+
+func handlePasswordLogin(store database.Store) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Digite o nome do usuário: ")
+	username, _ := reader.ReadString('\n')
+	username = strings.TrimSpace(username)
+
+	fmt.Print("Permitir login por senha? (s/n): ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	var disabled bool
+	switch answer {
+	case "s":
+		disabled = false
+	case "n":
+		disabled = true
+	default:
+		log.Fatalf("Resposta inválida: %s", answer)
+	}
+
+	if err := store.SetPasswordLoginDisabled(username, disabled); err != nil {
+		log.Fatalf("Erro ao atualizar login por senha: %v", err)
+	}
+
+	if disabled {
+		fmt.Printf("Login por senha desabilitado para '%s'.\n", username)
+	} else {
+		fmt.Printf("Login por senha habilitado para '%s'.\n", username)
+	}
+}
+
+func handlePending(store database.Store) {
+	users, err := store.GetAllUsers()
+	if err != nil {
+		log.Fatalf("Erro ao listar usuários: %v", err)
+	}
+
+	found := false
+	for _, user := range users {
+		if !user.Pending() {
+			continue
+		}
+		if !found {
+			fmt.Printf("%-20s %-30s %s\n", "USUÁRIO", "E-MAIL", "CADASTRO")
+			found = true
+		}
+		fmt.Printf("%-20s %-30s %s\n", user.Username, user.Email, user.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if !found {
+		fmt.Println("Nenhum cadastro aguardando aprovação.")
+	}
+}
+
+func handleApprove(store database.Store, args []string) {
+	if len(args) != 1 {
+		fmt.Println("Uso: bbs-admin approve <usuário>")
+		os.Exit(1)
+	}
+	if err := store.ApproveUser(args[0]); err != nil {
+		log.Fatalf("Erro ao aprovar cadastro: %v", err)
+	}
+	fmt.Printf("Cadastro de '%s' aprovado.\n", args[0])
+}
+
+func handleLockouts(store database.Store) {
+	lockouts, err := store.GetLockouts()
+	if err != nil {
+		log.Fatalf("Erro ao listar bloqueios: %v", err)
+	}
+	if len(lockouts) == 0 {
+		fmt.Println("Nenhuma falha de login registrada.")
+		return
+	}
+
+	now := time.Now()
+	fmt.Printf("%-8s %-40s %-7s %-20s %s\n", "TIPO", "ALVO", "FALHAS", "ÚLTIMA FALHA", "BLOQUEIO")
+	for _, l := range lockouts {
+		status := "-"
+		if l.Locked(now) {
+			status = fmt.Sprintf("até %s (%s)", l.LockedUntil.Local().Format("2006-01-02 15:04:05"),
+				l.LockedUntil.Sub(now).Truncate(time.Second))
+		}
+		fmt.Printf("%-8s %-40s %-7d %-20s %s\n", l.Scope, l.Target, l.Failures,
+			l.LastFailureAt.Local().Format("2006-01-02 15:04:05"), status)
+	}
+}
+
+func handleUnlock(store database.Store, args []string) {
+	if len(args) != 2 || (args[0] != database.LockoutScopeIP && args[0] != database.LockoutScopeUser &&
+		args[0] != database.LockoutScopeRegister) {
+		fmt.Println("Uso: bbs-admin unlock ip|user|register <alvo>")
+		os.Exit(1)
+	}
+	found, err := store.DeleteLockout(args[0], args[1])
+	if err != nil {
+		log.Fatalf("Erro ao desbloquear: %v", err)
+	}
+	if !found {
+		log.Fatalf("Nenhuma falha de login registrada para %s '%s'.", args[0], args[1])
+	}
+	fmt.Printf("Falhas de login de %s '%s' apagadas.\n", args[0], args[1])
+}
+
+func handleInvite(store database.Store, args []string) {
+	if len(args) == 0 {
+		fmt.Println("Uso: bbs-admin invite create [usos] [dias] | invite list | invite revoke <código>")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "create":
+		uses, days := 1, 7
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Número de usos inválido: %s", args[1])
+			}
+			uses = n
+		}
+		if len(args) > 2 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 0 {
+				log.Fatalf("Número de dias inválido: %s", args[2])
+			}
+			days = n
+		}
+		var expiresAt *time.Time
+		if days > 0 {
+			t := time.Now().AddDate(0, 0, days)
+			expiresAt = &t
+		}
+		invite, err := store.CreateInvite(0, uses, expiresAt)
+		if err != nil {
+			log.Fatalf("Erro ao criar convite: %v", err)
+		}
+		fmt.Println(invite.Code)
+	case "list":
+		invites, err := store.GetInvites()
+		if err != nil {
+			log.Fatalf("Erro ao listar convites: %v", err)
+		}
+		if len(invites) == 0 {
+			fmt.Println("Nenhum convite criado.")
+			return
+		}
+		now := time.Now()
+		fmt.Printf("%-10s %-20s %-6s %-20s %s\n", "CÓDIGO", "CRIADO POR", "USOS", "VALIDADE", "SITUAÇÃO")
+		for _, invite := range invites {
+			creator := invite.CreatorName
+			if invite.CreatedBy == 0 {
+				creator = "administração"
+			}
+			expires := "sem prazo"
+			if invite.ExpiresAt != nil {
+				expires = invite.ExpiresAt.Local().Format("2006-01-02 15:04:05")
+			}
+			status := "ativo"
+			if invite.Uses >= invite.MaxUses {
+				status = "esgotado"
+			} else if !invite.Active(now) {
+				status = "expirado"
+			}
+			fmt.Printf("%-10s %-20s %-6s %-20s %s\n", invite.Code, creator,
+				fmt.Sprintf("%d/%d", invite.Uses, invite.MaxUses), expires, status)
+		}
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Println("Uso: bbs-admin invite revoke <código>")
+			os.Exit(1)
+		}
+		if err := store.RevokeInvite(args[1]); err != nil {
+			log.Fatalf("Erro ao revogar convite: %v", err)
+		}
+		fmt.Printf("Convite %s revogado.\n", strings.ToUpper(args[1]))
+	default:
+		fmt.Printf("Subcomando desconhecido: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleReset2FA(store database.Store, args []string) {
+	if len(args) != 1 {
+		fmt.Println("Uso: bbs-admin reset2fa <usuário>")
+		os.Exit(1)
+	}
+	if err := store.DisableTOTP(args[0]); err != nil {
+		log.Fatalf("Erro ao desativar a verificação em duas etapas: %v", err)
+	}
+	fmt.Printf("Verificação em duas etapas de '%s' desativada. Se o papel do usuário a exigir, a ativação será pedida no próximo login.\n", args[0])
+}
+
+func handleGuestForum(store database.Store, args []string) {
+	if len(args) == 0 {
+		forums, err := store.GetGuestForums()
+		if err != nil {
+			log.Fatalf("Erro ao listar fóruns: %v", err)
+		}
+		if len(forums) == 0 {
+			fmt.Println("Nenhum fórum aberto a visitantes.")
+			return
+		}
+		for _, f := range forums {
+			fmt.Printf("%d\t%s\n", f.ID, f.Name)
+		}
+		return
+	}
+
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		fmt.Println("Uso: bbs-admin guestforum [<id do fórum> on|off]")
+		os.Exit(1)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		log.Fatalf("ID do fórum inválido: %v", err)
+	}
+	allowed := args[1] == "on"
+	if err := store.SetForumGuestAccess(id, allowed); err != nil {
+		log.Fatalf("Erro ao atualizar o acesso de visitantes: %v", err)
+	}
+	if allowed {
+		fmt.Printf("Fórum ID %d aberto a visitantes.\n", id)
+	} else {
+		fmt.Printf("Fórum ID %d fechado a visitantes.\n", id)
+	}
+}
+
+func handleForumPerm(store database.Store, args []string) {
+	const usage = "Uso: bbs-admin forumperm list <id do fórum> | forumperm grant|revoke <id do fórum> <permissão> role|user <nome>"
+	if len(args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	forumID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("ID do fórum inválido: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		grants, err := store.GetForumGrants(forumID)
+		if err != nil {
+			log.Fatalf("Erro ao listar permissões: %v", err)
+		}
+		if len(grants) == 0 {
+			fmt.Println("Nenhuma permissão concedida: o fórum segue o padrão dos papéis.")
+			return
+		}
+		for _, g := range grants {
+			fmt.Printf("%-10s %s\n", g.Permission, g.Subject())
+		}
+	case "grant", "revoke":
+		if len(args) != 5 || (args[3] != "role" && args[3] != "user") {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		perm, err := database.ParseForumPermission(args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		var role, username string
+		if args[3] == "role" {
+			role = args[4]
+		} else {
+			username = args[4]
+		}
+		if args[0] == "grant" {
+			if err := store.GrantForumPermission(forumID, perm, role, username); err != nil {
+				log.Fatalf("Erro ao conceder permissão: %v", err)
+			}
+			fmt.Printf("Permissão '%s' concedida a %s %s no fórum ID %d.\n", perm, args[3], args[4], forumID)
+		} else {
+			if err := store.RevokeForumPermission(forumID, perm, role, username); err != nil {
+				log.Fatalf("Erro ao revogar permissão: %v", err)
+			}
+			fmt.Printf("Permissão '%s' revogada de %s %s no fórum ID %d.\n", perm, args[3], args[4], forumID)
+		}
+	default:
+		fmt.Printf("Subcomando desconhecido: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleForumMod(store database.Store, args []string) {
+	const usage = "Uso: bbs-admin forummod list <id do fórum> | forummod add|remove <id do fórum> <usuário>"
+	if len(args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	forumID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		log.Fatalf("ID do fórum inválido: %v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		moderators, err := store.GetForumModerators(forumID)
+		if err != nil {
+			log.Fatalf("Erro ao listar moderadores: %v", err)
+		}
+		if len(moderators) == 0 {
+			fmt.Println("Nenhum moderador designado.")
+			return
+		}
+		for _, u := range moderators {
+			fmt.Printf("%s (%s)\n", u.Username, u.Role)
+		}
+	case "add":
+		if len(args) != 3 {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		if err := store.AddForumModerator(forumID, args[2]); err != nil {
+			log.Fatalf("Erro ao designar moderador: %v", err)
+		}
+		fmt.Printf("'%s' agora modera o fórum ID %d.\n", args[2], forumID)
+	case "remove":
+		if len(args) != 3 {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		if err := store.RemoveForumModerator(forumID, args[2]); err != nil {
+			log.Fatalf("Erro ao retirar moderador: %v", err)
+		}
+		fmt.Printf("'%s' não modera mais o fórum ID %d.\n", args[2], forumID)
+	default:
+		fmt.Printf("Subcomando desconhecido: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleTrash(store database.Store, args []string) {
+	const usage = "Uso: bbs-admin trash list | trash restore|purge forum|topic|post <id> | trash empty [dias]"
+	if len(args) == 0 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		items, err := store.GetTrash()
+		if err != nil {
+			log.Fatalf("Erro ao listar a lixeira: %v", err)
+		}
+		if len(items) == 0 {
+			fmt.Println("A lixeira está vazia.")
+			return
+		}
+		fmt.Printf("%-6s %-6s %-30s %-20s %-20s %s\n", "TIPO", "ID", "TÍTULO", "APAGADO POR", "APAGADO EM", "MOTIVO")
+		for _, item := range items {
+			title := strings.Join(strings.Fields(item.Title), " ")
+			if r := []rune(title); len(r) > 30 {
+				title = string(r[:29]) + "…"
+			}
+			deletedBy := item.DeletedBy
+			if deletedBy == "" {
+				deletedBy = "administração"
+			}
+			fmt.Printf("%-6s %-6d %-30s %-20s %-20s %s\n", item.Kind, item.ID, title, deletedBy,
+				item.DeletedAt.Local().Format("2006-01-02 15:04:05"), item.Reason)
+		}
+	case "restore", "purge":
+		if len(args) != 3 {
+			fmt.Println(usage)
+			os.Exit(1)
+		}
+		kind, err := database.ParseTrashKind(args[1])
+		if err != nil {
+			log.Fatalf("Erro: %v", err)
+		}
+		id, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("ID inválido: %v", err)
+		}
+		if args[0] == "restore" {
+			if err := store.RestoreTrashItem(kind, id); err != nil {
+				log.Fatalf("Erro ao restaurar: %v", err)
+			}
+			fmt.Printf("Restaurado: %s ID %d.\n", kind.Label(), id)
+			return
+		}
+		if err := store.PurgeTrashItem(kind, id); err != nil {
+			log.Fatalf("Erro ao remover: %v", err)
+		}
+		fmt.Printf("Removido definitivamente: %s ID %d.\n", kind.Label(), id)
+	case "empty":
+		days := 0
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				log.Fatalf("Número de dias inválido: %s", args[1])
+			}
+			days = n
+		}
+		n, err := store.PurgeTrash(time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			log.Fatalf("Erro ao esvaziar a lixeira: %v", err)
+		}
+		fmt.Printf("%d item(ns) removido(s) definitivamente.\n", n)
+	default:
+		fmt.Printf("Subcomando desconhecido: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleMigrate(db *sql.DB, args []string) {
+	if len(args) == 0 {
+		fmt.Println("Uso: bbs-admin migrate status|up|down [n]|force <versão> [applied|pending]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrations.Status(db)
+		if err != nil {
+			log.Fatalf("Erro ao consultar migrações: %v", err)
+		}
+		for _, st := range statuses {
+			state := "pendente"
+			if st.Dirty {
+				state = "INCONSISTENTE"
+			} else if st.Applied {
+				state = "aplicada em " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", st.Version, st.Name, state)
+		}
+	case "up":
+		applied, err := migrations.Up(db)
+		if err != nil {
+			log.Fatalf("Erro ao aplicar migrações: %v", err)
+		}
+		fmt.Printf("%d migração(ões) aplicada(s).\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Número de migrações inválido: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := migrations.Down(db, steps)
+		if err != nil {
+			log.Fatalf("Erro ao reverter migrações: %v", err)
+		}
+		fmt.Printf("%d migração(ões) revertida(s).\n", reverted)
+	case "force":
+		if len(args) < 2 {
+			log.Fatalf("Informe a versão da migração.")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Versão inválida: %s", args[1])
+		}
+		applied := true
+		if len(args) > 2 {
+			switch args[2] {
+			case "applied":
+			case "pending":
+				applied = false
+			default:
+				log.Fatalf("Estado inválido: %s (use applied ou pending)", args[2])
+			}
+		}
+		if err := migrations.Force(db, version, applied); err != nil {
+			log.Fatalf("Erro ao forçar migração: %v", err)
+		}
+		fmt.Printf("Migração %d marcada como %s.\n", version, map[bool]string{true: "aplicada", false: "pendente"}[applied])
+	default:
+		fmt.Printf("Subcomando desconhecido: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func controlSocketPath() string {
+	return getEnv("BBS_CONTROL_SOCKET", control.DefaultSocketPath)
+}
+
+func handleWho() {
+	resp, err := control.Call(controlSocketPath(), control.Request{Command: "who"})
+	if err != nil {
+		log.Fatalf("Erro ao consultar sessões: %v", err)
+	}
+	if len(resp.Sessions) == 0 {
+		fmt.Println("Nenhuma sessão conectada.")
+		return
+	}
+
+	now := time.Now()
+	fmt.Printf("%-5s %-16s %-21s %-10s %-10s %s\n", "ID", "USUÁRIO", "ENDEREÇO", "CONECTADO", "INATIVO", "TELA")
+	for _, s := range resp.Sessions {
+		fmt.Printf("%-5d %-16s %-21s %-10s %-10s %s\n", s.ID, s.Username, s.RemoteAddr,
+			now.Sub(s.ConnectedAt).Truncate(time.Second), now.Sub(s.LastActivity).Truncate(time.Second), s.View)
+	}
+}
+
+func handleKick(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Uso: bbs-admin kick <id da sessão>")
+		os.Exit(1)
+	}
+	if _, err := control.Call(controlSocketPath(), control.Request{Command: "kick", Args: args}); err != nil {
+		log.Fatalf("Erro ao desconectar sessão: %v", err)
+	}
+	fmt.Printf("Sessão %s desconectada.\n", args[0])
+}
+
+func handleBroadcast(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Uso: bbs-admin broadcast <aviso>")
+		os.Exit(1)
+	}
+	resp, err := control.Call(controlSocketPath(), control.Request{Command: "broadcast", Args: args})
+	if err != nil {
+		log.Fatalf("Erro ao enviar aviso: %v", err)
+	}
+	fmt.Println(resp.Message)
+}
+
+func handleShutdown(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Uso: bbs-admin shutdown <minutos> [motivo] | shutdown cancel")
+		os.Exit(1)
+	}
+	resp, err := control.Call(controlSocketPath(), control.Request{Command: "shutdown", Args: args})
+	if err != nil {
+		log.Fatalf("Erro ao programar desligamento: %v", err)
+	}
+	fmt.Println(resp.Message)
 }
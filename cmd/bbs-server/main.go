@@ -1,9 +1,12 @@
 package main
 
 import (
+	"log"
 	"modern-bbs/internal/app"
 )
 
 func main() {
-	app.Run()
+	if err := app.Run(); err != nil {
+		log.Fatal(err)
+	}
 }
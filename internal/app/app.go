@@ -1,33 +1,250 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"modern-bbs/internal/control"
 	"modern-bbs/internal/database"
 	"modern-bbs/internal/ssh"
+	"modern-bbs/pkg/tui"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-// Run inicia a aplicação principal do BBS.
-func Run() {
+// shutdownTimeout é quanto o servidor espera as sessões terminarem ao ser desligado.
+const shutdownTimeout = 10 * time.Second
+
+// defaultTrashRetention é o número padrão de dias que os itens apagados ficam na lixeira.
+const defaultTrashRetention = 30
+
+// trashPurgeInterval é o intervalo entre as limpezas da lixeira.
+const trashPurgeInterval = time.Hour
+
+// Run inicia a aplicação principal do BBS e retorna quando o servidor é desligado. Os
+// erros são retornados, em vez de encerrar o processo, para que o banco de dados seja
+// fechado e a limpeza da lixeira termine antes da saída.
+func Run() error {
 	// Configuração da aplicação a partir de variáveis de ambiente ou valores padrão.
 	dbPath := getEnv("BBS_DB_PATH", "bbs.db")
 	port := getEnv("BBS_PORT", "7778")
 	addr := ":" + port
 
 	// Inicializa o banco de dados.
-	if err := database.InitDB(dbPath); err != nil {
-		log.Fatalf("Erro ao inicializar o banco de dados em '%s': %v", dbPath, err)
+	store, err := database.InitDB(dbPath)
+	if err != nil {
+		return fmt.Errorf("falha ao inicializar o banco de dados em '%s': %w", dbPath, err)
 	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			log.Printf("Erro ao fechar o banco de dados: %v", err)
+		}
+	}()
 
 	// Cria e inicia o servidor SSH.
-	server, err := ssh.NewServer(addr)
+	server, err := ssh.NewServer(addr, store)
+	if err != nil {
+		return fmt.Errorf("falha ao criar o servidor SSH: %w", err)
+	}
+	server.ControlSocket = getEnv("BBS_CONTROL_SOCKET", control.DefaultSocketPath)
+	server.RegisterUser = getEnv("BBS_REGISTER_USER", ssh.DefaultRegisterUser)
+	server.Registration, err = registrationConfig(store, server.RegisterUser)
+	if err != nil {
+		return fmt.Errorf("erro na configuração do cadastro: %w", err)
+	}
+	server.TwoFactorRoles, err = twoFactorRoles(getEnv("BBS_REQUIRE_2FA", ""))
 	if err != nil {
-		log.Fatalf("Erro ao criar o servidor SSH: %v", err)
+		return fmt.Errorf("erro na configuração da verificação em duas etapas: %w", err)
+	}
+	server.GuestUser, server.GuestLimit, err = guestConfig(store, server.RegisterUser)
+	if err != nil {
+		return fmt.Errorf("erro na configuração do acesso de visitante: %w", err)
+	}
+	server.EditWindow, err = editWindow(getEnv("BBS_EDIT_WINDOW", tui.DefaultEditWindow.String()))
+	if err != nil {
+		return fmt.Errorf("erro na configuração da edição de posts: %w", err)
+	}
+	retention, err := trashRetention(getEnv("BBS_TRASH_RETENTION", strconv.Itoa(defaultTrashRetention)))
+	if err != nil {
+		return fmt.Errorf("erro na configuração da lixeira: %w", err)
+	}
+
+	// SIGINT e SIGTERM desligam o servidor sem interromper as sessões no meio de uma escrita.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A limpeza da lixeira termina antes de o banco de dados ser fechado.
+	var purging sync.WaitGroup
+	if retention > 0 {
+		purging.Add(1)
+		go func() {
+			defer purging.Done()
+			purgeTrash(ctx, store, retention)
+		}()
 	}
 
 	log.Printf("Servidor BBS escutando em %s...", addr)
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("Erro ao iniciar o servidor: %v", err)
+	err = server.ListenAndServe(ctx)
+	// Um segundo sinal durante a espera encerra o processo imediatamente. Se o servidor nem
+	// chegou a subir, o cancelamento também interrompe a limpeza da lixeira.
+	stop()
+	purging.Wait()
+	if err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return fmt.Errorf("falha ao iniciar o servidor: %w", err)
+	}
+
+	log.Printf("Desligando o servidor; aguardando até %s pelas sessões...", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Sessões encerradas à força: %v", err)
+	}
+	log.Printf("Servidor encerrado.")
+	return nil
+}
+
+// registrationConfig lê a política de cadastro (BBS_REGISTRATION) e as cotas de convites
+// por papel (BBS_INVITE_QUOTAS). Retorna nil se o cadastro estiver fechado.
+func registrationConfig(store database.Store, registerUser string) (*tui.Registration, error) {
+	// O cadastro fica fechado até que o operador escolha uma política.
+	policy := getEnv("BBS_REGISTRATION", tui.RegistrationClosed)
+	if policy == tui.RegistrationClosed || registerUser == "" {
+		return nil, nil
+	}
+	if !tui.ValidRegistrationPolicy(policy) {
+		return nil, fmt.Errorf("política de cadastro inválida: %q (use open, invite, approval ou closed)", policy)
+	}
+
+	quotas, err := parseInviteQuotas(getEnv("BBS_INVITE_QUOTAS", defaultInviteQuotas))
+	if err != nil {
+		return nil, err
+	}
+
+	// O login de cadastro não autentica, então não pode coincidir com uma conta existente.
+	user, _, err := store.GetUserByUsername(registerUser)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return nil, fmt.Errorf("o login de cadastro '%s' pertence a uma conta existente; escolha outro em BBS_REGISTER_USER", registerUser)
+	}
+
+	return &tui.Registration{Policy: policy, InviteQuotas: quotas}, nil
+}
+
+// defaultInviteQuotas permite que usuários e moderadores convidem algumas pessoas e que
+// os administradores convidem sem limite.
+const defaultInviteQuotas = "user=3,moderator=10,admin=-1"
+
+// parseInviteQuotas interpreta uma lista no formato papel=cota, separada por vírgulas.
+// Uma cota negativa não impõe limite.
+func parseInviteQuotas(value string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		role, quota, ok := strings.Cut(item, "=")
+		role = strings.TrimSpace(role)
+		n, err := strconv.Atoi(strings.TrimSpace(quota))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("cota de convites inválida: %q (use papel=número)", item)
+		}
+		if role != "user" && role != "moderator" && role != "admin" {
+			return nil, fmt.Errorf("papel desconhecido em BBS_INVITE_QUOTAS: %q", role)
+		}
+		quotas[role] = n
+	}
+	return quotas, nil
+}
+
+// guestConfig lê o login de visitante (BBS_GUEST_USER; vazio desabilita o acesso) e o
+// limite de sessões de visitante por endereço IP (BBS_GUEST_LIMIT).
+func guestConfig(store database.Store, registerUser string) (string, int, error) {
+	guestUser := getEnv("BBS_GUEST_USER", "")
+	if guestUser == "" {
+		return "", 0, nil
+	}
+	if guestUser == registerUser {
+		return "", 0, fmt.Errorf("o login de visitante não pode ser o login de cadastro '%s'", registerUser)
+	}
+
+	limit, err := strconv.Atoi(getEnv("BBS_GUEST_LIMIT", strconv.Itoa(ssh.DefaultGuestLimit)))
+	if err != nil || limit < 1 {
+		return "", 0, fmt.Errorf("limite de visitantes inválido em BBS_GUEST_LIMIT (use um número maior que zero)")
+	}
+
+	// Como o login de cadastro, o de visitante não autentica e não pode ser uma conta existente.
+	user, _, err := store.GetUserByUsername(guestUser)
+	if err != nil {
+		return "", 0, err
+	}
+	if user != nil {
+		return "", 0, fmt.Errorf("o login de visitante '%s' pertence a uma conta existente; escolha outro em BBS_GUEST_USER", guestUser)
+	}
+
+	return guestUser, limit, nil
+}
+
+// twoFactorRoles interpreta a lista de papéis, separados por vírgula, que precisam
+// ativar a verificação em duas etapas (ex.: "moderator,admin").
+func twoFactorRoles(value string) ([]string, error) {
+	var roles []string
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role == "" {
+			continue
+		}
+		if role != "user" && role != "moderator" && role != "admin" {
+			return nil, fmt.Errorf("papel desconhecido em BBS_REQUIRE_2FA: %q", role)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// editWindow interpreta o prazo de edição dos posts (ex.: "30m", "2h"); "0" não limita.
+func editWindow(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("prazo inválido em BBS_EDIT_WINDOW: %q (use, por exemplo, 30m, 2h ou 0 para não limitar)", value)
+	}
+	return d, nil
+}
+
+// trashRetention interpreta por quantos dias os itens apagados ficam na lixeira antes de
+// serem removidos definitivamente; "0" os mantém até que a administração os remova.
+func trashRetention(value string) (time.Duration, error) {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("retenção inválida em BBS_TRASH_RETENTION: %q (use o número de dias, ou 0 para não remover)", value)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// purgeTrash remove definitivamente, ao iniciar e depois a cada trashPurgeInterval, os
+// itens que estão na lixeira há mais de retention. Para quando ctx é cancelado.
+func purgeTrash(ctx context.Context, store database.Store, retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		n, err := store.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Erro ao limpar a lixeira: %v", err)
+		} else if n > 0 {
+			log.Printf("Lixeira: %d item(ns) apagado(s) há mais de %d dia(s) removido(s) definitivamente.", n, int(retention.Hours()/24))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
@@ -0,0 +1,326 @@
+// Package chat implementa as salas de chat em tempo real do BBS: um hub de
+// publicação e assinatura em memória, compartilhado por todas as sessões do
+// servidor, com o histórico das salas gravado no Store.
+package chat
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/database"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DefaultChannel é a sala em que os usuários entram ao abrir o chat.
+const DefaultChannel = "geral"
+
+const (
+	scrollbackSize = 100 // Mensagens do histórico exibidas ao entrar em uma sala
+	queueSize      = 256 // Eventos pendentes por cliente antes de descartar
+	maxChannelName = 32
+)
+
+// EventKind identifica o tipo de um evento de sala.
+type EventKind int
+
+const (
+	EventMessage EventKind = iota
+	EventAction            // Mensagem enviada com /me
+	EventJoin
+	EventPart
+	EventNotice // Aviso local, nunca enviado aos outros participantes
+)
+
+// Event é uma mensagem ou aviso de uma sala de chat.
+type Event struct {
+	Kind    EventKind
+	Channel string
+	User    string
+	Text    string
+	Time    time.Time
+	History bool // Carregado do histórico gravado, e não recebido ao vivo
+}
+
+// ChannelInfo resume uma sala para a listagem de salas.
+type ChannelInfo struct {
+	Name    string
+	Members int
+}
+
+// Hub distribui os eventos das salas entre os clientes conectados.
+type Hub struct {
+	store database.ChatStore
+
+	mu       sync.RWMutex
+	channels map[string]map[*Client]bool
+}
+
+// NewHub cria um hub que grava o histórico das salas no store.
+func NewHub(store database.ChatStore) *Hub {
+	return &Hub{
+		store:    store,
+		channels: make(map[string]map[*Client]bool),
+	}
+}
+
+// Client é a participação de uma sessão no chat. Os eventos das salas em que o
+// cliente está são entregues, em ordem, pela função deliver informada em Connect.
+type Client struct {
+	hub     *Hub
+	user    string
+	deliver func(Event)
+	queue   chan Event
+	done    chan struct{}
+	close   sync.Once
+}
+
+// Connect registra uma sessão do usuário no hub. deliver é chamada em uma goroutine
+// própria do cliente, de modo que um cliente lento não atrasa os demais.
+func (h *Hub) Connect(user string, deliver func(Event)) *Client {
+	c := &Client{
+		hub:     h,
+		user:    user,
+		deliver: deliver,
+		queue:   make(chan Event, queueSize),
+		done:    make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+func (c *Client) run() {
+	for {
+		select {
+		case ev := <-c.queue:
+			c.deliver(ev)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// send enfileira o evento sem bloquear; se a fila estiver cheia, o evento é descartado.
+func (c *Client) send(ev Event) {
+	select {
+	case c.queue <- ev:
+	default:
+		log.Printf("Chat: fila cheia para %s, evento de #%s descartado", c.user, ev.Channel)
+	}
+}
+
+// User retorna o nome do usuário do cliente.
+func (c *Client) User() string {
+	return c.user
+}
+
+// NormalizeChannel valida o nome de uma sala e o devolve sem o '#' e em minúsculas.
+func NormalizeChannel(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if name == "" {
+		return "", fmt.Errorf("informe o nome da sala")
+	}
+	if len(name) > maxChannelName {
+		return "", fmt.Errorf("o nome da sala pode ter no máximo %d caracteres", maxChannelName)
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", fmt.Errorf("nome de sala inválido: use letras, números, '-' e '_'")
+		}
+	}
+	return name, nil
+}
+
+// Join coloca o cliente na sala, avisa os participantes e retorna o histórico recente.
+func (c *Client) Join(channel string) ([]Event, error) {
+	channel, err := NormalizeChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := c.hub.store.GetChatScrollback(channel, scrollbackSize)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]Event, len(history))
+	for i, m := range history {
+		events[i] = Event{
+			Kind:    EventMessage,
+			Channel: m.Channel,
+			User:    m.Username,
+			Text:    m.Body,
+			Time:    m.CreatedAt,
+			History: true,
+		}
+		if m.Kind == database.ChatKindAction {
+			events[i].Kind = EventAction
+		}
+	}
+
+	h := c.hub
+	h.mu.Lock()
+	members, ok := h.channels[channel]
+	if !ok {
+		members = make(map[*Client]bool)
+		h.channels[channel] = members
+	}
+	already := members[c]
+	members[c] = true
+	h.mu.Unlock()
+
+	if !already {
+		h.broadcast(Event{Kind: EventJoin, Channel: channel, User: c.user, Time: time.Now()})
+	}
+	return events, nil
+}
+
+// Part tira o cliente da sala e avisa os participantes que ficaram.
+func (c *Client) Part(channel string) error {
+	channel, err := NormalizeChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	h := c.hub
+	h.mu.Lock()
+	members := h.channels[channel]
+	if !members[c] {
+		h.mu.Unlock()
+		return fmt.Errorf("você não está em #%s", channel)
+	}
+	delete(members, c)
+	if len(members) == 0 {
+		delete(h.channels, channel)
+	}
+	h.mu.Unlock()
+
+	ev := Event{Kind: EventPart, Channel: channel, User: c.user, Time: time.Now()}
+	h.broadcast(ev)
+	// Quem saiu também recebe o aviso, para encerrar a sala na própria tela.
+	c.send(ev)
+	return nil
+}
+
+// Say envia uma mensagem à sala.
+func (c *Client) Say(channel, text string) error {
+	return c.publish(channel, database.ChatKindMessage, text)
+}
+
+// Act envia uma ação (/me) à sala.
+func (c *Client) Act(channel, text string) error {
+	return c.publish(channel, database.ChatKindAction, text)
+}
+
+func (c *Client) publish(channel, kind, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	h := c.hub
+	h.mu.RLock()
+	member := h.channels[channel][c]
+	h.mu.RUnlock()
+	if !member {
+		return fmt.Errorf("você não está em #%s", channel)
+	}
+
+	m, err := h.store.AddChatMessage(channel, c.user, kind, text)
+	if err != nil {
+		return err
+	}
+
+	ev := Event{Kind: EventMessage, Channel: channel, User: c.user, Text: text, Time: m.CreatedAt}
+	if kind == database.ChatKindAction {
+		ev.Kind = EventAction
+	}
+	h.broadcast(ev)
+	return nil
+}
+
+// Close tira o cliente de todas as salas e encerra a entrega de eventos.
+func (c *Client) Close() {
+	c.close.Do(func() {
+		for _, channel := range c.Joined() {
+			c.Part(channel)
+		}
+		close(c.done)
+	})
+}
+
+// Joined retorna as salas em que o cliente está, em ordem alfabética.
+func (c *Client) Joined() []string {
+	h := c.hub
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var channels []string
+	for name, members := range h.channels {
+		if members[c] {
+			channels = append(channels, name)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Members retorna os usuários presentes na sala, sem repetição e em ordem alfabética.
+func (c *Client) Members(channel string) []string {
+	h := c.hub
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var users []string
+	for member := range h.channels[channel] {
+		if !seen[member.user] {
+			seen[member.user] = true
+			users = append(users, member.user)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Channels lista as salas com participantes ou com histórico gravado.
+func (c *Client) Channels() ([]ChannelInfo, error) {
+	names, err := c.hub.store.GetChatChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.hub
+	h.mu.RLock()
+	counts := make(map[string]int)
+	for name := range h.channels {
+		counts[name] = 0
+	}
+	h.mu.RUnlock()
+	for name := range counts {
+		counts[name] = len(c.Members(name))
+	}
+	for _, name := range append(names, DefaultChannel) {
+		if _, ok := counts[name]; !ok {
+			counts[name] = 0
+		}
+	}
+
+	channels := make([]ChannelInfo, 0, len(counts))
+	for name, n := range counts {
+		channels = append(channels, ChannelInfo{Name: name, Members: n})
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels, nil
+}
+
+// broadcast entrega o evento a todos os clientes da sala.
+func (h *Hub) broadcast(ev Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for member := range h.channels[ev.Channel] {
+		member.send(ev)
+	}
+}
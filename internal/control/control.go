@@ -0,0 +1,143 @@
+// Package control implementa o socket de controle local do servidor, pelo qual o
+// bbs-admin consulta e comanda uma instância em execução. Cada conexão leva uma
+// requisição e uma resposta em JSON.
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"modern-bbs/internal/session"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultSocketPath é o caminho do socket quando BBS_CONTROL_SOCKET não está definida.
+const DefaultSocketPath = "bbs.sock"
+
+const timeout = 5 * time.Second
+
+// Request é um comando enviado ao servidor.
+type Request struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Response é a resposta do servidor. Error vem preenchido quando o comando falha, e
+// Message traz um resumo do que foi feito.
+type Response struct {
+	Error    string         `json:"error,omitempty"`
+	Message  string         `json:"message,omitempty"`
+	Sessions []session.Info `json:"sessions,omitempty"`
+}
+
+// Handler executa um comando recebido pelo socket.
+type Handler func(Request) Response
+
+// Listen abre o socket de controle. Um socket deixado por um servidor que não está mais
+// em execução é substituído; se outro servidor estiver usando o socket, ou se o caminho
+// existir e não for um socket, Listen retorna um erro.
+func Listen(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s já existe e não é um socket; remova-o ou escolha outro BBS_CONTROL_SOCKET", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("o socket de controle %s já está em uso por outro servidor", path)
+		}
+	}
+
+	// Apenas o usuário que executa o servidor pode enviar comandos. O socket é criado num
+	// diretório temporário acessível só a ele e recebe a permissão 0600 antes de ser movido
+	// para o caminho final, então nunca fica aberto a outros usuários.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".bbs-control-")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar diretório do socket de controle: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "sock")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao escutar no socket de controle %s: %w", path, err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("falha ao proteger o socket de controle: %w", err)
+	}
+	// O rename substitui de uma vez o socket antigo, sem seguir links simbólicos.
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("falha ao instalar o socket de controle em %s: %w", path, err)
+	}
+	return &socketListener{Listener: l, path: path}, nil
+}
+
+// socketListener remove o arquivo do socket ao ser fechado, como o net.UnixListener faz
+// com o caminho em que foi criado.
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *socketListener) Close() error {
+	err := l.Listener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// Serve atende as conexões do socket até que o listener seja fechado.
+func Serve(l net.Listener, handler Handler) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Falha ao aceitar conexão no socket de controle: %v", err)
+			continue
+		}
+		go serveConn(conn, handler)
+	}
+}
+
+func serveConn(conn net.Conn, handler Handler) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(Response{Error: "requisição inválida"})
+		return
+	}
+	if err := json.NewEncoder(conn).Encode(handler(req)); err != nil {
+		log.Printf("Falha ao responder no socket de controle: %v", err)
+	}
+}
+
+// Call envia um comando ao servidor que escuta no socket informado.
+func Call(path string, req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível conectar ao servidor em %s (ele está em execução?): %w", path, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("falha ao enviar comando: %w", err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("falha ao ler resposta do servidor: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos de mensagem do chat.
+const (
+	ChatKindMessage = "message"
+	ChatKindAction  = "action" // Enviada com /me
+)
+
+// ChatMessage é uma mensagem gravada no histórico de uma sala de chat.
+type ChatMessage struct {
+	ID        int64
+	Channel   string
+	Username  string
+	Kind      string
+	Body      string
+	CreatedAt time.Time
+}
+
+// AddChatMessage grava uma mensagem no histórico da sala.
+func (s *SQLiteStore) AddChatMessage(channel, username, kind, body string) (*ChatMessage, error) {
+	// O horário devolvido corresponde ao CURRENT_TIMESTAMP gravado pelo SQLite (UTC, em segundos).
+	now := time.Now().UTC().Truncate(time.Second)
+	res, err := s.db.Exec("INSERT INTO chat_messages (channel, username, kind, body) VALUES (?, ?, ?, ?)",
+		channel, username, kind, body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gravar mensagem do chat: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter ID da mensagem do chat: %w", err)
+	}
+	return &ChatMessage{ID: id, Channel: channel, Username: username, Kind: kind, Body: body, CreatedAt: now}, nil
+}
+
+// GetChatScrollback retorna as últimas mensagens da sala, em ordem cronológica.
+func (s *SQLiteStore) GetChatScrollback(channel string, limit int) ([]ChatMessage, error) {
+	rows, err := s.db.Query(`
+		SELECT id, channel, username, kind, body, created_at FROM (
+			SELECT * FROM chat_messages WHERE channel = ? ORDER BY id DESC LIMIT ?
+		) ORDER BY id ASC
+	`, channel, limit)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar histórico do chat: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []ChatMessage
+	for rows.Next() {
+		var m ChatMessage
+		if err := rows.Scan(&m.ID, &m.Channel, &m.Username, &m.Kind, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear mensagem do chat: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// GetChatChannels retorna as salas que têm histórico, em ordem alfabética.
+func (s *SQLiteStore) GetChatChannels() ([]string, error) {
+	rows, err := s.db.Query("SELECT DISTINCT channel FROM chat_messages ORDER BY channel")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar salas do chat: %w", err)
+	}
+	defer rows.Close()
+
+	var channels []string
+	for rows.Next() {
+		var channel string
+		if err := rows.Scan(&channel); err != nil {
+			return nil, fmt.Errorf("falha ao escanear sala do chat: %w", err)
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, rows.Err()
+}
@@ -3,118 +3,133 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"log"
+	"modern-bbs/internal/database/migrations"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3" // Driver do SQLite
 )
 
-var DB *sql.DB
+// SQLiteStore é a implementação de Store sobre um banco de dados SQLite.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore cria um Store sobre uma conexão já aberta e migrada.
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+// InitDB abre o banco de dados SQLite, aplica as migrações pendentes e popula os dados iniciais.
+// Recusa-se a continuar se uma migração anterior tiver sido interrompida.
+func InitDB(dbPath string) (*SQLiteStore, error) {
+	db, err := OpenDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := migrations.Up(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao migrar o banco de dados: %w", err)
+	}
+	if applied > 0 {
+		log.Printf("%d migração(ões) aplicada(s) ao banco de dados.", applied)
+	}
+
+	store := NewSQLiteStore(db)
+	if err := seedDatabase(store); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
 
-// InitDB inicializa o banco de dados SQLite e cria as tabelas necessárias.
-func InitDB(dbPath string) error {
-	var err error
-	DB, err = sql.Open("sqlite3", dbPath)
+// OpenDB abre o banco de dados SQLite sem aplicar migrações nem popular dados.
+// É usado pelos comandos de manutenção do esquema. As chaves estrangeiras são
+// verificadas em todas as conexões, o que aplica os ON DELETE CASCADE do esquema.
+func OpenDB(dbPath string) (*sql.DB, error) {
+	dsn := dbPath + "?_foreign_keys=on"
+	if strings.Contains(dbPath, "?") {
+		dsn = dbPath + "&_foreign_keys=on"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		return fmt.Errorf("falha ao abrir o banco de dados: %w", err)
+		return nil, fmt.Errorf("falha ao abrir o banco de dados: %w", err)
 	}
 
-	if err = DB.Ping(); err != nil {
-		return fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
 	}
 
-	if err := createTables(); err != nil {
-		return err
+	if err := checkFTS5(db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return seedDatabase()
+	return db, nil
+}
+
+// checkFTS5 verifica se o driver foi compilado com o FTS5, usado pela busca.
+// Sem ele, a migração do índice falharia com uma mensagem pouco clara.
+func checkFTS5(db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return fmt.Errorf("falha ao verificar suporte a FTS5: %w", err)
+	}
+	if !enabled {
+		return fmt.Errorf("o SQLite foi compilado sem suporte a FTS5; compile o BBS com 'go build -tags sqlite_fts5'")
+	}
+	return nil
+}
+
+// Close fecha a conexão com o banco de dados.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
 }
 
-// createTables cria as tabelas do banco de dados se elas não existirem.
 // seedDatabase popula o banco de dados com dados iniciais (usuários, fóruns, etc.)
-func seedDatabase() error {
+func seedDatabase(s Store) error {
 	// Criar usuário admin se não existir
-	user, _, err := GetUserByUsername("admin")
+	user, _, err := s.GetUserByUsername("admin")
 	if err != nil {
 		return fmt.Errorf("falha ao verificar usuário admin: %w", err)
 	}
 	if user == nil {
-		if _, err := CreateUser("admin", "adminpass"); err != nil {
+		if _, err := s.CreateUser("admin", "adminpass"); err != nil {
 			return fmt.Errorf("falha ao criar usuário admin: %w", err)
 		}
-		if err := SetUserRole("admin", "admin"); err != nil {
+		if err := s.SetUserRole("admin", "admin"); err != nil {
 			return fmt.Errorf("falha ao definir papel do admin: %w", err)
 		}
 	}
 
 	// Criar usuário moderator se não existir
-	user, _, err = GetUserByUsername("mod")
+	user, _, err = s.GetUserByUsername("mod")
 	if err != nil {
 		return fmt.Errorf("falha ao verificar usuário mod: %w", err)
 	}
 	if user == nil {
-		if _, err := CreateUser("mod", "modpass"); err != nil {
+		if _, err := s.CreateUser("mod", "modpass"); err != nil {
 			return fmt.Errorf("falha ao criar usuário mod: %w", err)
 		}
-		if err := SetUserRole("mod", "moderator"); err != nil {
+		if err := s.SetUserRole("mod", "moderator"); err != nil {
 			return fmt.Errorf("falha ao definir papel do mod: %w", err)
 		}
 	}
 
 	// Criar usuário comum se não existir
-	user, _, err = GetUserByUsername("user")
+	user, _, err = s.GetUserByUsername("user")
 	if err != nil {
 		return fmt.Errorf("falha ao verificar usuário user: %w", err)
 	}
 	if user == nil {
-		if _, err := CreateUser("user", "userpass"); err != nil {
+		if _, err := s.CreateUser("user", "userpass"); err != nil {
 			return fmt.Errorf("falha ao criar usuário user: %w", err)
 		}
 	}
 
 	return nil
 }
-
-func createTables() error {
-	createTablesSQL := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		username TEXT NOT NULL UNIQUE,
-		password_hash TEXT NOT NULL,
-		role TEXT NOT NULL DEFAULT 'user', -- 'user', 'moderator', 'admin'
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS forums (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL UNIQUE,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS topics (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		forum_id INTEGER NOT NULL,
-		user_id INTEGER NOT NULL,
-		title TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY(forum_id) REFERENCES forums(id),
-		FOREIGN KEY(user_id) REFERENCES users(id)
-	);
-
-	CREATE TABLE IF NOT EXISTS posts (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		topic_id INTEGER NOT NULL,
-		user_id INTEGER NOT NULL,
-		content TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY(topic_id) REFERENCES topics(id),
-		FOREIGN KEY(user_id) REFERENCES users(id)
-	);
-	`
-
-	_, err := DB.Exec(createTablesSQL)
-	if err != nil {
-		return fmt.Errorf("falha ao criar as tabelas: %w", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"modern-bbs/internal/events"
+	"time"
+)
+
+// eventStore decora um Store publicando no barramento as criações e remoções de
+// tópicos e posts bem-sucedidas, as edições de posts e as operações da lixeira. As
+// demais operações são repassadas sem alteração.
+type eventStore struct {
+	Store
+	bus *events.Bus
+}
+
+// WithEvents retorna um Store que publica em bus as alterações de fóruns, tópicos e posts.
+func WithEvents(store Store, bus *events.Bus) Store {
+	return &eventStore{Store: store, bus: bus}
+}
+
+// forumOf retorna o fórum do tópico, ou 0 se ele não puder ser consultado.
+func (s *eventStore) forumOf(topicID int) int64 {
+	topic, err := s.Store.GetTopicByID(topicID)
+	if err != nil || topic == nil {
+		return 0
+	}
+	return int64(topic.ForumID)
+}
+
+func (s *eventStore) CreateTopic(forumID, userID int, title string) error {
+	if err := s.Store.CreateTopic(forumID, userID, title); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.TopicCreated, ForumID: int64(forumID), UserID: userID})
+	return nil
+}
+
+func (s *eventStore) DeleteTopic(id int, deletedBy int64, reason string) error {
+	return s.deleteTopic(id, func() error { return s.Store.DeleteTopic(id, deletedBy, reason) })
+}
+
+func (s *eventStore) AdminDeleteTopic(id int, reason string) error {
+	return s.deleteTopic(id, func() error { return s.Store.AdminDeleteTopic(id, reason) })
+}
+
+// deleteTopic executa a remoção e publica TopicDeleted se ela der certo.
+func (s *eventStore) deleteTopic(id int, remove func() error) error {
+	// O fórum é consultado antes, enquanto o tópico ainda está visível.
+	forumID := s.forumOf(id)
+	if err := remove(); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.TopicDeleted, ForumID: forumID, TopicID: id})
+	return nil
+}
+
+func (s *eventStore) CreatePost(topicID, userID int, content string) error {
+	if err := s.Store.CreatePost(topicID, userID, content); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.PostCreated, ForumID: s.forumOf(topicID), TopicID: topicID, UserID: userID})
+	return nil
+}
+
+func (s *eventStore) UpdatePost(postID int, editorID int64, content string, editWindow time.Duration) error {
+	if err := s.Store.UpdatePost(postID, editorID, content, editWindow); err != nil {
+		return err
+	}
+	ev := events.Event{Kind: events.PostEdited, PostID: postID, UserID: int(editorID)}
+	if post, err := s.Store.GetPostByID(postID); err == nil && post != nil {
+		ev.TopicID = post.TopicID
+		ev.ForumID = s.forumOf(post.TopicID)
+	}
+	s.bus.Publish(ev)
+	return nil
+}
+
+func (s *eventStore) DeletePost(id int, deletedBy int64, reason string) error {
+	return s.deletePost(id, func() error { return s.Store.DeletePost(id, deletedBy, reason) })
+}
+
+func (s *eventStore) AdminDeletePost(id int, reason string) error {
+	return s.deletePost(id, func() error { return s.Store.AdminDeletePost(id, reason) })
+}
+
+// deletePost executa a remoção e publica PostDeleted se ela der certo.
+func (s *eventStore) deletePost(id int, remove func() error) error {
+	// O tópico e o fórum são consultados antes, enquanto o post ainda está visível.
+	ev := events.Event{Kind: events.PostDeleted, PostID: id}
+	if post, err := s.Store.GetPostByID(id); err == nil && post != nil {
+		ev.TopicID = post.TopicID
+		ev.ForumID = s.forumOf(post.TopicID)
+	}
+	if err := remove(); err != nil {
+		return err
+	}
+	s.bus.Publish(ev)
+	return nil
+}
+
+func (s *eventStore) DeleteForum(id, deletedBy int64, reason string) error {
+	if err := s.Store.DeleteForum(id, deletedBy, reason); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.ForumDeleted, ForumID: id, UserID: int(deletedBy)})
+	return nil
+}
+
+func (s *eventStore) RestoreTrashItem(kind TrashKind, id int64) error {
+	if err := s.Store.RestoreTrashItem(kind, id); err != nil {
+		return err
+	}
+	// Restaurado, o item volta a ser visível, e o seu tópico e fórum podem ser consultados.
+	ev := events.Event{Kind: events.TrashRestored}
+	switch kind {
+	case TrashForum:
+		ev.ForumID = id
+	case TrashTopic:
+		ev.TopicID = int(id)
+		ev.ForumID = s.forumOf(int(id))
+	case TrashPost:
+		ev.PostID = int(id)
+		if post, err := s.Store.GetPostByID(int(id)); err == nil && post != nil {
+			ev.TopicID = post.TopicID
+			ev.ForumID = s.forumOf(post.TopicID)
+		}
+	}
+	s.bus.Publish(ev)
+	return nil
+}
+
+func (s *eventStore) PurgeTrashItem(kind TrashKind, id int64) error {
+	if err := s.Store.PurgeTrashItem(kind, id); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{Kind: events.TrashPurged})
+	return nil
+}
+
+func (s *eventStore) PurgeTrash(before time.Time) (int, error) {
+	n, err := s.Store.PurgeTrash(before)
+	if n > 0 {
+		s.bus.Publish(events.Event{Kind: events.TrashPurged})
+	}
+	return n, err
+}
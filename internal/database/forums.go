@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 )
@@ -11,12 +12,17 @@ type Forum struct {
 	ID          int64
 	Name        string
 	Description string
+	GuestAccess bool // Visível para os visitantes, que entram sem conta
 	CreatedAt   time.Time
 }
 
+// ErrForumNameTaken é retornado ao restaurar um fórum da lixeira cujo nome passou a ser
+// usado por outro fórum.
+var ErrForumNameTaken = errors.New("já existe outro fórum com este nome; renomeie-o antes de restaurar")
+
 // CreateForum cria um novo fórum no banco de dados.
-func CreateForum(name, description string) (*Forum, error) {
-	stmt, err := DB.Prepare("INSERT INTO forums(name, description) VALUES(?, ?)")
+func (s *SQLiteStore) CreateForum(name, description string) (*Forum, error) {
+	stmt, err := s.db.Prepare("INSERT INTO forums(name, description) VALUES(?, ?)")
 	if err != nil {
 		return nil, fmt.Errorf("falha ao preparar statement: %w", err)
 	}
@@ -41,8 +47,8 @@ func CreateForum(name, description string) (*Forum, error) {
 
 // GetAllForums retorna todos os fóruns do banco de dados.
 // UpdateForum atualiza o nome e a descrição de um fórum existente.
-func UpdateForum(id int64, name, description string) error {
-	stmt, err := DB.Prepare("UPDATE forums SET name = ?, description = ? WHERE id = ?")
+func (s *SQLiteStore) UpdateForum(id int64, name, description string) error {
+	stmt, err := s.db.Prepare("UPDATE forums SET name = ?, description = ? WHERE id = ?")
 	if err != nil {
 		return fmt.Errorf("falha ao preparar statement: %w", err)
 	}
@@ -56,13 +62,34 @@ func UpdateForum(id int64, name, description string) error {
 	return nil
 }
 
-// DeleteForum remove um fórum e, em cascata, seus tópicos e posts.
-func DeleteForum(id int64) error {
-	tx, err := DB.Begin()
+// DeleteForum move o fórum para a lixeira, registrando quem o apagou e o motivo. Os
+// tópicos e posts do fórum ficam ocultos com ele.
+func (s *SQLiteStore) DeleteForum(id, deletedBy int64, reason string) error {
+	return s.moveToTrash(TrashForum, id, deletedBy, reason)
+}
+
+// purgeForum remove definitivamente um fórum e, em cascata, seus tópicos e posts.
+func (s *SQLiteStore) purgeForum(id int64) error {
+	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("falha ao iniciar transação: %w", err)
 	}
 
+	// Remove o estado de leitura dos tópicos do fórum
+	_, err = tx.Exec(`DELETE FROM read_state WHERE topic_id IN (SELECT id FROM topics WHERE forum_id = ?)`, id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover estado de leitura do fórum: %w", err)
+	}
+
+	// Remove as revisões dos posts do fórum
+	_, err = tx.Exec(`DELETE FROM post_revisions WHERE post_id IN (
+		SELECT p.id FROM posts p JOIN topics t ON t.id = p.topic_id WHERE t.forum_id = ?)`, id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover revisões do fórum: %w", err)
+	}
+
 	// Deleta os posts associados aos tópicos do fórum
 	_, err = tx.Exec(`DELETE FROM posts WHERE topic_id IN (SELECT id FROM topics WHERE forum_id = ?)`, id)
 	if err != nil {
@@ -77,6 +104,20 @@ func DeleteForum(id int64) error {
 		return fmt.Errorf("falha ao deletar tópicos do fórum: %w", err)
 	}
 
+	// Remove as permissões do fórum
+	_, err = tx.Exec("DELETE FROM forum_permissions WHERE forum_id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover permissões do fórum: %w", err)
+	}
+
+	// Remove os moderadores do fórum
+	_, err = tx.Exec("DELETE FROM forum_moderators WHERE forum_id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover moderadores do fórum: %w", err)
+	}
+
 	// Deleta o fórum
 	_, err = tx.Exec("DELETE FROM forums WHERE id = ?", id)
 	if err != nil {
@@ -87,8 +128,17 @@ func DeleteForum(id int64) error {
 	return tx.Commit()
 }
 
-func GetAllForums() ([]Forum, error) {
-	rows, err := DB.Query("SELECT id, name, description, created_at FROM forums ORDER BY name ASC")
+func (s *SQLiteStore) GetAllForums() ([]Forum, error) {
+	return s.queryForums("SELECT id, name, description, guest_access, created_at FROM forums WHERE deleted_at IS NULL ORDER BY name ASC")
+}
+
+// GetGuestForums retorna os fóruns abertos aos visitantes.
+func (s *SQLiteStore) GetGuestForums() ([]Forum, error) {
+	return s.queryForums("SELECT id, name, description, guest_access, created_at FROM forums WHERE guest_access = 1 AND deleted_at IS NULL ORDER BY name ASC")
+}
+
+func (s *SQLiteStore) queryForums(query string, args ...any) ([]Forum, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao consultar fóruns: %w", err)
 	}
@@ -99,7 +149,7 @@ func GetAllForums() ([]Forum, error) {
 		var forum Forum
 		// O scan para a descrição pode ser nulo, então precisamos tratar isso.
 		var description sql.NullString
-		if err := rows.Scan(&forum.ID, &forum.Name, &description, &forum.CreatedAt); err != nil {
+		if err := rows.Scan(&forum.ID, &forum.Name, &description, &forum.GuestAccess, &forum.CreatedAt); err != nil {
 			return nil, fmt.Errorf("falha ao escanear linha do fórum: %w", err)
 		}
 		if description.Valid {
@@ -110,3 +160,19 @@ func GetAllForums() ([]Forum, error) {
 
 	return forums, nil
 }
+
+// SetForumGuestAccess abre ou fecha o fórum aos visitantes.
+func (s *SQLiteStore) SetForumGuestAccess(id int64, allowed bool) error {
+	res, err := s.db.Exec("UPDATE forums SET guest_access = ? WHERE id = ?", allowed, id)
+	if err != nil {
+		return fmt.Errorf("falha ao atualizar acesso de visitantes: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("fórum %d não encontrado", id)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5
+
+package database
+
+// A busca depende do FTS5, que o go-sqlite3 só inclui com a tag sqlite_fts5. Sem ela, a
+// compilação para aqui, com o nome abaixo na mensagem de erro, em vez de o servidor
+// falhar só ao abrir o banco. Use "make" ou "go build -tags sqlite_fts5 ./...".
+var _ = compile_com_a_tag_sqlite_fts5
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidInvite é retornado pelo RegisterUser quando o código de convite não existe,
+// expirou ou já foi usado o máximo de vezes.
+var ErrInvalidInvite = errors.New("código de convite inválido, expirado ou esgotado")
+
+// Invite é um código que permite cadastrar novas contas.
+type Invite struct {
+	ID          int64
+	Code        string
+	CreatedBy   int64  // 0 nos convites criados pelo bbs-admin
+	CreatorName string // Nome de quem criou o convite, vazio se CreatedBy for 0
+	MaxUses     int
+	Uses        int
+	ExpiresAt   *time.Time // nil se o convite não expira
+	CreatedAt   time.Time
+}
+
+// Active informa se o convite ainda pode ser usado no instante informado.
+func (i *Invite) Active(now time.Time) bool {
+	return i.Uses < i.MaxUses && (i.ExpiresAt == nil || now.Before(*i.ExpiresAt))
+}
+
+// inviteAlphabet omite os caracteres que se confundem (0/O, 1/I/L).
+const inviteAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// newInviteCode gera um código aleatório no formato XXXX-XXXX. O rand.Int evita que as
+// primeiras letras do alfabeto saiam com mais frequência, como no resto de um byte aleatório.
+func newInviteCode() (string, error) {
+	size := big.NewInt(int64(len(inviteAlphabet)))
+	code := make([]byte, 0, 9)
+	for i := range 8 {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("falha ao gerar código de convite: %w", err)
+		}
+		code = append(code, inviteAlphabet[n.Int64()])
+	}
+	return string(code), nil
+}
+
+// normalizeInviteCode aceita o código digitado em minúsculas ou com espaços nas pontas.
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// validateInvite verifica os parâmetros de um novo convite.
+func validateInvite(maxUses int, expiresAt *time.Time) error {
+	if maxUses < 1 {
+		return fmt.Errorf("o convite deve permitir ao menos um uso")
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("a validade do convite deve estar no futuro")
+	}
+	return nil
+}
+
+// CreateInvite gera um convite. createdBy é 0 para os convites criados pelo bbs-admin.
+func (s *SQLiteStore) CreateInvite(createdBy int64, maxUses int, expiresAt *time.Time) (*Invite, error) {
+	if err := validateInvite(maxUses, expiresAt); err != nil {
+		return nil, err
+	}
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	var creator, expires any
+	if createdBy != 0 {
+		creator = createdBy
+	}
+	if expiresAt != nil {
+		expires = storedTime(*expiresAt)
+	}
+	res, err := s.db.Exec("INSERT INTO invites (code, created_by, max_uses, expires_at) VALUES (?, ?, ?, ?)",
+		code, creator, maxUses, expires)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar convite: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter ID do convite: %w", err)
+	}
+
+	return s.getInvite("i.id = ?", id)
+}
+
+const inviteColumns = `
+	SELECT i.id, i.code, COALESCE(i.created_by, 0), COALESCE(u.username, ''), i.max_uses, i.uses, i.expires_at, i.created_at
+	FROM invites i LEFT JOIN users u ON u.id = i.created_by`
+
+func (s *SQLiteStore) getInvite(where string, args ...any) (*Invite, error) {
+	invite, err := scanInvite(s.db.QueryRow(inviteColumns+" WHERE "+where, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return invite, err
+}
+
+// GetInvites retorna todos os convites, dos mais novos para os mais antigos.
+func (s *SQLiteStore) GetInvites() ([]Invite, error) {
+	return s.queryInvites(inviteColumns + " ORDER BY i.id DESC")
+}
+
+// GetInvitesByUser retorna os convites criados pelo usuário, dos mais novos para os mais antigos.
+func (s *SQLiteStore) GetInvitesByUser(userID int64) ([]Invite, error) {
+	return s.queryInvites(inviteColumns+" WHERE i.created_by = ? ORDER BY i.id DESC", userID)
+}
+
+func (s *SQLiteStore) queryInvites(query string, args ...any) ([]Invite, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar convites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []Invite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+	return invites, rows.Err()
+}
+
+// CountOpenInviteUses retorna quantos cadastros ainda podem ser feitos com os convites
+// ativos do usuário. É o número comparado à cota de convites do papel.
+func (s *SQLiteStore) CountOpenInviteUses(userID int64) (int, error) {
+	var open int
+	err := s.db.QueryRow(`
+		SELECT COALESCE(SUM(max_uses - uses), 0) FROM invites
+		WHERE created_by = ? AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)
+	`, userID, storedTime(time.Now())).Scan(&open)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao contar convites: %w", err)
+	}
+	return open, nil
+}
+
+// RevokeInvite apaga o convite, que deixa de poder ser usado. As contas já cadastradas
+// com ele continuam registrando quem as convidou.
+func (s *SQLiteStore) RevokeInvite(code string) error {
+	code = normalizeInviteCode(code)
+	res, err := s.db.Exec("DELETE FROM invites WHERE code = ?", code)
+	if err != nil {
+		return fmt.Errorf("falha ao revogar convite: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("convite %s não encontrado", code)
+	}
+	return nil
+}
+
+func scanInvite(row rowScanner) (*Invite, error) {
+	invite := &Invite{}
+	var expiresAt sql.NullTime
+	if err := row.Scan(&invite.ID, &invite.Code, &invite.CreatedBy, &invite.CreatorName, &invite.MaxUses,
+		&invite.Uses, &expiresAt, &invite.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("falha ao escanear convite: %w", err)
+	}
+	if expiresAt.Valid {
+		invite.ExpiresAt = &expiresAt.Time
+	}
+	return invite, nil
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// UserKey representa uma chave pública SSH autorizada para um usuário.
+type UserKey struct {
+	ID          int64
+	UserID      int64
+	Fingerprint string
+	KeyType     string
+	PublicKey   string // Linha no formato authorized_keys, sem o comentário
+	Comment     string
+	AddedAt     time.Time
+	LastUsedAt  *time.Time
+}
+
+// AddUserKey adiciona uma chave pública (no formato authorized_keys) a um usuário.
+func (s *SQLiteStore) AddUserKey(username, authorizedKey, comment string) (*UserKey, error) {
+	user, _, err := s.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+
+	key, err := newUserKey(user.ID, authorizedKey, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.db.Exec(`INSERT INTO user_keys (user_id, fingerprint, key_type, public_key, comment) VALUES (?, ?, ?, ?, ?)`,
+		key.UserID, key.Fingerprint, key.KeyType, key.PublicKey, key.Comment)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("a chave %s já está cadastrada para '%s'", key.Fingerprint, username)
+		}
+		return nil, fmt.Errorf("falha ao adicionar chave: %w", err)
+	}
+
+	key.ID, err = res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter o ID da chave: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetUserKeys retorna as chaves públicas cadastradas para um usuário.
+func (s *SQLiteStore) GetUserKeys(username string) ([]UserKey, error) {
+	rows, err := s.db.Query(`
+		SELECT k.id, k.user_id, k.fingerprint, k.key_type, k.public_key, k.comment, k.added_at, k.last_used_at
+		FROM user_keys k
+		JOIN users u ON k.user_id = u.id
+		WHERE u.username = ?
+		ORDER BY k.added_at ASC
+	`, username)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar chaves: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []UserKey
+	for rows.Next() {
+		key, err := scanUserKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, nil
+}
+
+// FindUserKey procura, entre as chaves de um usuário, aquela que corresponde à chave apresentada.
+// Retorna nil se a chave não estiver autorizada.
+func (s *SQLiteStore) FindUserKey(username string, pubKey ssh.PublicKey) (*UserKey, error) {
+	row := s.db.QueryRow(`
+		SELECT k.id, k.user_id, k.fingerprint, k.key_type, k.public_key, k.comment, k.added_at, k.last_used_at
+		FROM user_keys k
+		JOIN users u ON k.user_id = u.id
+		WHERE u.username = ? AND k.fingerprint = ?
+	`, username, ssh.FingerprintSHA256(pubKey))
+
+	key, err := scanUserKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// TouchUserKey registra o momento em que uma chave foi usada para autenticar.
+func (s *SQLiteStore) TouchUserKey(id int64) error {
+	_, err := s.db.Exec("UPDATE user_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("falha ao atualizar uso da chave: %w", err)
+	}
+	return nil
+}
+
+// RemoveUserKey remove uma chave de um usuário pelo fingerprint.
+// A última chave não pode ser removida enquanto o login por senha estiver desabilitado.
+func (s *SQLiteStore) RemoveUserKey(username, fingerprint string) error {
+	keys, err := s.GetUserKeys(username)
+	if err != nil {
+		return err
+	}
+
+	var target *UserKey
+	for i := range keys {
+		if keys[i].Fingerprint == fingerprint {
+			target = &keys[i]
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("chave %s não encontrada para '%s'", fingerprint, username)
+	}
+
+	if len(keys) == 1 {
+		disabled, err := s.IsPasswordLoginDisabled(username)
+		if err != nil {
+			return err
+		}
+		if disabled {
+			return fmt.Errorf("não é possível remover a última chave com o login por senha desabilitado")
+		}
+	}
+
+	if _, err := s.db.Exec("DELETE FROM user_keys WHERE id = ?", target.ID); err != nil {
+		return fmt.Errorf("falha ao remover chave: %w", err)
+	}
+
+	return nil
+}
+
+// IsPasswordLoginDisabled informa se o usuário desabilitou o login por senha.
+func (s *SQLiteStore) IsPasswordLoginDisabled(username string) (bool, error) {
+	var disabled bool
+	err := s.db.QueryRow(`
+		SELECT a.password_disabled
+		FROM user_auth a
+		JOIN users u ON a.user_id = u.id
+		WHERE u.username = ?
+	`, username).Scan(&disabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("falha ao consultar configuração de autenticação: %w", err)
+	}
+	return disabled, nil
+}
+
+// SetPasswordLoginDisabled habilita ou desabilita o login por senha de um usuário.
+// Só é possível desabilitar a senha se o usuário tiver ao menos uma chave cadastrada.
+func (s *SQLiteStore) SetPasswordLoginDisabled(username string, disabled bool) error {
+	user, _, err := s.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+
+	if disabled {
+		keys, err := s.GetUserKeys(username)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("cadastre uma chave SSH antes de desabilitar o login por senha")
+		}
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO user_auth (user_id, password_disabled) VALUES (?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET password_disabled = excluded.password_disabled
+	`, user.ID, disabled)
+	if err != nil {
+		return fmt.Errorf("falha ao atualizar configuração de autenticação: %w", err)
+	}
+
+	return nil
+}
+
+// newUserKey interpreta uma linha no formato authorized_keys. Se o comentário
+// estiver vazio, o comentário da própria chave é usado.
+func newUserKey(userID int64, authorizedKey, comment string) (*UserKey, error) {
+	pubKey, keyComment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(authorizedKey)))
+	if err != nil {
+		return nil, fmt.Errorf("chave pública inválida: %w", err)
+	}
+	if comment == "" {
+		comment = keyComment
+	}
+
+	return &UserKey{
+		UserID:      userID,
+		Fingerprint: ssh.FingerprintSHA256(pubKey),
+		KeyType:     pubKey.Type(),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pubKey))),
+		Comment:     comment,
+		AddedAt:     time.Now(),
+	}, nil
+}
+
+// rowScanner abstrai *sql.Row e *sql.Rows para reaproveitar o scan.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUserKey(row rowScanner) (*UserKey, error) {
+	key := &UserKey{}
+	var comment sql.NullString
+	var lastUsed sql.NullTime
+	if err := row.Scan(&key.ID, &key.UserID, &key.Fingerprint, &key.KeyType, &key.PublicKey, &comment, &key.AddedAt, &lastUsed); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("falha ao escanear chave: %w", err)
+	}
+	key.Comment = comment.String
+	if lastUsed.Valid {
+		key.LastUsedAt = &lastUsed.Time
+	}
+	return key, nil
+}
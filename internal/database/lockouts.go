@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Escopos das falhas de login. Em LockoutScopeRegister, cada tentativa de cadastro de um
+// endereço IP conta como uma falha, com ou sem sucesso.
+const (
+	LockoutScopeIP       = "ip"
+	LockoutScopeUser     = "user"
+	LockoutScopeRegister = "register"
+)
+
+// Lockout registra as falhas de login recentes de um endereço IP ou de um nome de usuário,
+// ou os cadastros de um endereço IP, e, se for o caso, até quando novas tentativas estão
+// bloqueadas.
+type Lockout struct {
+	Scope         string // LockoutScopeIP, LockoutScopeUser ou LockoutScopeRegister
+	Target        string // O endereço IP ou o nome de usuário
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time // nil se as tentativas não estiverem bloqueadas
+}
+
+// Locked informa se as tentativas estão bloqueadas no instante informado.
+func (l *Lockout) Locked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+// storedTime normaliza os horários gravados, para que as comparações feitas pelo SQLite
+// entre os textos das datas correspondam à ordem cronológica.
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// GetLockout retorna as falhas registradas para o alvo, ou nil se não houver nenhuma.
+func (s *SQLiteStore) GetLockout(scope, target string) (*Lockout, error) {
+	row := s.db.QueryRow(`
+		SELECT scope, target, failures, last_failure_at, locked_until
+		FROM login_lockouts WHERE scope = ? AND target = ?
+	`, scope, target)
+	l, err := scanLockout(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return l, err
+}
+
+// SaveLockout grava as falhas do alvo, substituindo o registro anterior.
+func (s *SQLiteStore) SaveLockout(l *Lockout) error {
+	var lockedUntil any
+	if l.LockedUntil != nil {
+		lockedUntil = storedTime(*l.LockedUntil)
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO login_lockouts (scope, target, failures, last_failure_at, locked_until) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(scope, target) DO UPDATE SET
+			failures = excluded.failures,
+			last_failure_at = excluded.last_failure_at,
+			locked_until = excluded.locked_until
+	`, l.Scope, l.Target, l.Failures, storedTime(l.LastFailureAt), lockedUntil)
+	if err != nil {
+		return fmt.Errorf("falha ao gravar falhas de login: %w", err)
+	}
+	return nil
+}
+
+// DeleteLockout apaga as falhas do alvo, desbloqueando-o. Retorna false se não havia registro.
+func (s *SQLiteStore) DeleteLockout(scope, target string) (bool, error) {
+	res, err := s.db.Exec("DELETE FROM login_lockouts WHERE scope = ? AND target = ?", scope, target)
+	if err != nil {
+		return false, fmt.Errorf("falha ao apagar falhas de login: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao apagar falhas de login: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetLockouts retorna todos os registros de falhas, dos mais recentes para os mais antigos.
+func (s *SQLiteStore) GetLockouts() ([]Lockout, error) {
+	rows, err := s.db.Query(`
+		SELECT scope, target, failures, last_failure_at, locked_until
+		FROM login_lockouts ORDER BY last_failure_at DESC, scope, target
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar falhas de login: %w", err)
+	}
+	defer rows.Close()
+
+	var lockouts []Lockout
+	for rows.Next() {
+		l, err := scanLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, *l)
+	}
+	return lockouts, rows.Err()
+}
+
+// PurgeLockouts apaga os registros sem falhas desde before e que não estejam mais bloqueados.
+func (s *SQLiteStore) PurgeLockouts(before time.Time) (int64, error) {
+	before = storedTime(before)
+	res, err := s.db.Exec(`
+		DELETE FROM login_lockouts
+		WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)
+	`, before, before)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao expurgar falhas de login: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func scanLockout(row rowScanner) (*Lockout, error) {
+	l := &Lockout{}
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&l.Scope, &l.Target, &l.Failures, &l.LastFailureAt, &lockedUntil); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("falha ao escanear falhas de login: %w", err)
+	}
+	if lockedUntil.Valid {
+		l.LockedUntil = &lockedUntil.Time
+	}
+	return l, nil
+}
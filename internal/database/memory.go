@@ -0,0 +1,2118 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+// MemoryStore é uma implementação de Store mantida inteiramente em memória.
+// É destinada a testes e execuções efêmeras: os dados se perdem ao fechar o processo.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	users  map[int64]*memoryUser
+	keys   []UserKey
+	forums map[int64]*Forum
+	grants []ForumGrant // Permissões por fórum, em ordem de concessão
+	mods   map[forumModKey]bool
+	topics map[int]*Topic
+	posts  map[int]*Post
+	revs   []PostRevision // Revisões dos posts, em ordem de ID
+	trash  map[trashKey]trashEntry
+	reads  map[readKey]int // Último post lido, por usuário e tópico
+
+	conversations map[int64]*memoryConversation
+	messages      []Message // Em ordem de ID
+	blocks        map[blockKey]bool
+	chat          []ChatMessage // Em ordem de ID
+	lockouts      map[lockoutKey]Lockout
+	invites       []*Invite // Em ordem de ID
+
+	lastUserID         int64
+	lastKeyID          int64
+	lastForumID        int64
+	lastTopicID        int
+	lastPostID         int
+	lastRevisionID     int64
+	lastConversationID int64
+	lastMessageID      int64
+	lastChatID         int64
+	lastInviteID       int64
+}
+
+// memoryUser guarda, junto com o usuário, os dados que no SQLite ficam em outras colunas e tabelas.
+type memoryUser struct {
+	User
+	passwordHash     string
+	passwordDisabled bool
+	totpSecret       string
+	totpLastStep     int64
+	recoveryCodes    map[string]bool // Hash do código → já usado
+}
+
+// lockoutKey identifica as falhas de login de um alvo.
+type lockoutKey struct {
+	scope  string
+	target string
+}
+
+// readKey identifica o estado de leitura de um usuário em um tópico.
+type readKey struct {
+	userID  int64
+	topicID int
+}
+
+// memoryConversation guarda uma conversa e o estado de cada participante.
+type memoryConversation struct {
+	id           int64
+	subject      string
+	createdAt    time.Time
+	participants map[int64]*memoryParticipant
+}
+
+type memoryParticipant struct {
+	lastRead int64
+	deleted  bool
+}
+
+// forumModKey identifica o moderador userID do fórum forumID.
+type forumModKey struct {
+	forumID int64
+	userID  int64
+}
+
+// trashKey identifica um fórum, tópico ou post apagado.
+type trashKey struct {
+	kind TrashKind
+	id   int64
+}
+
+// trashEntry guarda quem apagou o item, quando e por quê.
+type trashEntry struct {
+	deletedAt time.Time
+	deletedBy int64
+	reason    string
+}
+
+// blockKey identifica o bloqueio de blockedID por userID.
+type blockKey struct {
+	userID    int64
+	blockedID int64
+}
+
+// NewMemoryStore cria um MemoryStore vazio.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:  make(map[int64]*memoryUser),
+		forums: make(map[int64]*Forum),
+		topics: make(map[int]*Topic),
+		posts:  make(map[int]*Post),
+		reads:  make(map[readKey]int),
+		mods:   make(map[forumModKey]bool),
+		trash:  make(map[trashKey]trashEntry),
+
+		conversations: make(map[int64]*memoryConversation),
+		blocks:        make(map[blockKey]bool),
+		lockouts:      make(map[lockoutKey]Lockout),
+	}
+}
+
+// Close não faz nada; existe para satisfazer a interface Store.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// hashPassword usa o custo mínimo do bcrypt para manter os testes rápidos.
+// O hash continua compatível com CheckPasswordHash.
+func (s *MemoryStore) hashPassword(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	return string(bytes), err
+}
+
+// userByName deve ser chamado com o mutex travado.
+func (s *MemoryStore) userByName(username string) *memoryUser {
+	for _, u := range s.users {
+		if u.Username == username {
+			return u
+		}
+	}
+	return nil
+}
+
+// --- Usuários ---
+
+// usernameTakenLocked informa se o nome já pertence a uma conta, sem diferenciar
+// maiúsculas de minúsculas, como o índice users_username_nocase do SQLite.
+func (s *MemoryStore) usernameTakenLocked(username string) bool {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) CreateUser(username, password string) (*User, error) {
+	passwordHash, err := s.hashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar hash da senha: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usernameTakenLocked(username) {
+		return nil, fmt.Errorf("falha ao criar usuário: o usuário '%s' já existe", username)
+	}
+
+	s.lastUserID++
+	u := &memoryUser{
+		User:         User{ID: s.lastUserID, Username: username, Role: "user", Status: UserStatusActive, CreatedAt: time.Now()},
+		passwordHash: passwordHash,
+	}
+	s.users[u.ID] = u
+
+	return &User{ID: u.ID, Username: username}, nil
+}
+
+func (s *MemoryStore) RegisterUser(account NewAccount) (*User, error) {
+	// O nome e o convite são conferidos, e o uso do convite reservado, antes do hash da
+	// senha, que é lento e roda sem o lock.
+	s.mu.Lock()
+	if s.usernameTakenLocked(account.Username) {
+		s.mu.Unlock()
+		return nil, ErrUsernameTaken
+	}
+	var invite *Invite
+	if account.InviteCode != "" {
+		invite = s.inviteByCodeLocked(normalizeInviteCode(account.InviteCode))
+		if invite == nil || !invite.Active(time.Now()) {
+			s.mu.Unlock()
+			return nil, ErrInvalidInvite
+		}
+		invite.Uses++
+	}
+	s.mu.Unlock()
+
+	passwordHash, err := s.hashPassword(account.Password)
+	if err != nil {
+		err = fmt.Errorf("falha ao gerar hash da senha: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil && s.usernameTakenLocked(account.Username) {
+		err = ErrUsernameTaken
+	}
+	if err != nil {
+		if invite != nil && invite.Uses > 0 {
+			invite.Uses--
+		}
+		return nil, err
+	}
+
+	status := UserStatusActive
+	if account.Pending {
+		status = UserStatusPending
+	}
+	s.lastUserID++
+	u := &memoryUser{
+		User: User{ID: s.lastUserID, Username: account.Username, Role: "user", Email: account.Email,
+			Status: status, CreatedAt: time.Now()},
+		passwordHash: passwordHash,
+	}
+	if invite != nil {
+		u.InvitedBy = invite.CreatedBy
+	}
+	s.users[u.ID] = u
+
+	user := u.User
+	return &user, nil
+}
+
+func (s *MemoryStore) ApproveUser(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil || !u.Pending() {
+		return fmt.Errorf("nenhum cadastro pendente para '%s'", username)
+	}
+	u.Status = UserStatusActive
+	return nil
+}
+
+func (s *MemoryStore) GetUserByUsername(username string) (*User, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return nil, "", nil
+	}
+	user := u.User
+	return &user, u.passwordHash, nil
+}
+
+func (s *MemoryStore) GetAllUsers() ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []User
+	for _, u := range s.users {
+		users = append(users, u.User)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return users, nil
+}
+
+func (s *MemoryStore) UpdateUserPassword(username, currentPassword, newPassword string) error {
+	_, passwordHash, err := s.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if passwordHash == "" {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if !CheckPasswordHash(currentPassword, passwordHash) {
+		return fmt.Errorf("senha atual incorreta")
+	}
+	return s.AdminResetPassword(username, newPassword)
+}
+
+func (s *MemoryStore) AdminResetPassword(username, newPassword string) error {
+	newPasswordHash, err := s.hashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("falha ao gerar hash da nova senha: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Assim como o UPDATE do SQLite, um usuário inexistente não é erro.
+	if u := s.userByName(username); u != nil {
+		u.passwordHash = newPasswordHash
+	}
+	return nil
+}
+
+func (s *MemoryStore) SetUserRole(username, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	u.Role = role
+	return nil
+}
+
+func (s *MemoryStore) DeleteUser(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return nil
+	}
+	// Como a chave estrangeira do SQLite, o conteúdo do usuário impede a deleção.
+	if s.hasContentLocked(u.ID) {
+		return ErrUserHasContent
+	}
+
+	keys := s.keys[:0]
+	for _, k := range s.keys {
+		if k.UserID != u.ID {
+			keys = append(keys, k)
+		}
+	}
+	s.keys = keys
+	for k := range s.reads {
+		if k.userID == u.ID {
+			delete(s.reads, k)
+		}
+	}
+	for k := range s.blocks {
+		if k.userID == u.ID || k.blockedID == u.ID {
+			delete(s.blocks, k)
+		}
+	}
+	for _, c := range s.conversations {
+		delete(c.participants, u.ID)
+	}
+	invites := s.invites[:0]
+	for _, i := range s.invites {
+		if i.CreatedBy != u.ID {
+			invites = append(invites, i)
+		}
+	}
+	s.invites = invites
+	s.removeGrantsLocked(func(g ForumGrant) bool { return g.UserID == u.ID })
+	for k := range s.mods {
+		if k.userID == u.ID {
+			delete(s.mods, k)
+		}
+	}
+	for _, other := range s.users {
+		if other.InvitedBy == u.ID {
+			other.InvitedBy = 0
+		}
+	}
+	delete(s.users, u.ID)
+
+	return nil
+}
+
+// hasContentLocked informa se o usuário escreveu tópicos, posts ou mensagens, ou editou
+// posts. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) hasContentLocked(userID int64) bool {
+	for _, t := range s.topics {
+		if int64(t.UserID) == userID {
+			return true
+		}
+	}
+	for _, p := range s.posts {
+		if int64(p.UserID) == userID {
+			return true
+		}
+	}
+	for _, r := range s.revs {
+		if r.EditorID == userID {
+			return true
+		}
+	}
+	for _, m := range s.messages {
+		if m.SenderID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Chaves SSH ---
+
+func (s *MemoryStore) AddUserKey(username, authorizedKey, comment string) (*UserKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return nil, fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+
+	key, err := newUserKey(u.ID, authorizedKey, comment)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range s.keys {
+		if k.UserID == u.ID && k.Fingerprint == key.Fingerprint {
+			return nil, fmt.Errorf("a chave %s já está cadastrada para '%s'", key.Fingerprint, username)
+		}
+	}
+
+	s.lastKeyID++
+	key.ID = s.lastKeyID
+	s.keys = append(s.keys, *key)
+
+	return key, nil
+}
+
+func (s *MemoryStore) GetUserKeys(username string) ([]UserKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return nil, nil
+	}
+
+	var keys []UserKey
+	for _, k := range s.keys {
+		if k.UserID == u.ID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (s *MemoryStore) FindUserKey(username string, pubKey ssh.PublicKey) (*UserKey, error) {
+	keys, err := s.GetUserKeys(username)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+	for _, k := range keys {
+		if k.Fingerprint == fingerprint {
+			return &k, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) TouchUserKey(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.keys {
+		if s.keys[i].ID == id {
+			s.keys[i].LastUsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) RemoveUserKey(username, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("chave %s não encontrada para '%s'", fingerprint, username)
+	}
+
+	index, count := -1, 0
+	for i, k := range s.keys {
+		if k.UserID == u.ID {
+			count++
+			if k.Fingerprint == fingerprint {
+				index = i
+			}
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("chave %s não encontrada para '%s'", fingerprint, username)
+	}
+	if count == 1 && u.passwordDisabled {
+		return fmt.Errorf("não é possível remover a última chave com o login por senha desabilitado")
+	}
+
+	s.keys = append(s.keys[:index], s.keys[index+1:]...)
+	return nil
+}
+
+func (s *MemoryStore) IsPasswordLoginDisabled(username string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if u := s.userByName(username); u != nil {
+		return u.passwordDisabled, nil
+	}
+	return false, nil
+}
+
+func (s *MemoryStore) SetPasswordLoginDisabled(username string, disabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+
+	if disabled {
+		hasKey := false
+		for _, k := range s.keys {
+			if k.UserID == u.ID {
+				hasKey = true
+			}
+		}
+		if !hasKey {
+			return fmt.Errorf("cadastre uma chave SSH antes de desabilitar o login por senha")
+		}
+	}
+
+	u.passwordDisabled = disabled
+	return nil
+}
+
+// --- Verificação em duas etapas ---
+
+func (s *MemoryStore) GetTOTPSecret(username string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if u := s.userByName(username); u != nil {
+		return u.totpSecret, nil
+	}
+	return "", nil
+}
+
+func (s *MemoryStore) EnableTOTP(username, secret string, recoveryCodes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	u.totpSecret = secret
+	u.totpLastStep = 0
+	u.setRecoveryCodes(recoveryCodes)
+	return nil
+}
+
+func (s *MemoryStore) SetRecoveryCodes(username string, recoveryCodes []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if u.totpSecret == "" {
+		return fmt.Errorf("a verificação em duas etapas não está ativa para '%s'", username)
+	}
+	u.setRecoveryCodes(recoveryCodes)
+	return nil
+}
+
+func (u *memoryUser) setRecoveryCodes(recoveryCodes []string) {
+	u.recoveryCodes = make(map[string]bool, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		u.recoveryCodes[hashRecoveryCode(code)] = false
+	}
+}
+
+func (s *MemoryStore) DisableTOTP(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if u.totpSecret == "" {
+		return fmt.Errorf("a verificação em duas etapas não está ativa para '%s'", username)
+	}
+	u.totpSecret = ""
+	u.totpLastStep = 0
+	u.recoveryCodes = nil
+	return nil
+}
+
+func (s *MemoryStore) UseTOTPStep(username string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil || u.totpSecret == "" || step <= u.totpLastStep {
+		return false, nil
+	}
+	u.totpLastStep = step
+	return true, nil
+}
+
+func (s *MemoryStore) UseRecoveryCode(username, code string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return false, nil
+	}
+	hash := hashRecoveryCode(code)
+	if used, ok := u.recoveryCodes[hash]; !ok || used {
+		return false, nil
+	}
+	u.recoveryCodes[hash] = true
+	return true, nil
+}
+
+func (s *MemoryStore) CountRecoveryCodes(username string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	if u := s.userByName(username); u != nil {
+		for _, used := range u.recoveryCodes {
+			if !used {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// --- Fóruns ---
+
+func (s *MemoryStore) CreateForum(name, description string) (*Forum, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.forums {
+		if f.Name == name && !s.trashedLocked(TrashForum, f.ID) {
+			return nil, fmt.Errorf("falha ao executar statement: o fórum '%s' já existe", name)
+		}
+	}
+
+	s.lastForumID++
+	forum := &Forum{ID: s.lastForumID, Name: name, Description: description, CreatedAt: time.Now()}
+	s.forums[forum.ID] = forum
+
+	c := *forum
+	return &c, nil
+}
+
+func (s *MemoryStore) UpdateForum(id int64, name, description string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.forums {
+		if f.ID != id && f.Name == name && !s.trashedLocked(TrashForum, f.ID) {
+			return fmt.Errorf("falha ao atualizar fórum: o fórum '%s' já existe", name)
+		}
+	}
+	if f, ok := s.forums[id]; ok {
+		f.Name = name
+		f.Description = description
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteForum(id, deletedBy int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.forums[id]; !ok {
+		return fmt.Errorf("fórum %d não encontrado", id)
+	}
+	return s.moveToTrashLocked(TrashForum, id, deletedBy, reason)
+}
+
+// purgeForumLocked remove definitivamente o fórum, com seus tópicos e posts. Deve ser
+// chamado com o mutex travado.
+func (s *MemoryStore) purgeForumLocked(id int64) {
+	for topicID, t := range s.topics {
+		if int64(t.ForumID) == id {
+			s.deleteTopicLocked(topicID)
+		}
+	}
+	s.removeGrantsLocked(func(g ForumGrant) bool { return g.ForumID == id })
+	for k := range s.mods {
+		if k.forumID == id {
+			delete(s.mods, k)
+		}
+	}
+	delete(s.trash, trashKey{TrashForum, id})
+	delete(s.forums, id)
+}
+
+func (s *MemoryStore) GetAllForums() ([]Forum, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var forums []Forum
+	for _, f := range s.forums {
+		if s.forumLiveLocked(f.ID) {
+			forums = append(forums, *f)
+		}
+	}
+	sort.Slice(forums, func(i, j int) bool { return forums[i].Name < forums[j].Name })
+
+	return forums, nil
+}
+
+func (s *MemoryStore) GetGuestForums() ([]Forum, error) {
+	forums, err := s.GetAllForums()
+	if err != nil {
+		return nil, err
+	}
+	guest := forums[:0]
+	for _, f := range forums {
+		if f.GuestAccess {
+			guest = append(guest, f)
+		}
+	}
+	return guest, nil
+}
+
+func (s *MemoryStore) SetForumGuestAccess(id int64, allowed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.forums[id]
+	if !ok {
+		return fmt.Errorf("fórum %d não encontrado", id)
+	}
+	f.GuestAccess = allowed
+	return nil
+}
+
+// --- Permissões por fórum ---
+
+// grantSubjectLocked valida quem recebe a permissão. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) grantSubjectLocked(role, username string) (string, int64, error) {
+	if (role == "") == (username == "") {
+		return "", 0, fmt.Errorf("informe um papel ou um usuário, não os dois")
+	}
+	if role != "" {
+		return role, 0, validateRole(role)
+	}
+	u := s.userByName(username)
+	if u == nil {
+		return "", 0, fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	return "", u.ID, nil
+}
+
+// removeGrantsLocked apaga as concessões que satisfazem remove. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) removeGrantsLocked(remove func(ForumGrant) bool) int {
+	grants := s.grants[:0]
+	for _, g := range s.grants {
+		if !remove(g) {
+			grants = append(grants, g)
+		}
+	}
+	n := len(s.grants) - len(grants)
+	s.grants = grants
+	return n
+}
+
+func (s *MemoryStore) GrantForumPermission(forumID int64, perm ForumPermission, role, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, userID, err := s.grantSubjectLocked(role, username)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.forums[forumID]; !ok {
+		return fmt.Errorf("fórum %d não encontrado", forumID)
+	}
+	for _, g := range s.grants {
+		if g.ForumID == forumID && g.Permission == perm && g.Role == role && g.UserID == userID {
+			return nil
+		}
+	}
+	s.grants = append(s.grants, ForumGrant{ForumID: forumID, Permission: perm, Role: role, UserID: userID, CreatedAt: time.Now()})
+	return nil
+}
+
+func (s *MemoryStore) RevokeForumPermission(forumID int64, perm ForumPermission, role, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, userID, err := s.grantSubjectLocked(role, username)
+	if err != nil {
+		return err
+	}
+	n := s.removeGrantsLocked(func(g ForumGrant) bool {
+		return g.ForumID == forumID && g.Permission == perm && g.Role == role && g.UserID == userID
+	})
+	if n == 0 {
+		return fmt.Errorf("a permissão não foi concedida")
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetForumGrants(forumID int64) ([]ForumGrant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var grants []ForumGrant
+	for _, g := range s.grants {
+		if g.ForumID != forumID {
+			continue
+		}
+		if u, ok := s.users[g.UserID]; ok {
+			g.Username = u.Username
+		}
+		grants = append(grants, g)
+	}
+	order := make(map[ForumPermission]int)
+	for i, p := range ForumPermissions {
+		order[p] = i
+	}
+	sort.SliceStable(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if a.Permission != b.Permission {
+			return order[a.Permission] < order[b.Permission]
+		}
+		if (a.Role == "") != (b.Role == "") {
+			return a.Role != ""
+		}
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		return a.Username < b.Username
+	})
+	return grants, nil
+}
+
+// forumAllowedLocked aplica as mesmas regras de forumAccessFilter. Deve ser chamado com o
+// mutex travado.
+func (s *MemoryStore) forumAllowedLocked(userID, forumID int64, perm ForumPermission) bool {
+	if !s.forumLiveLocked(forumID) {
+		return false
+	}
+	if userID == 0 {
+		return perm == PermissionView && s.forums[forumID].GuestAccess
+	}
+	u, ok := s.users[userID]
+	if !ok {
+		return false
+	}
+	if u.Role == "admin" || s.mods[forumModKey{forumID, userID}] {
+		return true
+	}
+	allowed := func(p ForumPermission) bool {
+		restricted := false
+		for _, g := range s.grants {
+			if g.ForumID != forumID || g.Permission != p {
+				continue
+			}
+			if g.UserID == userID || (g.Role != "" && g.Role == u.Role) {
+				return true
+			}
+			restricted = true
+		}
+		return !restricted && p.defaultFor(u.Role)
+	}
+	if perm != PermissionView && !allowed(PermissionView) {
+		return false
+	}
+	return allowed(perm)
+}
+
+func (s *MemoryStore) HasForumPermission(userID, forumID int64, perm ForumPermission) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.forums[forumID]
+	return ok && s.forumAllowedLocked(userID, forumID, perm), nil
+}
+
+func (s *MemoryStore) GetVisibleForums(userID int64) ([]Forum, error) {
+	forums, err := s.GetAllForums()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	visible := forums[:0]
+	for _, f := range forums {
+		if s.forumAllowedLocked(userID, f.ID, PermissionView) {
+			visible = append(visible, f)
+		}
+	}
+	return visible, nil
+}
+
+func (s *MemoryStore) AddForumModerator(forumID int64, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if _, ok := s.forums[forumID]; !ok {
+		return fmt.Errorf("fórum %d não encontrado", forumID)
+	}
+	s.mods[forumModKey{forumID, u.ID}] = true
+	return nil
+}
+
+func (s *MemoryStore) RemoveForumModerator(forumID int64, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil || !s.mods[forumModKey{forumID, u.ID}] {
+		return fmt.Errorf("'%s' não modera o fórum %d", username, forumID)
+	}
+	delete(s.mods, forumModKey{forumID, u.ID})
+	return nil
+}
+
+func (s *MemoryStore) GetForumModerators(forumID int64) ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []User
+	for k := range s.mods {
+		if u, ok := s.users[k.userID]; ok && k.forumID == forumID {
+			users = append(users, User{ID: u.ID, Username: u.Username, Role: u.Role})
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users, nil
+}
+
+// --- Tópicos ---
+
+func (s *MemoryStore) CreateTopic(forumID, userID int, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.forumAllowedLocked(int64(userID), int64(forumID), PermissionTopic) {
+		return ErrForumPermission
+	}
+
+	s.lastTopicID++
+	s.topics[s.lastTopicID] = &Topic{ID: s.lastTopicID, ForumID: forumID, UserID: userID, Title: title, CreatedAt: time.Now()}
+	return nil
+}
+
+// topicWithAuthor devolve uma cópia do tópico com o nome do autor, como o JOIN do SQLite.
+// Deve ser chamado com o mutex travado.
+func (s *MemoryStore) topicWithAuthor(t *Topic) (*Topic, bool) {
+	u, ok := s.users[int64(t.UserID)]
+	if !ok {
+		return nil, false
+	}
+	c := *t
+	c.Username = u.Username
+	return &c, true
+}
+
+func (s *MemoryStore) GetTopicByID(id int) (*Topic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.topicLiveLocked(id)
+	if !ok {
+		return nil, nil
+	}
+	topic, ok := s.topicWithAuthor(t)
+	if !ok {
+		return nil, nil
+	}
+	return topic, nil
+}
+
+func (s *MemoryStore) GetVisibleTopic(viewerID int64, id int) (*Topic, error) {
+	topic, err := s.GetTopicByID(id)
+	if err != nil || topic == nil {
+		return nil, err
+	}
+	visible, err := s.HasForumPermission(viewerID, int64(topic.ForumID), PermissionView)
+	if err != nil || !visible {
+		return nil, err
+	}
+	return topic, nil
+}
+
+func (s *MemoryStore) GetVisibleTopics(viewerID int64, forumID int) ([]*Topic, error) {
+	if visible, _ := s.HasForumPermission(viewerID, int64(forumID), PermissionView); !visible {
+		return nil, ErrForumPermission
+	}
+	return s.GetTopicsByForumID(forumID)
+}
+
+func (s *MemoryStore) GetTopicsByForumID(forumID int) ([]*Topic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var topics []*Topic
+	for _, t := range s.topics {
+		if t.ForumID != forumID || s.trashedLocked(TrashTopic, int64(t.ID)) {
+			continue
+		}
+		if topic, ok := s.topicWithAuthor(t); ok {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i].CreatedAt.Equal(topics[j].CreatedAt) {
+			return topics[i].ID > topics[j].ID
+		}
+		return topics[i].CreatedAt.After(topics[j].CreatedAt)
+	})
+
+	return topics, nil
+}
+
+func (s *MemoryStore) GetTopicsPageByForumID(viewerID int64, forumID int, after *PageCursor, limit int) ([]*Topic, error) {
+	topics, err := s.visibleTopics(viewerID, forumID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if after != nil {
+		start = len(topics)
+		for i, t := range topics {
+			if t.CreatedAt.Before(after.CreatedAt) || (t.CreatedAt.Equal(after.CreatedAt) && t.ID < after.ID) {
+				start = i
+				break
+			}
+		}
+	}
+	return topics[start:min(start+limit, len(topics))], nil
+}
+
+func (s *MemoryStore) CountTopicsByForumID(viewerID int64, forumID int) (int, error) {
+	topics, err := s.visibleTopics(viewerID, forumID)
+	return len(topics), err
+}
+
+// visibleTopics retorna os tópicos do fórum, ou nenhum se o usuário não puder vê-lo.
+func (s *MemoryStore) visibleTopics(viewerID int64, forumID int) ([]*Topic, error) {
+	if visible, _ := s.HasForumPermission(viewerID, int64(forumID), PermissionView); !visible {
+		return nil, nil
+	}
+	return s.GetTopicsByForumID(forumID)
+}
+
+func (s *MemoryStore) DeleteTopic(id int, deletedBy int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if deletedBy <= 0 {
+		return ErrForumPermission
+	}
+	t, ok := s.topics[id]
+	if !ok {
+		return fmt.Errorf("tópico %d não encontrado", id)
+	}
+	if !s.forumAllowedLocked(deletedBy, int64(t.ForumID), PermissionModerate) {
+		return ErrForumPermission
+	}
+	return s.moveToTrashLocked(TrashTopic, int64(id), deletedBy, reason)
+}
+
+func (s *MemoryStore) AdminDeleteTopic(id int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.topics[id]; !ok {
+		return fmt.Errorf("tópico %d não encontrado", id)
+	}
+	return s.moveToTrashLocked(TrashTopic, int64(id), 0, reason)
+}
+
+// deleteTopicLocked remove o tópico e seus posts. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) deleteTopicLocked(id int) {
+	for postID, p := range s.posts {
+		if p.TopicID == id {
+			s.deletePostLocked(postID)
+		}
+	}
+	for k := range s.reads {
+		if k.topicID == id {
+			delete(s.reads, k)
+		}
+	}
+	delete(s.trash, trashKey{TrashTopic, int64(id)})
+	delete(s.topics, id)
+}
+
+// --- Posts ---
+
+func (s *MemoryStore) CreatePost(topicID, userID int, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.topicLiveLocked(topicID)
+	if !ok {
+		return fmt.Errorf("tópico %d não encontrado", topicID)
+	}
+	if !s.forumAllowedLocked(int64(userID), int64(t.ForumID), PermissionReply) {
+		return ErrForumPermission
+	}
+
+	s.lastPostID++
+	s.posts[s.lastPostID] = &Post{ID: s.lastPostID, TopicID: topicID, UserID: userID, Content: content, CreatedAt: time.Now()}
+	return nil
+}
+
+func (s *MemoryStore) GetPostsByTopicID(topicID int) ([]*Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var posts []*Post
+	for _, p := range s.posts {
+		if p.TopicID != topicID || s.trashedLocked(TrashPost, int64(p.ID)) {
+			continue
+		}
+		u, ok := s.users[int64(p.UserID)]
+		if !ok {
+			continue
+		}
+		c := *p
+		c.Username = u.Username
+		posts = append(posts, &c)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID < posts[j].ID
+		}
+		return posts[i].CreatedAt.Before(posts[j].CreatedAt)
+	})
+
+	return posts, nil
+}
+
+func (s *MemoryStore) GetVisiblePosts(viewerID int64, topicID int) ([]*Post, error) {
+	topic, err := s.GetTopicByID(topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("tópico %d não encontrado", topicID)
+	}
+	if visible, _ := s.HasForumPermission(viewerID, int64(topic.ForumID), PermissionView); !visible {
+		return nil, ErrForumPermission
+	}
+	return s.GetPostsByTopicID(topicID)
+}
+
+func (s *MemoryStore) GetPostsPageByTopicID(viewerID int64, topicID int, after *PageCursor, limit int) ([]*Post, error) {
+	posts, err := s.visiblePosts(viewerID, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if after != nil {
+		start = len(posts)
+		for i, p := range posts {
+			if p.CreatedAt.After(after.CreatedAt) || (p.CreatedAt.Equal(after.CreatedAt) && p.ID > after.ID) {
+				start = i
+				break
+			}
+		}
+	}
+	return posts[start:min(start+limit, len(posts))], nil
+}
+
+func (s *MemoryStore) CountPostsByTopicID(viewerID int64, topicID int) (int, error) {
+	posts, err := s.visiblePosts(viewerID, topicID)
+	return len(posts), err
+}
+
+// visiblePosts retorna os posts do tópico, ou nenhum se o tópico estiver na lixeira ou se
+// o usuário não puder ver o seu fórum.
+func (s *MemoryStore) visiblePosts(viewerID int64, topicID int) ([]*Post, error) {
+	topic, err := s.GetVisibleTopic(viewerID, topicID)
+	if err != nil || topic == nil {
+		return nil, err
+	}
+	return s.GetPostsByTopicID(topicID)
+}
+
+func (s *MemoryStore) GetPostByID(id int) (*Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.posts[id]
+	if !ok || !s.postLiveLocked(p) {
+		return nil, nil
+	}
+	u, ok := s.users[int64(p.UserID)]
+	if !ok {
+		return nil, nil
+	}
+	c := *p
+	c.Username = u.Username
+	return &c, nil
+}
+
+func (s *MemoryStore) UpdatePost(postID int, editorID int64, content string, editWindow time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if editorID <= 0 {
+		return ErrForumPermission
+	}
+	p, ok := s.posts[postID]
+	if !ok || !s.postLiveLocked(p) {
+		return fmt.Errorf("post %d não encontrado", postID)
+	}
+	forumID := int64(s.topics[p.TopicID].ForumID)
+	if !s.forumAllowedLocked(editorID, forumID, PermissionModerate) {
+		if int64(p.UserID) != editorID || !s.forumAllowedLocked(editorID, forumID, PermissionReply) {
+			return ErrForumPermission
+		}
+		if editWindow > 0 && time.Since(p.CreatedAt) > editWindow {
+			return ErrEditWindow
+		}
+	}
+	if content == p.Content {
+		return nil
+	}
+
+	now := time.Now()
+	s.lastRevisionID++
+	s.revs = append(s.revs, PostRevision{ID: s.lastRevisionID, PostID: postID, Content: p.Content, EditorID: editorID, EditedAt: now})
+	p.Content = content
+	p.EditedAt = &now
+	return nil
+}
+
+func (s *MemoryStore) GetPostRevisions(viewerID int64, postID int) ([]PostRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.posts[postID]
+	if !ok || !s.postLiveLocked(p) || !s.forumAllowedLocked(viewerID, int64(s.topics[p.TopicID].ForumID), PermissionView) {
+		return nil, nil
+	}
+	var revisions []PostRevision
+	for _, r := range s.revs {
+		if r.PostID != postID {
+			continue
+		}
+		if u, ok := s.users[r.EditorID]; ok {
+			r.Editor = u.Username
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+func (s *MemoryStore) DeletePost(id int, deletedBy int64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if deletedBy <= 0 {
+		return ErrForumPermission
+	}
+	p, ok := s.posts[id]
+	if !ok {
+		return fmt.Errorf("post %d não encontrado", id)
+	}
+	t, ok := s.topics[p.TopicID]
+	if !ok || !s.forumAllowedLocked(deletedBy, int64(t.ForumID), PermissionModerate) {
+		return ErrForumPermission
+	}
+	return s.moveToTrashLocked(TrashPost, int64(id), deletedBy, reason)
+}
+
+func (s *MemoryStore) AdminDeletePost(id int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[id]; !ok {
+		return fmt.Errorf("post %d não encontrado", id)
+	}
+	return s.moveToTrashLocked(TrashPost, int64(id), 0, reason)
+}
+
+// deletePostLocked remove o post e suas revisões. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) deletePostLocked(id int) {
+	s.revs = slices.DeleteFunc(s.revs, func(r PostRevision) bool { return r.PostID == id })
+	delete(s.trash, trashKey{TrashPost, int64(id)})
+	delete(s.posts, id)
+}
+
+// --- Estado de leitura ---
+
+func (s *MemoryStore) MarkTopicRead(userID int64, topicID, lastPostID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := readKey{userID, topicID}
+	s.reads[k] = max(s.reads[k], lastPostID)
+	return nil
+}
+
+func (s *MemoryStore) GetLastReadPostID(userID int64, topicID int) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.reads[readKey{userID, topicID}], nil
+}
+
+// unreadLocked conta os posts não lidos por tópico. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) unreadLocked(userID int64) map[int]int {
+	counts := make(map[int]int)
+	for _, p := range s.posts {
+		if int64(p.UserID) != userID && p.ID > s.reads[readKey{userID, p.TopicID}] && s.postLiveLocked(p) {
+			counts[p.TopicID]++
+		}
+	}
+	return counts
+}
+
+func (s *MemoryStore) GetForumUnreadCounts(userID int64) (map[int64]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[int64]int)
+	for topicID, n := range s.unreadLocked(userID) {
+		if t, ok := s.topics[topicID]; ok {
+			counts[int64(t.ForumID)] += n
+		}
+	}
+	return counts, nil
+}
+
+func (s *MemoryStore) GetTopicUnreadCounts(userID int64, topicIDs []int) (map[int]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	unread := s.unreadLocked(userID)
+	counts := make(map[int]int)
+	for _, id := range topicIDs {
+		if n := unread[id]; n > 0 {
+			counts[id] = n
+		}
+	}
+	return counts, nil
+}
+
+func (s *MemoryStore) GetUnreadTopics(userID int64, limit int) ([]UnreadTopic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// O tópico com o post não lido mais recente vem primeiro.
+	latest := make(map[int]int)
+	for _, p := range s.posts {
+		if int64(p.UserID) != userID && p.ID > s.reads[readKey{userID, p.TopicID}] {
+			latest[p.TopicID] = max(latest[p.TopicID], p.ID)
+		}
+	}
+
+	var topics []UnreadTopic
+	for topicID, n := range s.unreadLocked(userID) {
+		t, ok := s.topics[topicID]
+		if !ok || !s.forumAllowedLocked(userID, int64(t.ForumID), PermissionView) {
+			continue
+		}
+		topic, ok := s.topicWithAuthor(t)
+		if !ok {
+			continue
+		}
+		var forumName string
+		if f, ok := s.forums[int64(t.ForumID)]; ok {
+			forumName = f.Name
+		}
+		topics = append(topics, UnreadTopic{
+			Topic:          *topic,
+			ForumName:      forumName,
+			Unread:         n,
+			LastReadPostID: s.reads[readKey{userID, topicID}],
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return latest[topics[i].ID] > latest[topics[j].ID] })
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+
+	return topics, nil
+}
+
+// --- Busca ---
+
+// Search faz uma busca simples por substring, sem ranking: os resultados mais
+// recentes vêm primeiro. Serve para testes; o SQLiteStore usa o índice FTS5.
+func (s *MemoryStore) Search(query string, filters SearchFilters) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// match aplica os filtros e monta o resultado a partir do tópico e do autor.
+	match := func(t *Topic, userID int, createdAt time.Time) (SearchResult, bool) {
+		f, ok := s.forums[int64(t.ForumID)]
+		if !ok || (filters.ForumID != 0 && f.ID != filters.ForumID) {
+			return SearchResult{}, false
+		}
+		if !s.forumAllowedLocked(filters.ViewerID, f.ID, PermissionView) {
+			return SearchResult{}, false
+		}
+		u, ok := s.users[int64(userID)]
+		if !ok || (filters.Author != "" && u.Username != filters.Author) {
+			return SearchResult{}, false
+		}
+		if !filters.Since.IsZero() && createdAt.Before(filters.Since) {
+			return SearchResult{}, false
+		}
+		if !filters.Until.IsZero() && !createdAt.Before(filters.Until) {
+			return SearchResult{}, false
+		}
+		return SearchResult{
+			TopicID:    t.ID,
+			ForumID:    f.ID,
+			ForumName:  f.Name,
+			TopicTitle: t.Title,
+			Author:     u.Username,
+			CreatedAt:  createdAt,
+		}, true
+	}
+
+	var results []SearchResult
+	for _, p := range s.posts {
+		snippet, ok := matchSnippet(p.Content, terms)
+		if !ok {
+			continue
+		}
+		t, ok := s.topics[p.TopicID]
+		if !ok || !s.postLiveLocked(p) {
+			continue
+		}
+		if r, ok := match(t, p.UserID, p.CreatedAt); ok {
+			r.PostID = p.ID
+			r.Snippet = snippet
+			results = append(results, r)
+		}
+	}
+	for _, t := range s.topics {
+		snippet, ok := matchSnippet(t.Title, terms)
+		if _, live := s.topicLiveLocked(t.ID); !ok || !live {
+			continue
+		}
+		if r, ok := match(t, t.UserID, t.CreatedAt); ok {
+			r.Snippet = snippet
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// --- Lixeira ---
+
+// trashedLocked informa se o item está na lixeira. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) trashedLocked(kind TrashKind, id int64) bool {
+	_, ok := s.trash[trashKey{kind, id}]
+	return ok
+}
+
+// forumLiveLocked informa se o fórum existe e não está na lixeira. Deve ser chamado com
+// o mutex travado.
+func (s *MemoryStore) forumLiveLocked(id int64) bool {
+	_, ok := s.forums[id]
+	return ok && !s.trashedLocked(TrashForum, id)
+}
+
+// topicLiveLocked retorna o tópico se nem ele nem o seu fórum estiverem na lixeira. Deve
+// ser chamado com o mutex travado.
+func (s *MemoryStore) topicLiveLocked(id int) (*Topic, bool) {
+	t, ok := s.topics[id]
+	if !ok || s.trashedLocked(TrashTopic, int64(id)) || !s.forumLiveLocked(int64(t.ForumID)) {
+		return nil, false
+	}
+	return t, true
+}
+
+// postLiveLocked informa se nem o post, nem o seu tópico, nem o fórum estão na lixeira.
+// Deve ser chamado com o mutex travado.
+func (s *MemoryStore) postLiveLocked(p *Post) bool {
+	if s.trashedLocked(TrashPost, int64(p.ID)) {
+		return false
+	}
+	_, ok := s.topicLiveLocked(p.TopicID)
+	return ok
+}
+
+// moveToTrashLocked marca o item como apagado. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) moveToTrashLocked(kind TrashKind, id, deletedBy int64, reason string) error {
+	k := trashKey{kind, id}
+	if _, ok := s.trash[k]; ok {
+		return fmt.Errorf("%s %d não encontrado", kind.Label(), id)
+	}
+	s.trash[k] = trashEntry{deletedAt: time.Now(), deletedBy: deletedBy, reason: reason}
+	return nil
+}
+
+// existsLocked informa se o item existe, dentro ou fora da lixeira. Deve ser chamado com
+// o mutex travado.
+func (s *MemoryStore) existsLocked(kind TrashKind, id int64) bool {
+	var ok bool
+	switch kind {
+	case TrashForum:
+		_, ok = s.forums[id]
+	case TrashTopic:
+		_, ok = s.topics[int(id)]
+	default:
+		_, ok = s.posts[int(id)]
+	}
+	return ok
+}
+
+func (s *MemoryStore) GetTrash() ([]TrashItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	username := func(id int64) string {
+		if u, ok := s.users[id]; ok {
+			return u.Username
+		}
+		return ""
+	}
+	forumName := func(id int) string {
+		if f, ok := s.forums[int64(id)]; ok {
+			return f.Name
+		}
+		return ""
+	}
+
+	var items []TrashItem
+	for k, e := range s.trash {
+		item := TrashItem{Kind: k.kind, ID: k.id, DeletedAt: e.deletedAt, DeletedBy: username(e.deletedBy), Reason: e.reason}
+		switch k.kind {
+		case TrashForum:
+			f, ok := s.forums[k.id]
+			if !ok {
+				continue
+			}
+			item.Title = f.Name
+		case TrashTopic:
+			t, ok := s.topics[int(k.id)]
+			if !ok || !s.forumLiveLocked(int64(t.ForumID)) {
+				continue
+			}
+			item.Title, item.Forum, item.Author = t.Title, forumName(t.ForumID), username(int64(t.UserID))
+		case TrashPost:
+			p, ok := s.posts[int(k.id)]
+			if !ok {
+				continue
+			}
+			t, ok := s.topicLiveLocked(p.TopicID)
+			if !ok {
+				continue
+			}
+			item.Title, item.Forum, item.Topic, item.Author = p.Content, forumName(t.ForumID), t.Title, username(int64(p.UserID))
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+func (s *MemoryStore) RestoreTrashItem(kind TrashKind, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := trashKey{kind, id}
+	if _, ok := s.trash[k]; !ok || !s.existsLocked(kind, id) {
+		return fmt.Errorf("%s %d não está na lixeira", kind.Label(), id)
+	}
+	if kind == TrashForum {
+		for _, f := range s.forums {
+			if f.ID != id && f.Name == s.forums[id].Name && !s.trashedLocked(TrashForum, f.ID) {
+				return ErrForumNameTaken
+			}
+		}
+	}
+	delete(s.trash, k)
+	return nil
+}
+
+func (s *MemoryStore) PurgeTrashItem(kind TrashKind, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.trashedLocked(kind, id) || !s.existsLocked(kind, id) {
+		return fmt.Errorf("%s %d não está na lixeira", kind.Label(), id)
+	}
+	s.purgeLocked(kind, id)
+	return nil
+}
+
+// purgeLocked remove definitivamente o item. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) purgeLocked(kind TrashKind, id int64) {
+	switch kind {
+	case TrashForum:
+		s.purgeForumLocked(id)
+	case TrashTopic:
+		s.deleteTopicLocked(int(id))
+	default:
+		s.deletePostLocked(int(id))
+	}
+}
+
+func (s *MemoryStore) PurgeTrash(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for _, kind := range []TrashKind{TrashForum, TrashTopic, TrashPost} {
+		for k, e := range s.trash {
+			if k.kind != kind || !e.deletedAt.Before(before) {
+				continue
+			}
+			if s.existsLocked(k.kind, k.id) {
+				s.purgeLocked(k.kind, k.id)
+				purged++
+			} else {
+				delete(s.trash, k)
+			}
+		}
+	}
+	return purged, nil
+}
+
+// --- Mensagens privadas ---
+
+func (s *MemoryStore) SendMessage(senderID int64, recipient, subject, body string) (*Conversation, error) {
+	if err := validateMessage(subject, body); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.userByName(recipient)
+	if r == nil {
+		return nil, fmt.Errorf("usuário '%s' não encontrado", recipient)
+	}
+	if r.ID == senderID {
+		return nil, fmt.Errorf("não é possível enviar mensagens para si mesmo")
+	}
+	if s.blocks[blockKey{r.ID, senderID}] {
+		return nil, ErrBlocked
+	}
+
+	s.lastConversationID++
+	c := &memoryConversation{
+		id:        s.lastConversationID,
+		subject:   subject,
+		createdAt: time.Now(),
+		participants: map[int64]*memoryParticipant{
+			senderID: {},
+			r.ID:     {},
+		},
+	}
+	s.conversations[c.id] = c
+	s.addMessageLocked(c, senderID, body)
+
+	return &Conversation{ID: c.id, Subject: subject, Participants: []string{recipient}}, nil
+}
+
+// addMessageLocked grava a mensagem, marca-a como lida pelo remetente e devolve a
+// conversa a quem a tinha excluído. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) addMessageLocked(c *memoryConversation, senderID int64, body string) Message {
+	s.lastMessageID++
+	m := Message{ID: s.lastMessageID, ConversationID: c.id, SenderID: senderID, Body: body, CreatedAt: time.Now()}
+	s.messages = append(s.messages, m)
+	for id, p := range c.participants {
+		p.deleted = false
+		if id == senderID {
+			p.lastRead = m.ID
+		}
+	}
+	return m
+}
+
+func (s *MemoryStore) ReplyToConversation(senderID, conversationID int64, body string) (*Message, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("a mensagem não pode estar vazia")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.conversations[conversationID]
+	if !ok || c.participants[senderID] == nil {
+		return nil, fmt.Errorf("conversa não encontrada")
+	}
+	for id := range c.participants {
+		if s.blocks[blockKey{id, senderID}] {
+			return nil, ErrBlocked
+		}
+	}
+
+	m := s.addMessageLocked(c, senderID, body)
+	return &m, nil
+}
+
+// lastMessageLocked retorna a mensagem mais recente da conversa. Deve ser chamado com o mutex travado.
+func (s *MemoryStore) lastMessageLocked(conversationID int64) (Message, bool) {
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].ConversationID == conversationID {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// senderNameLocked deve ser chamado com o mutex travado.
+func (s *MemoryStore) senderNameLocked(userID int64) string {
+	if u, ok := s.users[userID]; ok {
+		return u.Username
+	}
+	return removedUsername
+}
+
+func (s *MemoryStore) GetConversations(userID int64) ([]Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var conversations []Conversation
+	lastIDs := make(map[int64]int64)
+	for _, c := range s.conversations {
+		p := c.participants[userID]
+		if p == nil || p.deleted {
+			continue
+		}
+		last, ok := s.lastMessageLocked(c.id)
+		if !ok {
+			continue
+		}
+
+		conv := Conversation{
+			ID:            c.id,
+			Subject:       c.subject,
+			LastSender:    s.senderNameLocked(last.SenderID),
+			LastMessageAt: last.CreatedAt,
+			CreatedAt:     c.createdAt,
+		}
+		for id := range c.participants {
+			if u, ok := s.users[id]; ok && id != userID {
+				conv.Participants = append(conv.Participants, u.Username)
+			}
+		}
+		sort.Strings(conv.Participants)
+		for _, m := range s.messages {
+			if m.ConversationID == c.id && m.ID > p.lastRead && m.SenderID != userID {
+				conv.Unread++
+			}
+		}
+		lastIDs[c.id] = last.ID
+		conversations = append(conversations, conv)
+	}
+	sort.Slice(conversations, func(i, j int) bool { return lastIDs[conversations[i].ID] > lastIDs[conversations[j].ID] })
+
+	return conversations, nil
+}
+
+func (s *MemoryStore) GetConversationMessages(userID, conversationID int64) ([]Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.conversations[conversationID]
+	if !ok || c.participants[userID] == nil {
+		return nil, fmt.Errorf("conversa não encontrada")
+	}
+
+	var messages []Message
+	for _, m := range s.messages {
+		if m.ConversationID == conversationID {
+			m.Sender = s.senderNameLocked(m.SenderID)
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func (s *MemoryStore) MarkConversationRead(userID, conversationID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.conversations[conversationID]; ok {
+		if p := c.participants[userID]; p != nil {
+			last, _ := s.lastMessageLocked(conversationID)
+			p.lastRead = last.ID
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteConversation(userID, conversationID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.conversations[conversationID]; ok {
+		if p := c.participants[userID]; p != nil {
+			last, _ := s.lastMessageLocked(conversationID)
+			p.lastRead = last.ID
+			p.deleted = true
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) CountUnreadMessages(userID int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, m := range s.messages {
+		c, ok := s.conversations[m.ConversationID]
+		if !ok {
+			continue
+		}
+		if p := c.participants[userID]; p != nil && !p.deleted && m.ID > p.lastRead && m.SenderID != userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryStore) BlockUser(userID int64, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.userByName(username)
+	if u == nil {
+		return fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if u.ID == userID {
+		return fmt.Errorf("não é possível bloquear a si mesmo")
+	}
+	s.blocks[blockKey{userID, u.ID}] = true
+	return nil
+}
+
+func (s *MemoryStore) UnblockUser(userID int64, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u := s.userByName(username); u != nil {
+		delete(s.blocks, blockKey{userID, u.ID})
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetBlockedUsers(userID int64) ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []User
+	for k := range s.blocks {
+		if k.userID != userID {
+			continue
+		}
+		if u, ok := s.users[k.blockedID]; ok {
+			users = append(users, u.User)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return users, nil
+}
+
+// --- Chat ---
+
+func (s *MemoryStore) AddChatMessage(channel, username, kind, body string) (*ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastChatID++
+	m := ChatMessage{ID: s.lastChatID, Channel: channel, Username: username, Kind: kind, Body: body, CreatedAt: time.Now()}
+	s.chat = append(s.chat, m)
+	return &m, nil
+}
+
+func (s *MemoryStore) GetChatScrollback(channel string, limit int) ([]ChatMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var messages []ChatMessage
+	for i := len(s.chat) - 1; i >= 0 && len(messages) < limit; i-- {
+		if s.chat[i].Channel == channel {
+			messages = append(messages, s.chat[i])
+		}
+	}
+	// Inverte para a ordem cronológica.
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+func (s *MemoryStore) GetChatChannels() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var channels []string
+	for _, m := range s.chat {
+		if !seen[m.Channel] {
+			seen[m.Channel] = true
+			channels = append(channels, m.Channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels, nil
+}
+
+// --- Falhas de login ---
+
+func (s *MemoryStore) GetLockout(scope, target string) (*Lockout, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.lockouts[lockoutKey{scope, target}]
+	if !ok {
+		return nil, nil
+	}
+	return &l, nil
+}
+
+func (s *MemoryStore) SaveLockout(l *Lockout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *l
+	if l.LockedUntil != nil {
+		until := *l.LockedUntil
+		saved.LockedUntil = &until
+	}
+	s.lockouts[lockoutKey{l.Scope, l.Target}] = saved
+	return nil
+}
+
+func (s *MemoryStore) DeleteLockout(scope, target string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := lockoutKey{scope, target}
+	_, ok := s.lockouts[key]
+	delete(s.lockouts, key)
+	return ok, nil
+}
+
+func (s *MemoryStore) GetLockouts() ([]Lockout, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var lockouts []Lockout
+	for _, l := range s.lockouts {
+		lockouts = append(lockouts, l)
+	}
+	sort.Slice(lockouts, func(i, j int) bool {
+		a, b := lockouts[i], lockouts[j]
+		if !a.LastFailureAt.Equal(b.LastFailureAt) {
+			return a.LastFailureAt.After(b.LastFailureAt)
+		}
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		return a.Target < b.Target
+	})
+	return lockouts, nil
+}
+
+func (s *MemoryStore) PurgeLockouts(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for key, l := range s.lockouts {
+		if l.LastFailureAt.Before(before) && (l.LockedUntil == nil || l.LockedUntil.Before(before)) {
+			delete(s.lockouts, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// --- Convites ---
+
+// inviteByCodeLocked busca um convite pelo código.
+func (s *MemoryStore) inviteByCodeLocked(code string) *Invite {
+	for _, i := range s.invites {
+		if i.Code == code {
+			return i
+		}
+	}
+	return nil
+}
+
+// inviteWithCreatorLocked copia o convite preenchendo o nome de quem o criou.
+func (s *MemoryStore) inviteWithCreatorLocked(i *Invite) Invite {
+	invite := *i
+	if u, ok := s.users[i.CreatedBy]; ok {
+		invite.CreatorName = u.Username
+	}
+	return invite
+}
+
+func (s *MemoryStore) CreateInvite(createdBy int64, maxUses int, expiresAt *time.Time) (*Invite, error) {
+	if err := validateInvite(maxUses, expiresAt); err != nil {
+		return nil, err
+	}
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastInviteID++
+	i := &Invite{ID: s.lastInviteID, Code: code, CreatedBy: createdBy, MaxUses: maxUses, CreatedAt: time.Now()}
+	if expiresAt != nil {
+		expires := *expiresAt
+		i.ExpiresAt = &expires
+	}
+	s.invites = append(s.invites, i)
+
+	invite := s.inviteWithCreatorLocked(i)
+	return &invite, nil
+}
+
+func (s *MemoryStore) GetInvites() ([]Invite, error) {
+	return s.getInvites(func(*Invite) bool { return true }), nil
+}
+
+func (s *MemoryStore) GetInvitesByUser(userID int64) ([]Invite, error) {
+	return s.getInvites(func(i *Invite) bool { return i.CreatedBy == userID }), nil
+}
+
+// getInvites retorna os convites aceitos por match, dos mais novos para os mais antigos.
+func (s *MemoryStore) getInvites(match func(*Invite) bool) []Invite {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var invites []Invite
+	for j := len(s.invites) - 1; j >= 0; j-- {
+		if match(s.invites[j]) {
+			invites = append(invites, s.inviteWithCreatorLocked(s.invites[j]))
+		}
+	}
+	return invites
+}
+
+func (s *MemoryStore) CountOpenInviteUses(userID int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	open := 0
+	for _, i := range s.invites {
+		if i.CreatedBy == userID && i.Active(now) {
+			open += i.MaxUses - i.Uses
+		}
+	}
+	return open, nil
+}
+
+func (s *MemoryStore) RevokeInvite(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code = normalizeInviteCode(code)
+	for j, i := range s.invites {
+		if i.Code == code {
+			s.invites = append(s.invites[:j], s.invites[j+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("convite %s não encontrado", code)
+}
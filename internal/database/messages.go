@@ -0,0 +1,380 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrBlocked indica que o destinatário bloqueou as mensagens do remetente.
+var ErrBlocked = errors.New("o destinatário não aceita mensagens suas")
+
+// removedUsername é exibido no lugar do remetente cuja conta foi removida.
+const removedUsername = "(usuário removido)"
+
+// Conversation é uma conversa privada vista por um dos participantes.
+type Conversation struct {
+	ID            int64
+	Subject       string
+	Participants  []string // Os outros participantes, sem o próprio usuário
+	LastSender    string
+	LastMessageAt time.Time
+	Unread        int // Mensagens dos outros participantes ainda não lidas
+	CreatedAt     time.Time
+}
+
+// Message é uma mensagem de uma conversa privada.
+type Message struct {
+	ID             int64
+	ConversationID int64
+	SenderID       int64
+	Sender         string // Para exibição, obtido com um JOIN
+	Body           string
+	CreatedAt      time.Time
+}
+
+// validateMessage verifica os campos comuns a SendMessage e ReplyToConversation.
+func validateMessage(subject, body string) error {
+	if strings.TrimSpace(subject) == "" {
+		return fmt.Errorf("o assunto não pode estar vazio")
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("a mensagem não pode estar vazia")
+	}
+	return nil
+}
+
+// SendMessage inicia uma nova conversa do remetente com o destinatário.
+func (s *SQLiteStore) SendMessage(senderID int64, recipient, subject, body string) (*Conversation, error) {
+	if err := validateMessage(subject, body); err != nil {
+		return nil, err
+	}
+
+	var recipientID int64
+	err := s.db.QueryRow("SELECT id FROM users WHERE username = ?", recipient).Scan(&recipientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("usuário '%s' não encontrado", recipient)
+		}
+		return nil, fmt.Errorf("falha ao buscar destinatário: %w", err)
+	}
+	if recipientID == senderID {
+		return nil, fmt.Errorf("não é possível enviar mensagens para si mesmo")
+	}
+	if blocked, err := s.isBlocked(recipientID, senderID); err != nil {
+		return nil, err
+	} else if blocked {
+		return nil, ErrBlocked
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO conversations (subject) VALUES (?)", subject)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar conversa: %w", err)
+	}
+	conversationID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter ID da conversa: %w", err)
+	}
+
+	res, err = tx.Exec("INSERT INTO messages (conversation_id, sender_id, body) VALUES (?, ?, ?)", conversationID, senderID, body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gravar mensagem: %w", err)
+	}
+	messageID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter ID da mensagem: %w", err)
+	}
+
+	// O remetente já leu a própria mensagem.
+	_, err = tx.Exec(`
+		INSERT INTO conversation_participants (conversation_id, user_id, last_read_message_id)
+		VALUES (?, ?, ?), (?, ?, 0)
+	`, conversationID, senderID, messageID, conversationID, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao adicionar participantes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+
+	return &Conversation{
+		ID:           conversationID,
+		Subject:      subject,
+		Participants: []string{recipient},
+	}, nil
+}
+
+// ReplyToConversation adiciona uma mensagem a uma conversa da qual o remetente participa.
+// A conversa volta a aparecer para os participantes que a tinham excluído.
+func (s *SQLiteStore) ReplyToConversation(senderID, conversationID int64, body string) (*Message, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("a mensagem não pode estar vazia")
+	}
+
+	others, err := s.otherParticipants(senderID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range others {
+		if blocked, err := s.isBlocked(id, senderID); err != nil {
+			return nil, err
+		} else if blocked {
+			return nil, ErrBlocked
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO messages (conversation_id, sender_id, body) VALUES (?, ?, ?)", conversationID, senderID, body)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gravar mensagem: %w", err)
+	}
+	messageID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter ID da mensagem: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE conversations SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", conversationID); err != nil {
+		return nil, fmt.Errorf("falha ao atualizar conversa: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE conversation_participants SET deleted = 0 WHERE conversation_id = ?", conversationID); err != nil {
+		return nil, fmt.Errorf("falha ao atualizar participantes: %w", err)
+	}
+	_, err = tx.Exec("UPDATE conversation_participants SET last_read_message_id = ? WHERE conversation_id = ? AND user_id = ?", messageID, conversationID, senderID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao atualizar leitura: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+
+	return &Message{ID: messageID, ConversationID: conversationID, SenderID: senderID, Body: body}, nil
+}
+
+// otherParticipants retorna os demais participantes da conversa, ou um erro se o
+// usuário não participar dela.
+func (s *SQLiteStore) otherParticipants(userID, conversationID int64) ([]int64, error) {
+	rows, err := s.db.Query("SELECT user_id FROM conversation_participants WHERE conversation_id = ?", conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar participantes: %w", err)
+	}
+	defer rows.Close()
+
+	var others []int64
+	member := false
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("falha ao escanear participante: %w", err)
+		}
+		if id == userID {
+			member = true
+		} else {
+			others = append(others, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, fmt.Errorf("conversa não encontrada")
+	}
+	return others, nil
+}
+
+// GetConversations retorna as conversas do usuário, da atividade mais recente para a mais antiga.
+func (s *SQLiteStore) GetConversations(userID int64) ([]Conversation, error) {
+	rows, err := s.db.Query(`
+		SELECT c.id, c.subject, c.created_at,
+			(SELECT GROUP_CONCAT(u.username, char(10)) FROM conversation_participants op
+				JOIN users u ON u.id = op.user_id
+				WHERE op.conversation_id = c.id AND op.user_id != cp.user_id),
+			lm.created_at, COALESCE(lu.username, ?),
+			(SELECT COUNT(*) FROM messages m
+				WHERE m.conversation_id = c.id AND m.id > cp.last_read_message_id AND m.sender_id != cp.user_id)
+		FROM conversation_participants cp
+		JOIN conversations c ON c.id = cp.conversation_id
+		JOIN messages lm ON lm.id = (SELECT MAX(id) FROM messages WHERE conversation_id = c.id)
+		LEFT JOIN users lu ON lu.id = lm.sender_id
+		WHERE cp.user_id = ? AND cp.deleted = 0
+		ORDER BY lm.id DESC
+	`, removedUsername, userID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar conversas: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []Conversation
+	for rows.Next() {
+		var c Conversation
+		var participants sql.NullString
+		if err := rows.Scan(&c.ID, &c.Subject, &c.CreatedAt, &participants, &c.LastMessageAt, &c.LastSender, &c.Unread); err != nil {
+			return nil, fmt.Errorf("falha ao escanear conversa: %w", err)
+		}
+		if participants.String != "" {
+			c.Participants = strings.Split(participants.String, "\n")
+		}
+		conversations = append(conversations, c)
+	}
+
+	return conversations, rows.Err()
+}
+
+// GetConversationMessages retorna as mensagens da conversa em ordem cronológica.
+func (s *SQLiteStore) GetConversationMessages(userID, conversationID int64) ([]Message, error) {
+	if _, err := s.otherParticipants(userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT m.id, m.conversation_id, m.sender_id, COALESCE(u.username, ?), m.body, m.created_at
+		FROM messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.conversation_id = ?
+		ORDER BY m.id ASC
+	`, removedUsername, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar mensagens: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Sender, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear mensagem: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// MarkConversationRead marca todas as mensagens da conversa como lidas pelo usuário.
+func (s *SQLiteStore) MarkConversationRead(userID, conversationID int64) error {
+	_, err := s.db.Exec(`
+		UPDATE conversation_participants
+		SET last_read_message_id = COALESCE((SELECT MAX(id) FROM messages WHERE conversation_id = ?), 0)
+		WHERE conversation_id = ? AND user_id = ?
+	`, conversationID, conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("falha ao marcar conversa como lida: %w", err)
+	}
+	return nil
+}
+
+// DeleteConversation remove a conversa da caixa de entrada do usuário. Os outros
+// participantes continuam a vê-la e, se alguém responder, ela reaparece.
+func (s *SQLiteStore) DeleteConversation(userID, conversationID int64) error {
+	_, err := s.db.Exec(`
+		UPDATE conversation_participants
+		SET deleted = 1,
+			last_read_message_id = COALESCE((SELECT MAX(id) FROM messages WHERE conversation_id = ?), 0)
+		WHERE conversation_id = ? AND user_id = ?
+	`, conversationID, conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("falha ao excluir conversa: %w", err)
+	}
+	return nil
+}
+
+// CountUnreadMessages retorna quantas mensagens o usuário ainda não leu.
+func (s *SQLiteStore) CountUnreadMessages(userID int64) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM conversation_participants cp
+		JOIN messages m ON m.conversation_id = cp.conversation_id
+		WHERE cp.user_id = ? AND cp.deleted = 0
+			AND m.id > cp.last_read_message_id AND m.sender_id != cp.user_id
+	`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao contar mensagens não lidas: %w", err)
+	}
+	return count, nil
+}
+
+// isBlocked informa se userID bloqueou as mensagens de senderID.
+func (s *SQLiteStore) isBlocked(userID, senderID int64) (bool, error) {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM user_blocks WHERE user_id = ? AND blocked_user_id = ?", userID, senderID).Scan(&exists)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("falha ao consultar bloqueios: %w", err)
+	}
+	return true, nil
+}
+
+// BlockUser impede que username envie mensagens ao usuário.
+func (s *SQLiteStore) BlockUser(userID int64, username string) error {
+	var blockedID int64
+	err := s.db.QueryRow("SELECT id FROM users WHERE username = ?", username).Scan(&blockedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("usuário '%s' não encontrado", username)
+		}
+		return fmt.Errorf("falha ao buscar usuário: %w", err)
+	}
+	if blockedID == userID {
+		return fmt.Errorf("não é possível bloquear a si mesmo")
+	}
+
+	_, err = s.db.Exec("INSERT OR IGNORE INTO user_blocks (user_id, blocked_user_id) VALUES (?, ?)", userID, blockedID)
+	if err != nil {
+		return fmt.Errorf("falha ao bloquear usuário: %w", err)
+	}
+	return nil
+}
+
+// UnblockUser volta a permitir mensagens de username.
+func (s *SQLiteStore) UnblockUser(userID int64, username string) error {
+	_, err := s.db.Exec(`
+		DELETE FROM user_blocks
+		WHERE user_id = ? AND blocked_user_id = (SELECT id FROM users WHERE username = ?)
+	`, userID, username)
+	if err != nil {
+		return fmt.Errorf("falha ao desbloquear usuário: %w", err)
+	}
+	return nil
+}
+
+// GetBlockedUsers retorna os usuários bloqueados pelo usuário, em ordem alfabética.
+func (s *SQLiteStore) GetBlockedUsers(userID int64) ([]User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.role, u.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_user_id
+		WHERE b.user_id = ?
+		ORDER BY u.username
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar usuários bloqueados: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear usuário: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
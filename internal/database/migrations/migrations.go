@@ -0,0 +1,381 @@
+// Package migrations aplica as alterações versionadas do esquema do banco de dados.
+//
+// Cada migração é um par de arquivos embutidos em sql/, no formato
+// NNNN_nome.up.sql e NNNN_nome.down.sql. As versões aplicadas ficam registradas
+// na tabela schema_migrations.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// ErrDirty indica que uma migração foi interrompida no meio e o esquema
+// precisa ser verificado manualmente antes de continuar.
+var ErrDirty = errors.New("o banco de dados está em estado inconsistente (migração interrompida)")
+
+// Migration é uma alteração versionada do esquema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus descreve a situação de uma migração em um banco de dados.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// appliedMigration é uma linha da tabela schema_migrations.
+type appliedMigration struct {
+	version   int
+	name      string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// Load retorna as migrações embutidas, ordenadas pela versão.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar migrações: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("arquivo de migração inesperado: %s", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionStr, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("nome de migração inválido: %s", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("versão de migração inválida em %s: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(files, "sql/"+name)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler migração %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migração %d possui nomes divergentes: %s e %s", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migração %04d_%s não possui arquivo .up.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up aplica todas as migrações pendentes, em ordem. Retorna quantas foram aplicadas.
+func Up(db *sql.DB) (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureTable(db); err != nil {
+		return 0, err
+	}
+	applied, err := loadApplied(db)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkApplied(migrations, applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := run(db, m, m.Up, "up"); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverte as últimas `steps` migrações aplicadas. Retorna quantas foram revertidas.
+func Down(db *sql.DB, steps int) (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureTable(db); err != nil {
+		return 0, err
+	}
+	applied, err := loadApplied(db)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkApplied(migrations, applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(migrations) - 1; i >= 0 && count < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return count, fmt.Errorf("migração %04d_%s não pode ser revertida (sem arquivo .down.sql)", m.Version, m.Name)
+		}
+		if err := run(db, m, m.Down, "down"); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status lista todas as migrações conhecidas e se estão aplicadas. Versões aplicadas
+// que este binário não conhece também são listadas, com o nome registrado no banco.
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := loadApplied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	known := make(map[int]bool)
+	for _, m := range migrations {
+		known[m.Version] = true
+		st := MigrationStatus{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			st.Applied = true
+			st.Dirty = a.dirty
+			st.AppliedAt = &a.appliedAt
+		}
+		statuses = append(statuses, st)
+	}
+	for _, a := range applied {
+		if !known[a.version] {
+			appliedAt := a.appliedAt
+			statuses = append(statuses, MigrationStatus{Version: a.version, Name: a.name, Applied: true, Dirty: a.dirty, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Force marca uma migração como aplicada (ou não) sem executá-la, limpando o estado
+// inconsistente. Deve ser usado apenas após conferir o esquema manualmente.
+func Force(db *sql.DB, version int, applied bool) error {
+	migrations, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := ensureTable(db); err != nil {
+		return err
+	}
+
+	var target *Migration
+	for i := range migrations {
+		if migrations[i].Version == version {
+			target = &migrations[i]
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("migração %d desconhecida", version)
+	}
+
+	if applied {
+		_, err = db.Exec(`
+			INSERT INTO schema_migrations (version, name, dirty) VALUES (?, ?, 0)
+			ON CONFLICT(version) DO UPDATE SET dirty = 0
+		`, target.Version, target.Name)
+	} else {
+		_, err = db.Exec("DELETE FROM schema_migrations WHERE version = ?", target.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao forçar a migração %d: %w", version, err)
+	}
+
+	return nil
+}
+
+func ensureTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			dirty INTEGER NOT NULL DEFAULT 0,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("falha ao criar a tabela schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func loadApplied(db *sql.DB) (map[int]appliedMigration, error) {
+	rows, err := db.Query("SELECT version, name, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar migrações aplicadas: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.dirty, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear migração aplicada: %w", err)
+		}
+		applied[a.version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+func checkApplied(migrations []Migration, applied map[int]appliedMigration) error {
+	known := make(map[int]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = true
+	}
+
+	for _, a := range applied {
+		if a.dirty {
+			return fmt.Errorf("%w: versão %04d_%s; confira o esquema e use 'bbs-admin migrate force'", ErrDirty, a.version, a.name)
+		}
+		if !known[a.version] {
+			return fmt.Errorf("o banco possui a migração %04d_%s, desconhecida por esta versão do BBS", a.version, a.name)
+		}
+	}
+
+	return nil
+}
+
+// run executa uma migração em uma transação. Antes de começar, a versão é marcada
+// como suja fora da transação; se o processo morrer no meio, a marca permanece e
+// impede que o servidor suba sobre um esquema incompleto.
+//
+// As chaves estrangeiras ficam desligadas durante a migração, como o SQLite recomenda
+// para alterações de esquema: recriar uma tabela (DROP TABLE seguido de RENAME) apagaria
+// em cascata as linhas que a referenciam. A migração deve manter as referências válidas.
+func run(db *sql.DB, m Migration, script, direction string) error {
+	if _, err := db.Exec(`
+		INSERT INTO schema_migrations (version, name, dirty) VALUES (?, ?, 1)
+		ON CONFLICT(version) DO UPDATE SET dirty = 1
+	`, m.Version, m.Name); err != nil {
+		return fmt.Errorf("falha ao marcar a migração %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	// clearMark desfaz a marcação quando a transação é revertida sem alterar o esquema.
+	clearMark := func() {
+		if direction == "up" {
+			db.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+		} else {
+			db.Exec("UPDATE schema_migrations SET dirty = 0 WHERE version = ?", m.Version)
+		}
+	}
+
+	// O PRAGMA vale só para a conexão e não tem efeito dentro de uma transação, então a
+	// migração usa uma conexão própria, desligado antes do BEGIN.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		clearMark()
+		return fmt.Errorf("falha ao obter conexão: %w", err)
+	}
+	defer conn.Close()
+	var foreignKeys bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		clearMark()
+		return fmt.Errorf("falha ao consultar as chaves estrangeiras: %w", err)
+	}
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			clearMark()
+			return fmt.Errorf("falha ao desligar as chaves estrangeiras: %w", err)
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		clearMark()
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		tx.Rollback()
+		clearMark()
+		return fmt.Errorf("falha ao aplicar a migração %04d_%s (%s): %w", m.Version, m.Name, direction, err)
+	}
+
+	if direction == "up" {
+		_, err = tx.Exec("UPDATE schema_migrations SET dirty = 0, applied_at = CURRENT_TIMESTAMP WHERE version = ?", m.Version)
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		clearMark()
+		return fmt.Errorf("falha ao registrar a migração %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		clearMark()
+		return fmt.Errorf("falha ao confirmar a migração %04d_%s: %w", m.Version, m.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // Driver do SQLite
+)
+
+// openTestDB abre um banco vazio em um arquivo temporário. Um banco :memory: não serve:
+// cada conexão do pool teria o seu, e run usa uma conexão própria.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "bbs.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func appliedCount(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	statuses, err := Status(db)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	n := 0
+	for _, st := range statuses {
+		if st.Applied {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migração %d tem a versão %d; as versões devem ser contínuas", i+1, m.Version)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migração %04d_%s sem os dois arquivos", m.Version, m.Name)
+		}
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	db := openTestDB(t)
+	migrations, _ := Load()
+
+	n, err := Up(db)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != len(migrations) {
+		t.Errorf("Up aplicou %d migrações, esperado %d", n, len(migrations))
+	}
+	if n, err := Up(db); err != nil || n != 0 {
+		t.Errorf("Up sem pendências = %d, %v, esperado 0", n, err)
+	}
+
+	if n, err := Down(db, 2); err != nil || n != 2 {
+		t.Fatalf("Down(2) = %d, %v", n, err)
+	}
+	if got := appliedCount(t, db); got != len(migrations)-2 {
+		t.Errorf("aplicadas após Down(2) = %d, esperado %d", got, len(migrations)-2)
+	}
+	if n, err := Up(db); err != nil || n != 2 {
+		t.Errorf("Up após Down(2) = %d, %v, esperado 2", n, err)
+	}
+
+	// Todas as migrações podem ser revertidas e reaplicadas.
+	if n, err := Down(db, len(migrations)); err != nil || n != len(migrations) {
+		t.Fatalf("Down de todas = %d, %v", n, err)
+	}
+	if n, err := Up(db); err != nil || n != len(migrations) {
+		t.Errorf("Up após reverter todas = %d, %v", n, err)
+	}
+}
+
+func TestDirtyMarker(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	migrations, _ := Load()
+	last := migrations[len(migrations)-1]
+
+	// Simula um processo que morreu no meio da migração.
+	if _, err := db.Exec("UPDATE schema_migrations SET dirty = 1 WHERE version = ?", last.Version); err != nil {
+		t.Fatalf("falha ao marcar a migração: %v", err)
+	}
+	if _, err := Up(db); !errors.Is(err, ErrDirty) {
+		t.Errorf("Up com migração suja = %v, esperado ErrDirty", err)
+	}
+	if _, err := Down(db, 1); !errors.Is(err, ErrDirty) {
+		t.Errorf("Down com migração suja = %v, esperado ErrDirty", err)
+	}
+	statuses, _ := Status(db)
+	if st := statuses[len(statuses)-1]; !st.Dirty || !st.Applied {
+		t.Errorf("Status = %+v, esperado aplicada e suja", st)
+	}
+
+	if err := Force(db, last.Version, true); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	if _, err := Up(db); err != nil {
+		t.Errorf("Up após Force = %v", err)
+	}
+	if err := Force(db, 9999, true); err == nil {
+		t.Error("Force aceitou uma versão desconhecida")
+	}
+}
+
+func TestFailedMigrationClearsMarker(t *testing.T) {
+	db := openTestDB(t)
+	if err := ensureTable(db); err != nil {
+		t.Fatalf("ensureTable: %v", err)
+	}
+
+	// A transação é revertida, então a marca não deve sobrar para travar o banco.
+	m := Migration{Version: 1, Name: "falha", Up: "CREATE TABLE ok (id INTEGER); CREATE TABLE"}
+	if err := run(db, m, m.Up, "up"); err == nil {
+		t.Fatal("run aceitou uma migração inválida")
+	}
+	var rows int
+	db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&rows)
+	if rows != 0 {
+		t.Errorf("schema_migrations tem %d linha(s) após a falha, esperado 0", rows)
+	}
+	var tables int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'ok'").Scan(&tables)
+	if tables != 0 {
+		t.Error("a parte da migração anterior ao erro não foi revertida")
+	}
+}
+
+func TestUnknownAppliedVersion(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, name) VALUES (9999, 'futura')"); err != nil {
+		t.Fatalf("falha ao registrar a migração: %v", err)
+	}
+	if _, err := Up(db); err == nil {
+		t.Error("Up aceitou um banco com uma migração desconhecida")
+	}
+	statuses, err := Status(db)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st := statuses[len(statuses)-1]; st.Version != 9999 || st.Name != "futura" || !st.Applied {
+		t.Errorf("Status não listou a migração desconhecida: %+v", st)
+	}
+}
+
+func TestMigrationsKeepForeignKeys(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	// A recriação de forums pela 0016 não pode apagar em cascata nem deixar referências quebradas.
+	if _, err := db.Exec(`INSERT INTO users (username, password_hash) VALUES ('u', '');
+		INSERT INTO forums (name) VALUES ('Geral');
+		INSERT INTO forum_moderators (forum_id, user_id) VALUES (1, 1)`); err != nil {
+		t.Fatalf("falha ao popular o banco: %v", err)
+	}
+	if _, err := Down(db, 2); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	var mods int
+	db.QueryRow("SELECT COUNT(*) FROM forum_moderators").Scan(&mods)
+	if mods != 1 {
+		t.Errorf("forum_moderators tem %d linha(s) após recriar forums, esperado 1", mods)
+	}
+	rows, err := db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		t.Fatalf("foreign_key_check: %v", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Error("foreign_key_check encontrou referências quebradas")
+	}
+}
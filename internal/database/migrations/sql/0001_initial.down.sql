@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_auth;
+DROP TABLE IF EXISTS user_keys;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS topics;
+DROP TABLE IF EXISTS forums;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS topics_fts_update;
+DROP TRIGGER IF EXISTS topics_fts_delete;
+DROP TRIGGER IF EXISTS topics_fts_insert;
+DROP TRIGGER IF EXISTS posts_fts_update;
+DROP TRIGGER IF EXISTS posts_fts_delete;
+DROP TRIGGER IF EXISTS posts_fts_insert;
+DROP TABLE IF EXISTS topics_fts;
+DROP TABLE IF EXISTS posts_fts;
@@ -0,0 +1,46 @@
+-- Índices de busca textual (FTS5) sobre posts e títulos de tópicos.
+-- As tabelas usam "external content": o texto fica apenas em posts/topics e
+-- os gatilhos abaixo mantêm os índices sincronizados.
+CREATE VIRTUAL TABLE posts_fts USING fts5(
+	content,
+	content = 'posts',
+	content_rowid = 'id',
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE VIRTUAL TABLE topics_fts USING fts5(
+	title,
+	content = 'topics',
+	content_rowid = 'id',
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+	INSERT INTO posts_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+	INSERT INTO posts_fts(posts_fts, rowid, content) VALUES ('delete', old.id, old.content);
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF content ON posts BEGIN
+	INSERT INTO posts_fts(posts_fts, rowid, content) VALUES ('delete', old.id, old.content);
+	INSERT INTO posts_fts(rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER topics_fts_insert AFTER INSERT ON topics BEGIN
+	INSERT INTO topics_fts(rowid, title) VALUES (new.id, new.title);
+END;
+
+CREATE TRIGGER topics_fts_delete AFTER DELETE ON topics BEGIN
+	INSERT INTO topics_fts(topics_fts, rowid, title) VALUES ('delete', old.id, old.title);
+END;
+
+CREATE TRIGGER topics_fts_update AFTER UPDATE OF title ON topics BEGIN
+	INSERT INTO topics_fts(topics_fts, rowid, title) VALUES ('delete', old.id, old.title);
+	INSERT INTO topics_fts(rowid, title) VALUES (new.id, new.title);
+END;
+
+-- Indexa o conteúdo que já existia antes desta migração.
+INSERT INTO posts_fts(posts_fts) VALUES ('rebuild');
+INSERT INTO topics_fts(topics_fts) VALUES ('rebuild');
@@ -0,0 +1,2 @@
+DROP INDEX idx_posts_topic_created;
+DROP INDEX idx_topics_forum_created;
@@ -0,0 +1,3 @@
+-- Índices que cobrem a ordenação usada pela paginação por chave (created_at, id).
+CREATE INDEX idx_topics_forum_created ON topics(forum_id, created_at DESC, id DESC);
+CREATE INDEX idx_posts_topic_created ON posts(topic_id, created_at, id);
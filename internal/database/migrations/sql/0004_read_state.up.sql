@@ -0,0 +1,12 @@
+-- Último post lido por cada usuário em cada tópico.
+CREATE TABLE read_state (
+	user_id INTEGER NOT NULL,
+	topic_id INTEGER NOT NULL,
+	last_read_post_id INTEGER NOT NULL DEFAULT 0,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, topic_id),
+	FOREIGN KEY(user_id) REFERENCES users(id),
+	FOREIGN KEY(topic_id) REFERENCES topics(id)
+);
+
+CREATE INDEX idx_read_state_topic ON read_state(topic_id);
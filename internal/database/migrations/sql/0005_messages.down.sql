@@ -0,0 +1,4 @@
+DROP TABLE user_blocks;
+DROP TABLE messages;
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
@@ -0,0 +1,42 @@
+-- Mensagens privadas: cada conversa tem participantes e mensagens. O estado de
+-- leitura e a exclusão são guardados por participante.
+CREATE TABLE conversations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	subject TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE conversation_participants (
+	conversation_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	last_read_message_id INTEGER NOT NULL DEFAULT 0,
+	deleted INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (conversation_id, user_id),
+	FOREIGN KEY(conversation_id) REFERENCES conversations(id),
+	FOREIGN KEY(user_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_conversation_participants_user ON conversation_participants(user_id);
+
+CREATE TABLE messages (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	conversation_id INTEGER NOT NULL,
+	sender_id INTEGER NOT NULL,
+	body TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(conversation_id) REFERENCES conversations(id),
+	FOREIGN KEY(sender_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_messages_conversation ON messages(conversation_id, id);
+
+-- Remetentes bloqueados: blocked_user_id não pode enviar mensagens a user_id.
+CREATE TABLE user_blocks (
+	user_id INTEGER NOT NULL,
+	blocked_user_id INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, blocked_user_id),
+	FOREIGN KEY(user_id) REFERENCES users(id),
+	FOREIGN KEY(blocked_user_id) REFERENCES users(id)
+);
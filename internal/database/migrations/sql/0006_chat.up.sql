@@ -0,0 +1,12 @@
+-- Histórico das salas de chat. O autor é guardado pelo nome para que o histórico
+-- continue legível mesmo depois que a conta for removida.
+CREATE TABLE chat_messages (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	channel TEXT NOT NULL,
+	username TEXT NOT NULL,
+	kind TEXT NOT NULL DEFAULT 'message',
+	body TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_chat_messages_channel ON chat_messages(channel, id);
@@ -0,0 +1,11 @@
+-- Falhas de login recentes por endereço IP e por nome de usuário. Enquanto locked_until
+-- estiver no futuro, as tentativas são recusadas antes de qualquer verificação de senha.
+-- O nome não referencia users: nomes inexistentes também são contados.
+CREATE TABLE login_lockouts (
+	scope TEXT NOT NULL,
+	target TEXT NOT NULL,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_failure_at DATETIME NOT NULL,
+	locked_until DATETIME,
+	PRIMARY KEY (scope, target)
+);
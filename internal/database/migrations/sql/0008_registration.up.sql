@@ -0,0 +1,4 @@
+-- Cadastro pelo próprio usuário: o e-mail é opcional, e as contas criadas sob a política
+-- de aprovação ficam com o status 'pending' até que um administrador as aprove.
+ALTER TABLE users ADD COLUMN email TEXT;
+ALTER TABLE users ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
@@ -0,0 +1,17 @@
+-- Convites para o cadastro de novas contas. created_by é NULL nos convites criados pelo
+-- bbs-admin; expires_at é NULL nos convites sem prazo.
+CREATE TABLE invites (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	code TEXT NOT NULL UNIQUE,
+	created_by INTEGER,
+	max_uses INTEGER NOT NULL DEFAULT 1,
+	uses INTEGER NOT NULL DEFAULT 0,
+	expires_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_invites_created_by ON invites(created_by);
+
+-- Quem convidou cada conta cadastrada com um convite.
+ALTER TABLE users ADD COLUMN invited_by INTEGER REFERENCES users(id);
@@ -0,0 +1,3 @@
+DROP TABLE recovery_codes;
+ALTER TABLE user_auth DROP COLUMN totp_last_step;
+ALTER TABLE user_auth DROP COLUMN totp_secret;
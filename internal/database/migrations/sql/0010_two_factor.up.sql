@@ -0,0 +1,15 @@
+-- Verificação em duas etapas: o segredo TOTP fica com as demais preferências de
+-- autenticação, junto com o último intervalo aceito, que impede reutilizar uma senha.
+ALTER TABLE user_auth ADD COLUMN totp_secret TEXT;
+ALTER TABLE user_auth ADD COLUMN totp_last_step INTEGER NOT NULL DEFAULT 0;
+
+-- Códigos de recuperação, guardados apenas como hash SHA-256. Cada um vale para um login.
+CREATE TABLE recovery_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash TEXT NOT NULL,
+	used_at DATETIME,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes(user_id);
@@ -0,0 +1 @@
+ALTER TABLE forums DROP COLUMN guest_access;
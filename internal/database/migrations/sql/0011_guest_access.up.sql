@@ -0,0 +1,2 @@
+-- Fóruns abertos aos visitantes, que entram sem conta e só podem ler.
+ALTER TABLE forums ADD COLUMN guest_access INTEGER NOT NULL DEFAULT 0;
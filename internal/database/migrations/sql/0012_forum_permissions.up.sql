@@ -0,0 +1,15 @@
+-- Permissões por fórum (view, topic, reply, moderate), concedidas a um papel (role) ou a
+-- um usuário (user_id); o outro campo fica vazio. Uma permissão sem concessões no fórum
+-- segue o padrão do papel.
+CREATE TABLE forum_permissions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	forum_id INTEGER NOT NULL,
+	permission TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT '',
+	user_id INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(forum_id) REFERENCES forums(id) ON DELETE CASCADE,
+	UNIQUE(forum_id, permission, role, user_id)
+);
+
+CREATE INDEX idx_forum_permissions_user_id ON forum_permissions(user_id);
@@ -0,0 +1 @@
+DROP TABLE forum_moderators;
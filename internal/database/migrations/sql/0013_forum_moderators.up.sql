@@ -0,0 +1,11 @@
+-- Moderadores de fóruns específicos. Quem modera um fórum tem nele todas as permissões,
+-- independentemente do papel e das concessões em forum_permissions.
+CREATE TABLE forum_moderators (
+	forum_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (forum_id, user_id),
+	FOREIGN KEY(forum_id) REFERENCES forums(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_forum_moderators_user_id ON forum_moderators(user_id);
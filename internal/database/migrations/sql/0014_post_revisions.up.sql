@@ -0,0 +1,14 @@
+-- Edição de posts. Cada edição guarda em post_revisions o conteúdo anterior, quem editou
+-- e quando; posts.edited_at marca a última edição.
+ALTER TABLE posts ADD COLUMN edited_at DATETIME;
+
+CREATE TABLE post_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	post_id INTEGER NOT NULL,
+	content TEXT NOT NULL,
+	edited_by INTEGER NOT NULL,
+	edited_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE,
+	FOREIGN KEY(edited_by) REFERENCES users(id)
+);
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id, id);
@@ -0,0 +1,15 @@
+DROP INDEX idx_posts_deleted_at;
+DROP INDEX idx_topics_deleted_at;
+DROP INDEX idx_forums_deleted_at;
+
+ALTER TABLE posts DROP COLUMN delete_reason;
+ALTER TABLE posts DROP COLUMN deleted_by;
+ALTER TABLE posts DROP COLUMN deleted_at;
+
+ALTER TABLE topics DROP COLUMN delete_reason;
+ALTER TABLE topics DROP COLUMN deleted_by;
+ALTER TABLE topics DROP COLUMN deleted_at;
+
+ALTER TABLE forums DROP COLUMN delete_reason;
+ALTER TABLE forums DROP COLUMN deleted_by;
+ALTER TABLE forums DROP COLUMN deleted_at;
@@ -0,0 +1,18 @@
+-- Exclusão reversível: fóruns, tópicos e posts apagados vão para a lixeira, com quem os
+-- apagou, quando e por quê, e só saem do banco ao serem removidos definitivamente.
+ALTER TABLE forums ADD COLUMN deleted_at DATETIME;
+ALTER TABLE forums ADD COLUMN deleted_by INTEGER;
+ALTER TABLE forums ADD COLUMN delete_reason TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE topics ADD COLUMN deleted_at DATETIME;
+ALTER TABLE topics ADD COLUMN deleted_by INTEGER;
+ALTER TABLE topics ADD COLUMN delete_reason TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE posts ADD COLUMN deleted_at DATETIME;
+ALTER TABLE posts ADD COLUMN deleted_by INTEGER;
+ALTER TABLE posts ADD COLUMN delete_reason TEXT NOT NULL DEFAULT '';
+
+-- A lixeira e a remoção por idade consultam apenas os itens apagados.
+CREATE INDEX idx_forums_deleted_at ON forums(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_topics_deleted_at ON topics(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_posts_deleted_at ON posts(deleted_at) WHERE deleted_at IS NOT NULL;
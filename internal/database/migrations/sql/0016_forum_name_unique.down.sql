@@ -0,0 +1,19 @@
+-- Falha se um fórum da lixeira tiver o mesmo nome de outro fórum.
+CREATE TABLE forums_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	guest_access INTEGER NOT NULL DEFAULT 0,
+	deleted_at DATETIME,
+	deleted_by INTEGER,
+	delete_reason TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO forums_old (id, name, description, created_at, guest_access, deleted_at, deleted_by, delete_reason)
+SELECT id, name, description, created_at, guest_access, deleted_at, deleted_by, delete_reason FROM forums;
+
+DROP TABLE forums;
+ALTER TABLE forums_old RENAME TO forums;
+
+CREATE INDEX idx_forums_deleted_at ON forums(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,21 @@
+-- O nome de um fórum só precisa ser único entre os fóruns fora da lixeira. O UNIQUE da
+-- coluna não pode ser removido com ALTER TABLE, então a tabela é recriada.
+CREATE TABLE forums_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	description TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	guest_access INTEGER NOT NULL DEFAULT 0,
+	deleted_at DATETIME,
+	deleted_by INTEGER,
+	delete_reason TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO forums_new (id, name, description, created_at, guest_access, deleted_at, deleted_by, delete_reason)
+SELECT id, name, description, created_at, guest_access, deleted_at, deleted_by, delete_reason FROM forums;
+
+DROP TABLE forums;
+ALTER TABLE forums_new RENAME TO forums;
+
+CREATE INDEX idx_forums_deleted_at ON forums(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_forums_name ON forums(name) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- O cadastro recusa nomes reservados sem diferenciar maiúsculas de minúsculas; os nomes
+-- de usuário seguem a mesma regra. Falha se já houver dois nomes que só diferem na caixa.
+CREATE UNIQUE INDEX idx_users_username_nocase ON users(username COLLATE NOCASE);
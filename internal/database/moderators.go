@@ -0,0 +1,69 @@
+package database
+
+import "fmt"
+
+// AddForumModerator torna o usuário moderador do fórum, com todas as permissões nele.
+// Adicionar de novo um moderador existente não tem efeito.
+func (s *SQLiteStore) AddForumModerator(forumID int64, username string) error {
+	userID, err := s.userIDByName(username)
+	if err != nil {
+		return err
+	}
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM forums WHERE id = ?)", forumID).Scan(&exists); err != nil {
+		return fmt.Errorf("falha ao buscar fórum: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("fórum %d não encontrado", forumID)
+	}
+
+	_, err = s.db.Exec("INSERT INTO forum_moderators (forum_id, user_id) VALUES (?, ?) ON CONFLICT(forum_id, user_id) DO NOTHING", forumID, userID)
+	if err != nil {
+		return fmt.Errorf("falha ao adicionar moderador: %w", err)
+	}
+	return nil
+}
+
+// RemoveForumModerator retira o usuário da moderação do fórum.
+func (s *SQLiteStore) RemoveForumModerator(forumID int64, username string) error {
+	res, err := s.db.Exec(`
+		DELETE FROM forum_moderators
+		WHERE forum_id = ? AND user_id = (SELECT id FROM users WHERE username = ?)
+	`, forumID, username)
+	if err != nil {
+		return fmt.Errorf("falha ao remover moderador: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("'%s' não modera o fórum %d", username, forumID)
+	}
+	return nil
+}
+
+// GetForumModerators lista os moderadores do fórum em ordem alfabética.
+func (s *SQLiteStore) GetForumModerators(forumID int64) ([]User, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.username, u.role
+		FROM forum_moderators m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.forum_id = ?
+		ORDER BY u.username
+	`, forumID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar moderadores: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Role); err != nil {
+			return nil, fmt.Errorf("falha ao escanear moderador: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
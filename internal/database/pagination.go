@@ -0,0 +1,27 @@
+package database
+
+import "time"
+
+// PageCursor marca a posição do último item de uma página. As listas paginadas
+// usam a chave (created_at, id) em vez de OFFSET, para que páginas distantes
+// custem o mesmo que a primeira e inserções concorrentes não dupliquem itens.
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// sqliteTime formata um instante como o CURRENT_TIMESTAMP do SQLite grava, para
+// que comparações com created_at funcionem como comparação de texto.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// Cursor retorna a posição do tópico para buscar a página seguinte.
+func (t *Topic) Cursor() *PageCursor {
+	return &PageCursor{CreatedAt: t.CreatedAt, ID: t.ID}
+}
+
+// Cursor retorna a posição do post para buscar a página seguinte.
+func (p *Post) Cursor() *PageCursor {
+	return &PageCursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
@@ -0,0 +1,260 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ForumPermission é uma ação que pode ser concedida em um fórum.
+type ForumPermission string
+
+const (
+	PermissionView     ForumPermission = "view"     // Ver o fórum, seus tópicos e posts
+	PermissionTopic    ForumPermission = "topic"    // Criar tópicos
+	PermissionReply    ForumPermission = "reply"    // Responder aos tópicos
+	PermissionModerate ForumPermission = "moderate" // Apagar tópicos e posts
+)
+
+// ForumPermissions lista as permissões na ordem em que são exibidas.
+var ForumPermissions = []ForumPermission{PermissionView, PermissionTopic, PermissionReply, PermissionModerate}
+
+// GuestRole é o papel dos visitantes, que entram sem conta. Nas verificações de
+// permissão, o usuário de ID 0 é um visitante: ele só vê os fóruns abertos aos visitantes.
+const GuestRole = "guest"
+
+// ErrForumPermission é retornado quando o usuário não tem a permissão exigida no fórum.
+var ErrForumPermission = errors.New("você não tem permissão para esta ação neste fórum")
+
+// ParseForumPermission converte o nome de uma permissão (view, topic, reply ou moderate).
+func ParseForumPermission(name string) (ForumPermission, error) {
+	for _, p := range ForumPermissions {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("permissão inválida: %q (use view, topic, reply ou moderate)", name)
+}
+
+// Label retorna a descrição da permissão exibida aos usuários.
+func (p ForumPermission) Label() string {
+	switch p {
+	case PermissionView:
+		return "ver"
+	case PermissionTopic:
+		return "criar tópicos"
+	case PermissionReply:
+		return "responder"
+	case PermissionModerate:
+		return "moderar"
+	}
+	return string(p)
+}
+
+// defaultFor informa se o papel tem a permissão nos fóruns sem concessões para ela. O
+// padrão mantém as regras de antes das permissões por fórum: todos veem e respondem, e
+// só moderadores e administradores criam tópicos e moderam.
+func (p ForumPermission) defaultFor(role string) bool {
+	switch p {
+	case PermissionView, PermissionReply:
+		return role == "user" || role == "moderator" || role == "admin"
+	default:
+		return role == "moderator" || role == "admin"
+	}
+}
+
+// ForumGrant concede uma permissão em um fórum a um papel ou a um usuário.
+type ForumGrant struct {
+	ForumID    int64
+	Permission ForumPermission
+	Role       string // Papel que recebe a permissão; vazio se ela for de um usuário
+	UserID     int64
+	Username   string
+	CreatedAt  time.Time
+}
+
+// Subject descreve quem recebe a permissão.
+func (g ForumGrant) Subject() string {
+	if g.Role != "" {
+		return "papel " + g.Role
+	}
+	return "usuário " + g.Username
+}
+
+// forumAccessFilter monta a condição SQL que limita forumExpr aos fóruns em que o usuário,
+// com o papel informado, tem a permissão. Toda permissão exige também a de ver o fórum.
+// Quem modera o fórum tem todas as permissões nele.
+// Os administradores têm todas as permissões; os visitantes só veem os fóruns abertos a
+// eles, e quem não tem papel (um usuário que não existe) não tem nenhuma.
+func forumAccessFilter(forumExpr string, perm ForumPermission, userID int64, role string) (string, []any) {
+	switch role {
+	case "admin":
+		return "1", nil
+	case "":
+		return "0", nil
+	case GuestRole:
+		if perm != PermissionView {
+			return "0", nil
+		}
+		return "EXISTS (SELECT 1 FROM forums g WHERE g.id = " + forumExpr + " AND g.guest_access = 1)", nil
+	}
+	cond, args := permissionFilter(forumExpr, perm, userID, role)
+	if perm != PermissionView {
+		view, viewArgs := permissionFilter(forumExpr, PermissionView, userID, role)
+		cond = view + " AND " + cond
+		args = append(viewArgs, args...)
+	}
+	return "(" + cond + ")", args
+}
+
+// permissionFilter monta a condição de uma única permissão. Os moderadores do fórum têm
+// todas as permissões nele.
+func permissionFilter(forumExpr string, perm ForumPermission, userID int64, role string) (string, []any) {
+	granted := `(EXISTS (SELECT 1 FROM forum_permissions a WHERE a.forum_id = ` + forumExpr + `
+		AND a.permission = ? AND (a.user_id = ? OR a.role = ?))
+		OR EXISTS (SELECT 1 FROM forum_moderators m WHERE m.forum_id = ` + forumExpr + ` AND m.user_id = ?))`
+	args := []any{perm, userID, role, userID}
+	if !perm.defaultFor(role) {
+		return granted, args
+	}
+	return `(NOT EXISTS (SELECT 1 FROM forum_permissions a WHERE a.forum_id = ` + forumExpr + ` AND a.permission = ?)
+		OR ` + granted + `)`, append([]any{perm}, args...)
+}
+
+// userRole retorna o papel do usuário, GuestRole para o ID 0, ou "" se ele não existir.
+func (s *SQLiteStore) userRole(userID int64) (string, error) {
+	if userID == 0 {
+		return GuestRole, nil
+	}
+	var role string
+	err := s.db.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("falha ao buscar papel do usuário: %w", err)
+	}
+	return role, nil
+}
+
+// grantSubject valida quem recebe a permissão: um papel ou um usuário, nunca os dois.
+func (s *SQLiteStore) grantSubject(role, username string) (string, int64, error) {
+	if (role == "") == (username == "") {
+		return "", 0, fmt.Errorf("informe um papel ou um usuário, não os dois")
+	}
+	if role != "" {
+		return role, 0, validateRole(role)
+	}
+	userID, err := s.userIDByName(username)
+	return "", userID, err
+}
+
+// GrantForumPermission concede a permissão no fórum a um papel ou a um usuário. Conceder
+// de novo uma permissão existente não tem efeito.
+func (s *SQLiteStore) GrantForumPermission(forumID int64, perm ForumPermission, role, username string) error {
+	role, userID, err := s.grantSubject(role, username)
+	if err != nil {
+		return err
+	}
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM forums WHERE id = ?)", forumID).Scan(&exists); err != nil {
+		return fmt.Errorf("falha ao buscar fórum: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("fórum %d não encontrado", forumID)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO forum_permissions (forum_id, permission, role, user_id) VALUES (?, ?, ?, ?)
+		ON CONFLICT(forum_id, permission, role, user_id) DO NOTHING
+	`, forumID, perm, role, userID)
+	if err != nil {
+		return fmt.Errorf("falha ao conceder permissão: %w", err)
+	}
+	return nil
+}
+
+// RevokeForumPermission remove a concessão feita por GrantForumPermission.
+func (s *SQLiteStore) RevokeForumPermission(forumID int64, perm ForumPermission, role, username string) error {
+	role, userID, err := s.grantSubject(role, username)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec("DELETE FROM forum_permissions WHERE forum_id = ? AND permission = ? AND role = ? AND user_id = ?", forumID, perm, role, userID)
+	if err != nil {
+		return fmt.Errorf("falha ao revogar permissão: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("a permissão não foi concedida")
+	}
+	return nil
+}
+
+// GetForumGrants lista as concessões do fórum, agrupadas por permissão.
+func (s *SQLiteStore) GetForumGrants(forumID int64) ([]ForumGrant, error) {
+	rows, err := s.db.Query(`
+		SELECT a.forum_id, a.permission, a.role, a.user_id, COALESCE(u.username, ''), a.created_at
+		FROM forum_permissions a
+		LEFT JOIN users u ON u.id = a.user_id
+		WHERE a.forum_id = ?
+		ORDER BY CASE a.permission WHEN 'view' THEN 0 WHEN 'topic' THEN 1 WHEN 'reply' THEN 2 ELSE 3 END,
+			a.role = '', a.role, u.username
+	`, forumID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar permissões: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []ForumGrant
+	for rows.Next() {
+		var g ForumGrant
+		if err := rows.Scan(&g.ForumID, &g.Permission, &g.Role, &g.UserID, &g.Username, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear permissão: %w", err)
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+// HasForumPermission informa se o usuário tem a permissão no fórum. Ninguém tem
+// permissões em um fórum que está na lixeira.
+func (s *SQLiteStore) HasForumPermission(userID, forumID int64, perm ForumPermission) (bool, error) {
+	role, err := s.userRole(userID)
+	if err != nil {
+		return false, err
+	}
+	cond, args := forumAccessFilter("f.id", perm, userID, role)
+	var ok bool
+	err = s.db.QueryRow("SELECT EXISTS (SELECT 1 FROM forums f WHERE f.id = ? AND f.deleted_at IS NULL AND "+cond+")", append([]any{forumID}, args...)...).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("falha ao verificar permissão: %w", err)
+	}
+	return ok, nil
+}
+
+// checkForumPermission retorna ErrForumPermission se o usuário não tiver a permissão.
+func (s *SQLiteStore) checkForumPermission(userID, forumID int64, perm ForumPermission) error {
+	ok, err := s.HasForumPermission(userID, forumID, perm)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrForumPermission
+	}
+	return nil
+}
+
+// GetVisibleForums retorna os fóruns que o usuário pode ver.
+func (s *SQLiteStore) GetVisibleForums(userID int64) ([]Forum, error) {
+	role, err := s.userRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	cond, args := forumAccessFilter("f.id", PermissionView, userID, role)
+	return s.queryForums("SELECT f.id, f.name, f.description, f.guest_access, f.created_at FROM forums f WHERE f.deleted_at IS NULL AND "+cond+" ORDER BY f.name ASC", args...)
+}
@@ -0,0 +1,53 @@
+package database
+
+import "testing"
+
+func TestForumPermissionDefaults(t *testing.T) {
+	roles := []string{"user", "moderator", "admin", GuestRole, ""}
+	want := map[ForumPermission][]bool{
+		PermissionView:     {true, true, true, false, false},
+		PermissionReply:    {true, true, true, false, false},
+		PermissionTopic:    {false, true, true, false, false},
+		PermissionModerate: {false, true, true, false, false},
+	}
+	for perm, expected := range want {
+		for i, role := range roles {
+			if got := perm.defaultFor(role); got != expected[i] {
+				t.Errorf("%s.defaultFor(%q) = %v, esperado %v", perm, role, got, expected[i])
+			}
+		}
+	}
+}
+
+func TestForumAccessFilterRoles(t *testing.T) {
+	tests := []struct {
+		role string
+		perm ForumPermission
+		want string
+	}{
+		{"admin", PermissionModerate, "1"},
+		{"", PermissionView, "0"},
+		{GuestRole, PermissionReply, "0"},
+		{GuestRole, PermissionTopic, "0"},
+	}
+	for _, tt := range tests {
+		got, args := forumAccessFilter("f.id", tt.perm, 1, tt.role)
+		if got != tt.want || len(args) != 0 {
+			t.Errorf("forumAccessFilter(%s, %q) = %q, %v, esperado %q", tt.perm, tt.role, got, args, tt.want)
+		}
+	}
+}
+
+func TestParseForumPermission(t *testing.T) {
+	for _, p := range ForumPermissions {
+		got, err := ParseForumPermission(string(p))
+		if err != nil || got != p {
+			t.Errorf("ParseForumPermission(%q) = %q, %v", p, got, err)
+		}
+	}
+	for _, name := range []string{"", "View", "admin"} {
+		if _, err := ParseForumPermission(name); err == nil {
+			t.Errorf("ParseForumPermission(%q) aceitou uma permissão inválida", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 )
@@ -13,11 +14,39 @@ type Post struct {
 	Username  string // Para exibição, obtido com um JOIN
 	Content   string
 	CreatedAt time.Time
+	EditedAt  *time.Time // Última edição; nil se o post nunca foi editado
 }
 
-// CreatePost cria uma nova postagem em um tópico.
-func CreatePost(topicID, userID int, content string) error {
-	stmt, err := DB.Prepare("INSERT INTO posts(topic_id, user_id, content) VALUES(?, ?, ?)")
+// postColumns são as colunas lidas por scanPost, com o autor obtido pelo JOIN u.
+const postColumns = "p.id, p.topic_id, p.user_id, u.username, p.content, p.created_at, p.edited_at"
+
+func scanPost(row rowScanner) (*Post, error) {
+	post := &Post{}
+	var editedAt sql.NullTime
+	if err := row.Scan(&post.ID, &post.TopicID, &post.UserID, &post.Username, &post.Content, &post.CreatedAt, &editedAt); err != nil {
+		return nil, err
+	}
+	if editedAt.Valid {
+		post.EditedAt = &editedAt.Time
+	}
+	return post, nil
+}
+
+// CreatePost cria uma nova postagem em um tópico, se o usuário tiver permissão para
+// responder no fórum do tópico.
+func (s *SQLiteStore) CreatePost(topicID, userID int, content string) error {
+	var forumID int64
+	err := s.db.QueryRow("SELECT forum_id FROM topics WHERE id = ? AND deleted_at IS NULL", topicID).Scan(&forumID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("tópico %d não encontrado", topicID)
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao buscar tópico: %w", err)
+	}
+	if err := s.checkForumPermission(int64(userID), forumID, PermissionReply); err != nil {
+		return err
+	}
+	stmt, err := s.db.Prepare("INSERT INTO posts(topic_id, user_id, content) VALUES(?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -28,28 +57,61 @@ func CreatePost(topicID, userID int, content string) error {
 }
 
 // GetPostsByTopicID retorna todas as postagens de um determinado tópico, incluindo o nome do autor.
-// DeletePost remove um post do banco de dados.
-func DeletePost(id int) error {
-	stmt, err := DB.Prepare("DELETE FROM posts WHERE id = ?")
+// DeletePost move o post para a lixeira, registrando quem o apagou e o motivo. Quem apaga
+// precisa poder moderar o fórum do post; os visitantes (deletedBy 0) nunca podem.
+func (s *SQLiteStore) DeletePost(id int, deletedBy int64, reason string) error {
+	if deletedBy <= 0 {
+		return ErrForumPermission
+	}
+	var forumID int64
+	err := s.db.QueryRow(`
+		SELECT t.forum_id
+		FROM posts p
+		JOIN topics t ON t.id = p.topic_id
+		WHERE p.id = ? AND p.deleted_at IS NULL
+	`, id).Scan(&forumID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("post %d não encontrado", id)
+	}
 	if err != nil {
-		return fmt.Errorf("falha ao preparar statement: %w", err)
+		return fmt.Errorf("falha ao buscar post: %w", err)
 	}
-	defer stmt.Close()
+	if err := s.checkForumPermission(deletedBy, forumID, PermissionModerate); err != nil {
+		return err
+	}
+	return s.moveToTrash(TrashPost, int64(id), deletedBy, reason)
+}
 
-	_, err = stmt.Exec(id)
+// AdminDeletePost move o post para a lixeira sem verificar permissões e sem registrar
+// quem o apagou. É usado pelo bbs-admin.
+func (s *SQLiteStore) AdminDeletePost(id int, reason string) error {
+	return s.moveToTrash(TrashPost, int64(id), 0, reason)
+}
+
+// purgePost remove definitivamente um post, junto com suas revisões.
+func (s *SQLiteStore) purgePost(id int) error {
+	tx, err := s.db.Begin()
 	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM post_revisions WHERE post_id = ?", id); err != nil {
+		return fmt.Errorf("falha ao remover revisões do post: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM posts WHERE id = ?", id); err != nil {
 		return fmt.Errorf("falha ao deletar post: %w", err)
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func GetPostsByTopicID(topicID int) ([]*Post, error) {
-	rows, err := DB.Query(`
-		SELECT p.id, p.topic_id, p.user_id, u.username, p.content, p.created_at
+func (s *SQLiteStore) GetPostsByTopicID(topicID int) ([]*Post, error) {
+	rows, err := s.db.Query(`
+		SELECT `+postColumns+`
 		FROM posts p
 		JOIN users u ON p.user_id = u.id
-		WHERE p.topic_id = ?
+		WHERE p.topic_id = ? AND p.deleted_at IS NULL
 		ORDER BY p.created_at ASC
 	`, topicID)
 	if err != nil {
@@ -59,8 +121,8 @@ func GetPostsByTopicID(topicID int) ([]*Post, error) {
 
 	var posts []*Post
 	for rows.Next() {
-		post := &Post{}
-		if err := rows.Scan(&post.ID, &post.TopicID, &post.UserID, &post.Username, &post.Content, &post.CreatedAt); err != nil {
+		post, err := scanPost(rows)
+		if err != nil {
 			return nil, err
 		}
 		posts = append(posts, post)
@@ -68,3 +130,87 @@ func GetPostsByTopicID(topicID int) ([]*Post, error) {
 
 	return posts, nil
 }
+
+// GetPostsPageByTopicID retorna até limit posts do tópico, dos mais antigos para os mais
+// novos, começando após o cursor. Com after nil, retorna a primeira página. Se o usuário
+// não puder ver o fórum do tópico, a página vem vazia.
+func (s *SQLiteStore) GetPostsPageByTopicID(viewerID int64, topicID int, after *PageCursor, limit int) ([]*Post, error) {
+	role, err := s.userRole(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, viewerID, role)
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE p.topic_id = ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+			AND f.deleted_at IS NULL AND ` + access
+	args := append([]any{topicID}, accessArgs...)
+	if after != nil {
+		query += " AND (p.created_at > ? OR (p.created_at = ? AND p.id > ?))"
+		createdAt := sqliteTime(after.CreatedAt)
+		args = append(args, createdAt, createdAt, after.ID)
+	}
+	query += " ORDER BY p.created_at ASC, p.id ASC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao escanear post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// CountPostsByTopicID retorna quantos posts o tópico possui, ou 0 se o usuário não puder
+// ver o fórum do tópico.
+func (s *SQLiteStore) CountPostsByTopicID(viewerID int64, topicID int) (int, error) {
+	role, err := s.userRole(viewerID)
+	if err != nil {
+		return 0, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, viewerID, role)
+	var count int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE p.topic_id = ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+			AND f.deleted_at IS NULL AND `+access,
+		append([]any{topicID}, accessArgs...)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao contar posts: %w", err)
+	}
+	return count, nil
+}
+
+// GetVisiblePosts retorna os posts do tópico, ou ErrForumPermission se o usuário não
+// puder ver o fórum do tópico.
+func (s *SQLiteStore) GetVisiblePosts(viewerID int64, topicID int) ([]*Post, error) {
+	topic, err := s.GetTopicByID(topicID)
+	if err != nil {
+		return nil, err
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("tópico %d não encontrado", topicID)
+	}
+	if err := s.checkForumPermission(viewerID, int64(topic.ForumID), PermissionView); err != nil {
+		return nil, err
+	}
+	return s.GetPostsByTopicID(topicID)
+}
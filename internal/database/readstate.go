@@ -0,0 +1,143 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// UnreadTopic é um tópico com posts ainda não lidos pelo usuário.
+type UnreadTopic struct {
+	Topic
+	ForumName      string
+	Unread         int
+	LastReadPostID int
+}
+
+// Os posts do próprio usuário nunca contam como não lidos, nem os que estão na lixeira
+// ou em um tópico ou fórum que está.
+const unreadPostsFrom = `
+	FROM posts p
+	JOIN topics t ON t.id = p.topic_id
+	JOIN forums f ON f.id = t.forum_id
+	LEFT JOIN read_state r ON r.topic_id = p.topic_id AND r.user_id = ?
+	WHERE p.id > COALESCE(r.last_read_post_id, 0) AND p.user_id != ?
+		AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL`
+
+// MarkTopicRead registra que o usuário leu o tópico até o post informado.
+// A posição nunca retrocede: marcar um post anterior ao já lido não tem efeito.
+func (s *SQLiteStore) MarkTopicRead(userID int64, topicID, lastPostID int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO read_state (user_id, topic_id, last_read_post_id) VALUES (?, ?, ?)
+		ON CONFLICT(user_id, topic_id) DO UPDATE SET
+			last_read_post_id = MAX(last_read_post_id, excluded.last_read_post_id),
+			updated_at = CURRENT_TIMESTAMP
+	`, userID, topicID, lastPostID)
+	if err != nil {
+		return fmt.Errorf("falha ao marcar tópico como lido: %w", err)
+	}
+	return nil
+}
+
+// GetLastReadPostID retorna o ID do último post lido pelo usuário no tópico, ou 0.
+func (s *SQLiteStore) GetLastReadPostID(userID int64, topicID int) (int, error) {
+	var lastRead int
+	err := s.db.QueryRow("SELECT last_read_post_id FROM read_state WHERE user_id = ? AND topic_id = ?", userID, topicID).Scan(&lastRead)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("falha ao consultar leitura do tópico: %w", err)
+	}
+	return lastRead, nil
+}
+
+// GetForumUnreadCounts retorna, por fórum, quantos posts o usuário ainda não leu.
+// Fóruns sem posts novos não aparecem no mapa.
+func (s *SQLiteStore) GetForumUnreadCounts(userID int64) (map[int64]int, error) {
+	rows, err := s.db.Query("SELECT t.forum_id, COUNT(*)"+unreadPostsFrom+" GROUP BY t.forum_id", userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao contar posts não lidos: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var forumID int64
+		var count int
+		if err := rows.Scan(&forumID, &count); err != nil {
+			return nil, fmt.Errorf("falha ao escanear contagem: %w", err)
+		}
+		counts[forumID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetTopicUnreadCounts retorna, para os tópicos informados, quantos posts o usuário
+// ainda não leu. Tópicos sem posts novos não aparecem no mapa.
+func (s *SQLiteStore) GetTopicUnreadCounts(userID int64, topicIDs []int) (map[int]int, error) {
+	counts := make(map[int]int)
+	if len(topicIDs) == 0 {
+		return counts, nil
+	}
+
+	args := []any{userID, userID}
+	for _, id := range topicIDs {
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(topicIDs)), ",")
+
+	rows, err := s.db.Query("SELECT p.topic_id, COUNT(*)"+unreadPostsFrom+" AND p.topic_id IN ("+placeholders+") GROUP BY p.topic_id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao contar posts não lidos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var topicID, count int
+		if err := rows.Scan(&topicID, &count); err != nil {
+			return nil, fmt.Errorf("falha ao escanear contagem: %w", err)
+		}
+		counts[topicID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetUnreadTopics lista os tópicos com posts não lidos, começando pelos que
+// receberam posts mais recentemente. Os fóruns que o usuário não pode ver ficam de fora.
+func (s *SQLiteStore) GetUnreadTopics(userID int64, limit int) ([]UnreadTopic, error) {
+	role, err := s.userRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	access, accessArgs := forumAccessFilter("t.forum_id", PermissionView, userID, role)
+	args := append([]any{userID, userID}, accessArgs...)
+	rows, err := s.db.Query(`
+		SELECT t.id, t.forum_id, t.user_id, u.username, t.title, t.created_at, f.name,
+			COUNT(*), COALESCE(MAX(r.last_read_post_id), 0)
+		FROM posts p
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		JOIN users u ON u.id = t.user_id
+		LEFT JOIN read_state r ON r.topic_id = p.topic_id AND r.user_id = ?
+		WHERE p.id > COALESCE(r.last_read_post_id, 0) AND p.user_id != ?
+			AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL AND `+access+`
+		GROUP BY t.id
+		ORDER BY MAX(p.id) DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar tópicos não lidos: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []UnreadTopic
+	for rows.Next() {
+		var t UnreadTopic
+		if err := rows.Scan(&t.ID, &t.ForumID, &t.UserID, &t.Username, &t.Title, &t.CreatedAt, &t.ForumName, &t.Unread, &t.LastReadPostID); err != nil {
+			return nil, fmt.Errorf("falha ao escanear tópico não lido: %w", err)
+		}
+		topics = append(topics, t)
+	}
+	return topics, rows.Err()
+}
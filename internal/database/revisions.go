@@ -0,0 +1,135 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrEditWindow é retornado ao autor que tenta editar o próprio post depois do prazo.
+var ErrEditWindow = errors.New("o prazo para editar este post terminou")
+
+// PostRevision é uma versão anterior de um post, guardada quando ele é editado.
+type PostRevision struct {
+	ID       int64
+	PostID   int
+	Content  string // Conteúdo do post antes da edição
+	EditorID int64
+	Editor   string // Vazio se a conta de quem editou foi removida
+	EditedAt time.Time
+}
+
+// GetPostByID retorna um post com o nome do autor, ou nil se ele não existir ou se ele,
+// o seu tópico ou o seu fórum estiverem na lixeira.
+func (s *SQLiteStore) GetPostByID(id int) (*Post, error) {
+	post, err := scanPost(s.db.QueryRow(`
+		SELECT `+postColumns+`
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE p.id = ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar post: %w", err)
+	}
+	return post, nil
+}
+
+// UpdatePost substitui o conteúdo do post, guardando o anterior em post_revisions. Quem
+// pode moderar o fórum do post edita qualquer post a qualquer momento; o autor edita o
+// próprio post enquanto puder responder no fórum e dentro de editWindow, contado da
+// publicação (0 não limita o prazo).
+func (s *SQLiteStore) UpdatePost(postID int, editorID int64, content string, editWindow time.Duration) error {
+	if editorID <= 0 {
+		return ErrForumPermission
+	}
+	var authorID, forumID int64
+	var current string
+	var createdAt time.Time
+	err := s.db.QueryRow(`
+		SELECT p.user_id, t.forum_id, p.content, p.created_at
+		FROM posts p
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE p.id = ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL
+	`, postID).Scan(&authorID, &forumID, &current, &createdAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("post %d não encontrado", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao buscar post: %w", err)
+	}
+	canModerate, err := s.HasForumPermission(editorID, forumID, PermissionModerate)
+	if err != nil {
+		return err
+	}
+	if !canModerate {
+		if authorID != editorID {
+			return ErrForumPermission
+		}
+		if err := s.checkForumPermission(editorID, forumID, PermissionReply); err != nil {
+			return err
+		}
+		if editWindow > 0 && time.Since(createdAt) > editWindow {
+			return ErrEditWindow
+		}
+	}
+	if content == current {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := sqliteTime(time.Now())
+	if _, err := tx.Exec("INSERT INTO post_revisions (post_id, content, edited_by, edited_at) VALUES (?, ?, ?, ?)", postID, current, editorID, now); err != nil {
+		return fmt.Errorf("falha ao guardar revisão do post: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE posts SET content = ?, edited_at = ? WHERE id = ?", content, now, postID); err != nil {
+		return fmt.Errorf("falha ao atualizar post: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetPostRevisions retorna as versões anteriores do post, da mais antiga para a mais nova.
+// Não retorna nada se o post estiver na lixeira ou se o usuário não puder ver o seu fórum.
+func (s *SQLiteStore) GetPostRevisions(viewerID int64, postID int) ([]PostRevision, error) {
+	role, err := s.userRole(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, viewerID, role)
+	rows, err := s.db.Query(`
+		SELECT r.id, r.post_id, r.content, r.edited_by, COALESCE(u.username, ''), r.edited_at
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.edited_by
+		JOIN posts p ON p.id = r.post_id
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE r.post_id = ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+			AND f.deleted_at IS NULL AND `+access+`
+		ORDER BY r.id ASC
+	`, append([]any{postID}, accessArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar revisões: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []PostRevision
+	for rows.Next() {
+		var r PostRevision
+		if err := rows.Scan(&r.ID, &r.PostID, &r.Content, &r.EditorID, &r.Editor, &r.EditedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear revisão: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Marcadores que delimitam os termos encontrados em SearchResult.Snippet.
+// São caracteres de controle para que a TUI possa destacá-los sem ambiguidade
+// com o texto digitado pelos usuários.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+const defaultSearchLimit = 50
+
+// SearchFilters restringe os resultados de uma busca. Campos vazios não filtram.
+type SearchFilters struct {
+	ForumID int64
+	Author  string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+	// ViewerID limita a busca aos fóruns que o usuário pode ver. Sempre se aplica: o ID 0
+	// é o visitante, que só vê os fóruns abertos aos visitantes.
+	ViewerID int64
+}
+
+// SearchResult é um post ou título de tópico que corresponde à busca.
+// Para resultados no título do tópico, PostID é zero.
+type SearchResult struct {
+	PostID     int
+	TopicID    int
+	ForumID    int64
+	ForumName  string
+	TopicTitle string
+	Author     string
+	Snippet    string
+	CreatedAt  time.Time
+}
+
+// Search busca o texto nos posts e nos títulos de tópicos usando o índice FTS5.
+func (s *SQLiteStore) Search(query string, filters SearchFilters) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	match := ftsQuery(terms)
+
+	postWhere, postArgs := searchConditions("p", filters)
+	topicWhere, topicArgs := searchConditions("t", filters)
+	role, err := s.userRole(filters.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, filters.ViewerID, role)
+	postWhere += " AND " + access
+	postArgs = append(postArgs, accessArgs...)
+	topicWhere += " AND " + access
+	topicArgs = append(topicArgs, accessArgs...)
+
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	sqlQuery := `
+		SELECT p.id, t.id, f.id, f.name, t.title, u.username,
+			snippet(posts_fts, 0, ?, ?, '…', 16), p.created_at, bm25(posts_fts) AS rank
+		FROM posts_fts
+		JOIN posts p ON p.id = posts_fts.rowid
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		JOIN users u ON u.id = p.user_id
+		WHERE posts_fts MATCH ? AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL` + postWhere + `
+		UNION ALL
+		SELECT 0, t.id, f.id, f.name, t.title, u.username,
+			snippet(topics_fts, 0, ?, ?, '…', 16), t.created_at, bm25(topics_fts) AS rank
+		FROM topics_fts
+		JOIN topics t ON t.id = topics_fts.rowid
+		JOIN forums f ON f.id = t.forum_id
+		JOIN users u ON u.id = t.user_id
+		WHERE topics_fts MATCH ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL` + topicWhere + `
+		ORDER BY rank
+		LIMIT ?
+	`
+
+	args := []any{HighlightStart, HighlightEnd, match}
+	args = append(args, postArgs...)
+	args = append(args, HighlightStart, HighlightEnd, match)
+	args = append(args, topicArgs...)
+	args = append(args, limit)
+
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var rank float64
+		if err := rows.Scan(&r.PostID, &r.TopicID, &r.ForumID, &r.ForumName, &r.TopicTitle, &r.Author, &r.Snippet, &r.CreatedAt, &rank); err != nil {
+			return nil, fmt.Errorf("falha ao escanear resultado da busca: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// searchConditions monta as condições SQL dos filtros para a tabela com o alias informado.
+func searchConditions(alias string, filters SearchFilters) (string, []any) {
+	var b strings.Builder
+	var args []any
+
+	if filters.ForumID != 0 {
+		b.WriteString(" AND f.id = ?")
+		args = append(args, filters.ForumID)
+	}
+	if filters.Author != "" {
+		b.WriteString(" AND u.username = ?")
+		args = append(args, filters.Author)
+	}
+	// created_at é gravado pelo SQLite como texto em UTC; o filtro usa o mesmo formato.
+	if !filters.Since.IsZero() {
+		b.WriteString(" AND " + alias + ".created_at >= ?")
+		args = append(args, sqliteTime(filters.Since))
+	}
+	if !filters.Until.IsZero() {
+		b.WriteString(" AND " + alias + ".created_at < ?")
+		args = append(args, sqliteTime(filters.Until))
+	}
+
+	return b.String(), args
+}
+
+// searchTerms separa a busca em palavras, descartando pontuação.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ftsQuery converte as palavras em uma consulta FTS5 segura: cada termo vira uma
+// string entre aspas com busca por prefixo, e todos precisam aparecer.
+func ftsQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// matchSnippet produz um trecho de texto ao redor do primeiro termo encontrado,
+// com os termos destacados, no mesmo formato do snippet() do FTS5.
+// Retorna false se algum termo não aparecer no texto.
+func matchSnippet(text string, terms []string) (string, bool) {
+	lower := strings.ToLower(text)
+	first := -1
+	for _, term := range terms {
+		i := strings.Index(lower, strings.ToLower(term))
+		if i < 0 {
+			return "", false
+		}
+		if first < 0 || i < first {
+			first = i
+		}
+	}
+
+	words := strings.Fields(text)
+	// Localiza a palavra que contém a primeira ocorrência.
+	start, pos := 0, 0
+	for i, w := range words {
+		idx := strings.Index(text[pos:], w) + pos
+		if idx+len(w) > first {
+			start = i
+			break
+		}
+		pos = idx + len(w)
+	}
+
+	const window = 16
+	from := max(start-window/4, 0)
+	to := min(from+window, len(words))
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	for i := from; i < to; i++ {
+		if i > from {
+			b.WriteString(" ")
+		}
+		w := words[i]
+		highlighted := false
+		for _, term := range terms {
+			if strings.HasPrefix(strings.ToLower(w), strings.ToLower(term)) {
+				highlighted = true
+			}
+		}
+		if highlighted {
+			b.WriteString(HighlightStart + w + HighlightEnd)
+		} else {
+			b.WriteString(w)
+		}
+	}
+	if to < len(words) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Store reúne todas as operações de persistência do BBS. O servidor SSH, a TUI e o
+// bbs-admin dependem apenas desta interface, o que permite executar várias instâncias
+// no mesmo processo e substituir o SQLite por outra implementação (ex.: MemoryStore).
+type Store interface {
+	UserStore
+	KeyStore
+	TwoFactorStore
+	ForumStore
+	ForumACLStore
+	TopicStore
+	PostStore
+	TrashStore
+	SearchStore
+	ReadStateStore
+	MessageStore
+	ChatStore
+	LockoutStore
+	InviteStore
+
+	// Close libera os recursos do Store.
+	Close() error
+}
+
+// UserStore gerencia as contas de usuário.
+type UserStore interface {
+	CreateUser(username, password string) (*User, error)
+	// RegisterUser cria a conta de quem se cadastrou sozinho, consumindo o convite informado.
+	RegisterUser(account NewAccount) (*User, error)
+	// ApproveUser ativa uma conta pendente.
+	ApproveUser(username string) error
+	// GetUserByUsername retorna o usuário e o hash da senha, ou nil se não existir.
+	GetUserByUsername(username string) (*User, string, error)
+	GetAllUsers() ([]User, error)
+	UpdateUserPassword(username, currentPassword, newPassword string) error
+	AdminResetPassword(username, newPassword string) error
+	SetUserRole(username, role string) error
+	DeleteUser(username string) error
+}
+
+// KeyStore gerencia as chaves SSH e as preferências de autenticação dos usuários.
+type KeyStore interface {
+	AddUserKey(username, authorizedKey, comment string) (*UserKey, error)
+	GetUserKeys(username string) ([]UserKey, error)
+	// FindUserKey retorna a chave do usuário correspondente a pubKey, ou nil se não estiver autorizada.
+	FindUserKey(username string, pubKey ssh.PublicKey) (*UserKey, error)
+	TouchUserKey(id int64) error
+	RemoveUserKey(username, fingerprint string) error
+	IsPasswordLoginDisabled(username string) (bool, error)
+	SetPasswordLoginDisabled(username string, disabled bool) error
+}
+
+// TwoFactorStore guarda a verificação em duas etapas (TOTP) e os códigos de recuperação.
+type TwoFactorStore interface {
+	// GetTOTPSecret retorna o segredo TOTP, ou "" se a verificação estiver desativada.
+	GetTOTPSecret(username string) (string, error)
+	// EnableTOTP ativa a verificação e substitui os códigos de recuperação do usuário.
+	EnableTOTP(username, secret string, recoveryCodes []string) error
+	DisableTOTP(username string) error
+	// SetRecoveryCodes substitui os códigos de recuperação de quem já ativou a verificação.
+	SetRecoveryCodes(username string, recoveryCodes []string) error
+	// UseTOTPStep registra o intervalo da senha aceita. Retorna false se ela já tiver sido usada.
+	UseTOTPStep(username string, step int64) (bool, error)
+	// UseRecoveryCode consome um código. Retorna false se ele não existir ou já tiver sido usado.
+	UseRecoveryCode(username, code string) (bool, error)
+	CountRecoveryCodes(username string) (int, error)
+}
+
+// ForumStore gerencia os fóruns.
+type ForumStore interface {
+	CreateForum(name, description string) (*Forum, error)
+	UpdateForum(id int64, name, description string) error
+	// DeleteForum move o fórum para a lixeira; seus tópicos e posts ficam ocultos com ele.
+	// deletedBy é 0 quando o fórum é apagado pelo bbs-admin.
+	DeleteForum(id, deletedBy int64, reason string) error
+	GetAllForums() ([]Forum, error)
+	// GetGuestForums retorna os fóruns abertos aos visitantes, que entram sem conta.
+	GetGuestForums() ([]Forum, error)
+	SetForumGuestAccess(id int64, allowed bool) error
+}
+
+// ForumACLStore gerencia as permissões e os moderadores de cada fórum. As permissões são
+// concedidas a um papel ou a um usuário; sem concessões no fórum, vale o padrão de cada papel.
+type ForumACLStore interface {
+	// GrantForumPermission concede a permissão a role ou a username; informe só um dos dois.
+	GrantForumPermission(forumID int64, perm ForumPermission, role, username string) error
+	RevokeForumPermission(forumID int64, perm ForumPermission, role, username string) error
+	GetForumGrants(forumID int64) ([]ForumGrant, error)
+	HasForumPermission(userID, forumID int64, perm ForumPermission) (bool, error)
+	// GetVisibleForums retorna os fóruns que o usuário tem permissão de ver.
+	GetVisibleForums(userID int64) ([]Forum, error)
+	// AddForumModerator torna o usuário moderador do fórum, com todas as permissões nele.
+	AddForumModerator(forumID int64, username string) error
+	RemoveForumModerator(forumID int64, username string) error
+	GetForumModerators(forumID int64) ([]User, error)
+}
+
+// TopicStore gerencia os tópicos dos fóruns.
+type TopicStore interface {
+	// CreateTopic retorna ErrForumPermission se o usuário não puder criar tópicos no fórum.
+	CreateTopic(forumID, userID int, title string) error
+	// GetTopicByID retorna o tópico, ou nil se não existir ou estiver na lixeira.
+	GetTopicByID(id int) (*Topic, error)
+	// GetVisibleTopic retorna nil também se o usuário não puder ver o fórum do tópico.
+	GetVisibleTopic(viewerID int64, id int) (*Topic, error)
+	GetTopicsByForumID(forumID int) ([]*Topic, error)
+	// GetVisibleTopics retorna ErrForumPermission se o usuário não puder ver o fórum.
+	GetVisibleTopics(viewerID int64, forumID int) ([]*Topic, error)
+	// GetTopicsPageByForumID retorna uma página de tópicos, dos mais novos para os mais
+	// antigos; vazia, assim como a contagem, se o usuário não puder ver o fórum.
+	GetTopicsPageByForumID(viewerID int64, forumID int, after *PageCursor, limit int) ([]*Topic, error)
+	CountTopicsByForumID(viewerID int64, forumID int) (int, error)
+	// DeleteTopic move o tópico para a lixeira; seus posts ficam ocultos com ele. Retorna
+	// ErrForumPermission se deletedBy não puder moderar o fórum, ou se for o visitante.
+	DeleteTopic(id int, deletedBy int64, reason string) error
+	// AdminDeleteTopic move o tópico para a lixeira sem verificar permissões; é usado pelo
+	// bbs-admin, que não age em nome de um usuário.
+	AdminDeleteTopic(id int, reason string) error
+}
+
+// PostStore gerencia os posts dos tópicos.
+type PostStore interface {
+	// CreatePost retorna ErrForumPermission se o usuário não puder responder no fórum do tópico.
+	CreatePost(topicID, userID int, content string) error
+	GetPostsByTopicID(topicID int) ([]*Post, error)
+	// GetVisiblePosts retorna ErrForumPermission se o usuário não puder ver o fórum do tópico.
+	GetVisiblePosts(viewerID int64, topicID int) ([]*Post, error)
+	// GetPostsPageByTopicID retorna uma página de posts, dos mais antigos para os mais
+	// novos; vazia, assim como a contagem, se o usuário não puder ver o fórum do tópico.
+	GetPostsPageByTopicID(viewerID int64, topicID int, after *PageCursor, limit int) ([]*Post, error)
+	CountPostsByTopicID(viewerID int64, topicID int) (int, error)
+	// GetPostByID retorna nil se o post não existir ou se ele, o seu tópico ou o seu fórum
+	// estiverem na lixeira.
+	GetPostByID(id int) (*Post, error)
+	// UpdatePost guarda o conteúdo anterior como revisão. Retorna ErrForumPermission se
+	// o editor não puder moderar o fórum do post nem for o autor com permissão para
+	// responder nele, e ErrEditWindow se o autor editar depois de editWindow (0 não limita).
+	UpdatePost(postID int, editorID int64, content string, editWindow time.Duration) error
+	// GetPostRevisions não retorna nada se o usuário não puder ver o fórum do post.
+	GetPostRevisions(viewerID int64, postID int) ([]PostRevision, error)
+	// DeletePost move o post para a lixeira. Retorna ErrForumPermission se deletedBy não
+	// puder moderar o fórum do post, ou se for o visitante.
+	DeletePost(id int, deletedBy int64, reason string) error
+	// AdminDeletePost move o post para a lixeira sem verificar permissões, como
+	// AdminDeleteTopic.
+	AdminDeletePost(id int, reason string) error
+}
+
+// TrashStore gerencia a lixeira: os fóruns, tópicos e posts apagados ficam ocultos nas
+// demais consultas até serem restaurados ou removidos definitivamente.
+type TrashStore interface {
+	GetTrash() ([]TrashItem, error)
+	// RestoreTrashItem tira o item da lixeira. Retorna ErrForumNameTaken se o nome de um
+	// fórum restaurado estiver em uso por outro fórum.
+	RestoreTrashItem(kind TrashKind, id int64) error
+	PurgeTrashItem(kind TrashKind, id int64) error
+	// PurgeTrash remove definitivamente os itens apagados antes de before.
+	PurgeTrash(before time.Time) (int, error)
+}
+
+// SearchStore faz buscas de texto nos posts e títulos de tópicos.
+type SearchStore interface {
+	// Search retorna os resultados mais relevantes para query. Os termos encontrados
+	// aparecem no Snippet entre HighlightStart e HighlightEnd.
+	Search(query string, filters SearchFilters) ([]SearchResult, error)
+}
+
+// ReadStateStore registra até onde cada usuário leu cada tópico.
+type ReadStateStore interface {
+	MarkTopicRead(userID int64, topicID, lastPostID int) error
+	GetLastReadPostID(userID int64, topicID int) (int, error)
+	GetForumUnreadCounts(userID int64) (map[int64]int, error)
+	GetTopicUnreadCounts(userID int64, topicIDs []int) (map[int]int, error)
+	GetUnreadTopics(userID int64, limit int) ([]UnreadTopic, error)
+}
+
+// MessageStore gerencia as mensagens privadas entre usuários e os bloqueios de remetentes.
+type MessageStore interface {
+	// SendMessage inicia uma conversa. Retorna ErrBlocked se o destinatário bloqueou o remetente.
+	SendMessage(senderID int64, recipient, subject, body string) (*Conversation, error)
+	ReplyToConversation(senderID, conversationID int64, body string) (*Message, error)
+	GetConversations(userID int64) ([]Conversation, error)
+	GetConversationMessages(userID, conversationID int64) ([]Message, error)
+	MarkConversationRead(userID, conversationID int64) error
+	// DeleteConversation remove a conversa apenas da caixa de entrada do usuário.
+	DeleteConversation(userID, conversationID int64) error
+	CountUnreadMessages(userID int64) (int, error)
+	BlockUser(userID int64, username string) error
+	UnblockUser(userID int64, username string) error
+	GetBlockedUsers(userID int64) ([]User, error)
+}
+
+// ChatStore guarda o histórico das salas de chat.
+type ChatStore interface {
+	AddChatMessage(channel, username, kind, body string) (*ChatMessage, error)
+	// GetChatScrollback retorna as últimas limit mensagens da sala, em ordem cronológica.
+	GetChatScrollback(channel string, limit int) ([]ChatMessage, error)
+	GetChatChannels() ([]string, error)
+}
+
+// LockoutStore guarda as falhas de login por endereço IP e por nome de usuário. A política
+// de bloqueio fica com quem chama; o Store apenas persiste os registros.
+type LockoutStore interface {
+	// GetLockout retorna as falhas do alvo, ou nil se não houver nenhuma.
+	GetLockout(scope, target string) (*Lockout, error)
+	SaveLockout(l *Lockout) error
+	// DeleteLockout apaga as falhas do alvo. Retorna false se não havia registro.
+	DeleteLockout(scope, target string) (bool, error)
+	GetLockouts() ([]Lockout, error)
+	// PurgeLockouts apaga os registros sem falhas desde before que não estejam bloqueados.
+	PurgeLockouts(before time.Time) (int64, error)
+}
+
+// InviteStore gerencia os convites para o cadastro de novas contas.
+type InviteStore interface {
+	// CreateInvite gera um convite; createdBy é 0 para os convites da administração.
+	CreateInvite(createdBy int64, maxUses int, expiresAt *time.Time) (*Invite, error)
+	GetInvites() ([]Invite, error)
+	GetInvitesByUser(userID int64) ([]Invite, error)
+	// CountOpenInviteUses retorna quantos cadastros os convites ativos do usuário ainda permitem.
+	CountOpenInviteUses(userID int64) (int, error)
+	RevokeInvite(code string) error
+}
+
+var (
+	_ Store = (*SQLiteStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*eventStore)(nil)
+)
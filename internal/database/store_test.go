@@ -0,0 +1,589 @@
+package database
+
+import (
+	"errors"
+	"modern-bbs/internal/database/migrations"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// forEachStore executa o teste sobre cada implementação de Store, com os usuários de
+// seedDatabase (admin, mod e user) já criados. Os testes daqui são o contrato que
+// SQLiteStore e MemoryStore devem cumprir da mesma forma.
+func forEachStore(t *testing.T, test func(t *testing.T, s Store)) {
+	t.Run("SQLite", func(t *testing.T) {
+		db, err := OpenDB(filepath.Join(t.TempDir(), "bbs.db"))
+		if err != nil {
+			t.Fatalf("OpenDB: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		if _, err := migrations.Up(db); err != nil {
+			t.Fatalf("migrations.Up: %v", err)
+		}
+		// Os usuários são inseridos direto: o custo do bcrypt de CreateUser tornaria
+		// cada teste lento, e nenhum deles confere senhas.
+		if _, err := db.Exec(`INSERT INTO users (username, password_hash, role) VALUES
+			('admin', '', 'admin'), ('mod', '', 'moderator'), ('user', '', 'user')`); err != nil {
+			t.Fatalf("falha ao criar usuários: %v", err)
+		}
+		test(t, NewSQLiteStore(db))
+	})
+	t.Run("Memory", func(t *testing.T) {
+		s := NewMemoryStore()
+		if err := seedDatabase(s); err != nil {
+			t.Fatalf("seedDatabase: %v", err)
+		}
+		test(t, s)
+	})
+}
+
+// userID retorna o ID de um usuário existente.
+func userID(t *testing.T, s Store, username string) int64 {
+	t.Helper()
+	u, _, err := s.GetUserByUsername(username)
+	if err != nil || u == nil {
+		t.Fatalf("GetUserByUsername(%q) = %v, %v", username, u, err)
+	}
+	return u.ID
+}
+
+func createForum(t *testing.T, s Store, name string) int64 {
+	t.Helper()
+	f, err := s.CreateForum(name, "")
+	if err != nil {
+		t.Fatalf("CreateForum(%q): %v", name, err)
+	}
+	return f.ID
+}
+
+// createTopic cria um tópico com um primeiro post e retorna o ID do tópico.
+func createTopic(t *testing.T, s Store, forumID, authorID int64, title string) int {
+	t.Helper()
+	if err := s.CreateTopic(int(forumID), int(authorID), title); err != nil {
+		t.Fatalf("CreateTopic(%q): %v", title, err)
+	}
+	topics, err := s.GetTopicsByForumID(int(forumID))
+	if err != nil {
+		t.Fatalf("GetTopicsByForumID: %v", err)
+	}
+	for _, topic := range topics {
+		if topic.Title == title {
+			createPost(t, s, topic.ID, authorID, "primeiro post de "+title)
+			return topic.ID
+		}
+	}
+	t.Fatalf("tópico %q não encontrado após a criação", title)
+	return 0
+}
+
+// createPost cria um post e retorna o seu ID.
+func createPost(t *testing.T, s Store, topicID int, authorID int64, content string) int {
+	t.Helper()
+	if err := s.CreatePost(topicID, int(authorID), content); err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	posts, err := s.GetPostsByTopicID(topicID)
+	if err != nil {
+		t.Fatalf("GetPostsByTopicID: %v", err)
+	}
+	return posts[len(posts)-1].ID
+}
+
+func forumNames(forums []Forum) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range forums {
+		names[f.Name] = true
+	}
+	return names
+}
+
+func TestStoreForumPermissions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin, mod, user := userID(t, s, "admin"), userID(t, s, "mod"), userID(t, s, "user")
+		open := createForum(t, s, "Aberto")
+		staff := createForum(t, s, "Equipe")
+		if err := s.GrantForumPermission(staff, PermissionView, "moderator", ""); err != nil {
+			t.Fatalf("GrantForumPermission: %v", err)
+		}
+
+		tests := []struct {
+			name   string
+			userID int64
+			forum  int64
+			perm   ForumPermission
+			want   bool
+		}{
+			{"usuário vê por padrão", user, open, PermissionView, true},
+			{"usuário responde por padrão", user, open, PermissionReply, true},
+			{"usuário não cria tópicos por padrão", user, open, PermissionTopic, false},
+			{"moderador modera por padrão", mod, open, PermissionModerate, true},
+			{"concessão a outro papel esconde o fórum", user, staff, PermissionView, false},
+			{"concessão ao papel libera o fórum", mod, staff, PermissionView, true},
+			{"sem ver o fórum não se responde", user, staff, PermissionReply, false},
+			{"administrador tem todas", admin, staff, PermissionModerate, true},
+			{"visitante não vê fórum fechado", 0, open, PermissionView, false},
+			{"usuário inexistente não tem nenhuma", 9999, open, PermissionView, false},
+		}
+		for _, tt := range tests {
+			got, err := s.HasForumPermission(tt.userID, tt.forum, tt.perm)
+			if err != nil {
+				t.Fatalf("%s: HasForumPermission: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: HasForumPermission = %v, esperado %v", tt.name, got, tt.want)
+			}
+		}
+
+		if err := s.GrantForumPermission(staff, PermissionView, "", "user"); err != nil {
+			t.Fatalf("GrantForumPermission (usuário): %v", err)
+		}
+		if ok, _ := s.HasForumPermission(user, staff, PermissionView); !ok {
+			t.Error("a concessão ao usuário não liberou o fórum")
+		}
+		if ok, _ := s.HasForumPermission(user, staff, PermissionModerate); ok {
+			t.Error("o usuário modera o fórum sem ser moderador")
+		}
+		if err := s.AddForumModerator(staff, "user"); err != nil {
+			t.Fatalf("AddForumModerator: %v", err)
+		}
+		if ok, _ := s.HasForumPermission(user, staff, PermissionModerate); !ok {
+			t.Error("o moderador do fórum não pode moderá-lo")
+		}
+
+		if err := s.SetForumGuestAccess(open, true); err != nil {
+			t.Fatalf("SetForumGuestAccess: %v", err)
+		}
+		if ok, _ := s.HasForumPermission(0, open, PermissionView); !ok {
+			t.Error("o visitante não vê o fórum aberto aos visitantes")
+		}
+		if ok, _ := s.HasForumPermission(0, open, PermissionReply); ok {
+			t.Error("o visitante pode responder")
+		}
+	})
+}
+
+func TestStoreForumACLEnforcement(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin, user := userID(t, s, "admin"), userID(t, s, "user")
+		open := createForum(t, s, "Aberto")
+		hidden := createForum(t, s, "Oculto")
+		if err := s.GrantForumPermission(hidden, PermissionView, "admin", ""); err != nil {
+			t.Fatalf("GrantForumPermission: %v", err)
+		}
+		openTopic := createTopic(t, s, open, admin, "Público")
+		hiddenTopic := createTopic(t, s, hidden, admin, "Secreto")
+
+		forums, err := s.GetVisibleForums(user)
+		if err != nil {
+			t.Fatalf("GetVisibleForums: %v", err)
+		}
+		if names := forumNames(forums); !names["Aberto"] || names["Oculto"] {
+			t.Errorf("GetVisibleForums(user) = %v, esperado só Aberto", names)
+		}
+
+		if err := s.CreateTopic(int(open), int(user), "Novo"); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("CreateTopic sem permissão = %v, esperado ErrForumPermission", err)
+		}
+		if err := s.CreatePost(hiddenTopic, int(user), "oi"); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("CreatePost em fórum oculto = %v, esperado ErrForumPermission", err)
+		}
+		if _, err := s.GetVisibleTopics(user, int(hidden)); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("GetVisibleTopics em fórum oculto = %v, esperado ErrForumPermission", err)
+		}
+		if _, err := s.GetVisiblePosts(user, hiddenTopic); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("GetVisiblePosts em fórum oculto = %v, esperado ErrForumPermission", err)
+		}
+		if topic, err := s.GetVisibleTopic(user, hiddenTopic); err != nil || topic != nil {
+			t.Errorf("GetVisibleTopic em fórum oculto = %v, %v, esperado nil", topic, err)
+		}
+		if topic, err := s.GetVisibleTopic(user, openTopic); err != nil || topic == nil {
+			t.Errorf("GetVisibleTopic em fórum aberto = %v, %v", topic, err)
+		}
+
+		if topics, err := s.GetTopicsPageByForumID(user, int(hidden), nil, 10); err != nil || len(topics) != 0 {
+			t.Errorf("GetTopicsPageByForumID em fórum oculto = %d tópico(s), %v, esperado nenhum", len(topics), err)
+		}
+		if n, err := s.CountTopicsByForumID(user, int(hidden)); err != nil || n != 0 {
+			t.Errorf("CountTopicsByForumID em fórum oculto = %d, %v, esperado 0", n, err)
+		}
+		if n, _ := s.CountTopicsByForumID(admin, int(hidden)); n != 1 {
+			t.Errorf("CountTopicsByForumID do administrador = %d, esperado 1", n)
+		}
+		if posts, err := s.GetPostsPageByTopicID(user, hiddenTopic, nil, 10); err != nil || len(posts) != 0 {
+			t.Errorf("GetPostsPageByTopicID em fórum oculto = %d post(s), %v, esperado nenhum", len(posts), err)
+		}
+		if n, err := s.CountPostsByTopicID(user, hiddenTopic); err != nil || n != 0 {
+			t.Errorf("CountPostsByTopicID em fórum oculto = %d, %v, esperado 0", n, err)
+		}
+		if posts, _ := s.GetPostsPageByTopicID(0, openTopic, nil, 10); len(posts) != 0 {
+			t.Errorf("GetPostsPageByTopicID de um visitante = %d post(s), esperado nenhum", len(posts))
+		}
+		if posts, _ := s.GetPostsPageByTopicID(user, openTopic, nil, 10); len(posts) != 1 {
+			t.Errorf("GetPostsPageByTopicID em fórum aberto = %d post(s), esperado 1", len(posts))
+		}
+		hiddenPosts, _ := s.GetPostsByTopicID(hiddenTopic)
+		if err := s.UpdatePost(hiddenPosts[0].ID, admin, "editado", 0); err != nil {
+			t.Fatalf("UpdatePost: %v", err)
+		}
+		if revisions, err := s.GetPostRevisions(user, hiddenPosts[0].ID); err != nil || len(revisions) != 0 {
+			t.Errorf("GetPostRevisions em fórum oculto = %d revisão(ões), %v, esperado nenhuma", len(revisions), err)
+		}
+		if revisions, _ := s.GetPostRevisions(admin, hiddenPosts[0].ID); len(revisions) != 1 {
+			t.Errorf("GetPostRevisions do administrador = %d revisão(ões), esperado 1", len(revisions))
+		}
+
+		if err := s.DeleteTopic(openTopic, user, ""); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("DeleteTopic sem moderar = %v, esperado ErrForumPermission", err)
+		}
+		posts, _ := s.GetPostsByTopicID(openTopic)
+		if err := s.DeletePost(posts[0].ID, user, ""); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("DeletePost sem moderar = %v, esperado ErrForumPermission", err)
+		}
+		// O ID 0 é o visitante, e não um atalho para apagar sem verificação.
+		if err := s.DeleteTopic(openTopic, 0, ""); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("DeleteTopic do visitante = %v, esperado ErrForumPermission", err)
+		}
+		if err := s.DeletePost(posts[0].ID, 0, ""); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("DeletePost do visitante = %v, esperado ErrForumPermission", err)
+		}
+
+		results, err := s.Search("Secreto", SearchFilters{ViewerID: user})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("a busca retornou %d resultado(s) de um fórum oculto", len(results))
+		}
+		if results, _ := s.Search("Secreto", SearchFilters{}); len(results) != 0 {
+			t.Errorf("a busca de um visitante retornou %d resultado(s) de um fórum oculto", len(results))
+		}
+		if results, _ := s.Search("Secreto", SearchFilters{ViewerID: admin}); len(results) == 0 {
+			t.Error("a busca do administrador não encontrou o tópico")
+		}
+	})
+}
+
+func TestStoreUpdatePost(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin, mod, user := userID(t, s, "admin"), userID(t, s, "mod"), userID(t, s, "user")
+		forum := createForum(t, s, "Geral")
+		topic := createTopic(t, s, forum, admin, "Tópico")
+		post := createPost(t, s, topic, user, "original")
+
+		if err := s.UpdatePost(post, user, "editado pelo autor", time.Hour); err != nil {
+			t.Fatalf("UpdatePost do autor no prazo: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+		if err := s.UpdatePost(post, user, "fora do prazo", time.Nanosecond); !errors.Is(err, ErrEditWindow) {
+			t.Errorf("UpdatePost do autor fora do prazo = %v, esperado ErrEditWindow", err)
+		}
+		if err := s.UpdatePost(post, mod, "editado pelo moderador", time.Nanosecond); err != nil {
+			t.Errorf("UpdatePost do moderador fora do prazo: %v", err)
+		}
+		if err := s.UpdatePost(post, 0, "visitante", 0); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("UpdatePost do visitante = %v, esperado ErrForumPermission", err)
+		}
+		other := createPost(t, s, topic, admin, "do administrador")
+		if err := s.UpdatePost(other, user, "alheio", 0); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("UpdatePost de um post alheio = %v, esperado ErrForumPermission", err)
+		}
+
+		// Quem perdeu a permissão de responder no fórum não edita mais os próprios posts.
+		if err := s.GrantForumPermission(forum, PermissionReply, "moderator", ""); err != nil {
+			t.Fatalf("GrantForumPermission: %v", err)
+		}
+		if err := s.UpdatePost(post, user, "sem permissão", 0); !errors.Is(err, ErrForumPermission) {
+			t.Errorf("UpdatePost sem permissão de responder = %v, esperado ErrForumPermission", err)
+		}
+
+		if err := s.DeleteForum(forum, admin, ""); err != nil {
+			t.Fatalf("DeleteForum: %v", err)
+		}
+		if err := s.UpdatePost(post, admin, "na lixeira", 0); err == nil {
+			t.Error("UpdatePost editou um post de um fórum na lixeira")
+		}
+
+		revisions, err := s.GetPostRevisions(admin, post)
+		if err != nil {
+			t.Fatalf("GetPostRevisions: %v", err)
+		}
+		if len(revisions) != 0 {
+			t.Errorf("GetPostRevisions de um fórum na lixeira = %d revisão(ões), esperado nenhuma", len(revisions))
+		}
+	})
+}
+
+func TestStoreSoftDelete(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin := userID(t, s, "admin")
+		forum := createForum(t, s, "Geral")
+		topic := createTopic(t, s, forum, admin, "Tópico")
+		post := createPost(t, s, topic, admin, "resposta")
+
+		if err := s.DeletePost(post, admin, "spam"); err != nil {
+			t.Fatalf("DeletePost: %v", err)
+		}
+		if p, _ := s.GetPostByID(post); p != nil {
+			t.Error("GetPostByID retornou um post da lixeira")
+		}
+		if n, _ := s.CountPostsByTopicID(admin, topic); n != 1 {
+			t.Errorf("CountPostsByTopicID = %d, esperado 1", n)
+		}
+		trash, err := s.GetTrash()
+		if err != nil {
+			t.Fatalf("GetTrash: %v", err)
+		}
+		if len(trash) != 1 || trash[0].Kind != TrashPost || trash[0].ID != int64(post) || trash[0].Reason != "spam" {
+			t.Fatalf("GetTrash = %+v, esperado o post com o motivo", trash)
+		}
+		if err := s.DeletePost(post, admin, ""); err == nil {
+			t.Error("DeletePost aceitou um post que já está na lixeira")
+		}
+		if err := s.RestoreTrashItem(TrashPost, int64(post)); err != nil {
+			t.Fatalf("RestoreTrashItem: %v", err)
+		}
+		if p, _ := s.GetPostByID(post); p == nil {
+			t.Error("o post restaurado continua oculto")
+		}
+
+		// Os tópicos e posts de um fórum apagado somem com ele.
+		if err := s.DeleteForum(forum, admin, ""); err != nil {
+			t.Fatalf("DeleteForum: %v", err)
+		}
+		if forums, _ := s.GetAllForums(); forumNames(forums)["Geral"] {
+			t.Error("GetAllForums retornou um fórum da lixeira")
+		}
+		if tp, _ := s.GetTopicByID(topic); tp != nil {
+			t.Error("GetTopicByID retornou um tópico de um fórum da lixeira")
+		}
+		if p, _ := s.GetPostByID(post); p != nil {
+			t.Error("GetPostByID retornou um post de um fórum da lixeira")
+		}
+
+		// O nome de um fórum da lixeira pode ser reutilizado, mas impede a restauração.
+		other := createForum(t, s, "Geral")
+		if err := s.RestoreTrashItem(TrashForum, forum); !errors.Is(err, ErrForumNameTaken) {
+			t.Errorf("RestoreTrashItem com o nome em uso = %v, esperado ErrForumNameTaken", err)
+		}
+		if err := s.UpdateForum(other, "Outro", ""); err != nil {
+			t.Fatalf("UpdateForum: %v", err)
+		}
+		if err := s.RestoreTrashItem(TrashForum, forum); err != nil {
+			t.Fatalf("RestoreTrashItem: %v", err)
+		}
+		if tp, _ := s.GetTopicByID(topic); tp == nil {
+			t.Error("o tópico do fórum restaurado continua oculto")
+		}
+
+		if err := s.AdminDeleteTopic(topic, ""); err != nil {
+			t.Fatalf("AdminDeleteTopic: %v", err)
+		}
+		if err := s.PurgeTrashItem(TrashTopic, int64(topic)); err != nil {
+			t.Fatalf("PurgeTrashItem: %v", err)
+		}
+		if trash, _ := s.GetTrash(); len(trash) != 0 {
+			t.Errorf("GetTrash após a remoção definitiva = %+v", trash)
+		}
+		if err := s.RestoreTrashItem(TrashTopic, int64(topic)); err == nil {
+			t.Error("RestoreTrashItem aceitou um tópico removido definitivamente")
+		}
+		if err := s.RestoreTrashItem(TrashForum, forum); err == nil {
+			t.Error("RestoreTrashItem aceitou um fórum fora da lixeira")
+		}
+	})
+}
+
+func TestStorePurgeTrash(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin := userID(t, s, "admin")
+		forum := createForum(t, s, "Geral")
+		topic := createTopic(t, s, forum, admin, "Tópico")
+		if err := s.DeleteTopic(topic, admin, ""); err != nil {
+			t.Fatalf("DeleteTopic: %v", err)
+		}
+
+		if n, err := s.PurgeTrash(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Errorf("PurgeTrash de itens recentes = %d, %v, esperado 0", n, err)
+		}
+		if n, err := s.PurgeTrash(time.Now().Add(time.Hour)); err != nil || n != 1 {
+			t.Errorf("PurgeTrash = %d, %v, esperado 1", n, err)
+		}
+		if trash, _ := s.GetTrash(); len(trash) != 0 {
+			t.Errorf("GetTrash após PurgeTrash = %+v", trash)
+		}
+	})
+}
+
+func TestStoreUnreadCounts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin, user := userID(t, s, "admin"), userID(t, s, "user")
+		forum := createForum(t, s, "Geral")
+		topic := createTopic(t, s, forum, admin, "Tópico")
+		createPost(t, s, topic, admin, "segunda")
+		last := createPost(t, s, topic, admin, "terceira")
+
+		counts, err := s.GetForumUnreadCounts(user)
+		if err != nil {
+			t.Fatalf("GetForumUnreadCounts: %v", err)
+		}
+		if counts[forum] != 3 {
+			t.Errorf("não lidos no fórum = %d, esperado 3", counts[forum])
+		}
+		if counts, _ := s.GetForumUnreadCounts(admin); counts[forum] != 0 {
+			t.Errorf("os próprios posts contam como não lidos: %d", counts[forum])
+		}
+
+		if err := s.MarkTopicRead(user, topic, last-1); err != nil {
+			t.Fatalf("MarkTopicRead: %v", err)
+		}
+		if counts, _ := s.GetTopicUnreadCounts(user, []int{topic}); counts[topic] != 1 {
+			t.Errorf("não lidos no tópico = %d, esperado 1", counts[topic])
+		}
+		if id, _ := s.GetLastReadPostID(user, topic); id != last-1 {
+			t.Errorf("GetLastReadPostID = %d, esperado %d", id, last-1)
+		}
+
+		// Os posts da lixeira não contam.
+		if err := s.DeletePost(last, admin, ""); err != nil {
+			t.Fatalf("DeletePost: %v", err)
+		}
+		if counts, _ := s.GetTopicUnreadCounts(user, []int{topic}); counts[topic] != 0 {
+			t.Errorf("não lidos após apagar o post = %d, esperado 0", counts[topic])
+		}
+
+		if err := s.MarkTopicRead(user, topic, last); err != nil {
+			t.Fatalf("MarkTopicRead: %v", err)
+		}
+		if counts, _ := s.GetForumUnreadCounts(user); counts[forum] != 0 {
+			t.Errorf("não lidos após a leitura = %d, esperado 0", counts[forum])
+		}
+	})
+}
+
+func TestStoreLockouts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		now := time.Now().UTC().Truncate(time.Second)
+		until := now.Add(time.Minute)
+		locked := &Lockout{Scope: LockoutScopeUser, Target: "user", Failures: 6, LastFailureAt: now, LockedUntil: &until}
+		if err := s.SaveLockout(locked); err != nil {
+			t.Fatalf("SaveLockout: %v", err)
+		}
+		old := &Lockout{Scope: LockoutScopeIP, Target: "192.0.2.1", Failures: 2, LastFailureAt: now.Add(-2 * time.Hour)}
+		if err := s.SaveLockout(old); err != nil {
+			t.Fatalf("SaveLockout: %v", err)
+		}
+
+		l, err := s.GetLockout(LockoutScopeUser, "user")
+		if err != nil || l == nil {
+			t.Fatalf("GetLockout = %v, %v", l, err)
+		}
+		if l.Failures != 6 || !l.LastFailureAt.Equal(now) || !l.Locked(now) || l.Locked(until) {
+			t.Errorf("GetLockout = %+v, esperado o registro gravado", l)
+		}
+		if l, _ := s.GetLockout(LockoutScopeIP, "user"); l != nil {
+			t.Errorf("GetLockout confundiu os escopos: %+v", l)
+		}
+
+		if n, err := s.PurgeLockouts(now.Add(-time.Hour)); err != nil || n != 1 {
+			t.Errorf("PurgeLockouts = %d, %v, esperado 1", n, err)
+		}
+		if all, _ := s.GetLockouts(); len(all) != 1 || all[0].Target != "user" {
+			t.Errorf("GetLockouts após a limpeza = %+v", all)
+		}
+
+		if ok, err := s.DeleteLockout(LockoutScopeUser, "user"); err != nil || !ok {
+			t.Errorf("DeleteLockout = %v, %v, esperado true", ok, err)
+		}
+		if ok, _ := s.DeleteLockout(LockoutScopeUser, "user"); ok {
+			t.Error("DeleteLockout retornou true sem registro")
+		}
+	})
+}
+
+func TestStoreTOTPStepReplay(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if ok, _ := s.UseTOTPStep("user", 100); ok {
+			t.Error("UseTOTPStep aceitou uma senha sem a verificação ativa")
+		}
+		if err := s.EnableTOTP("user", "JBSWY3DPEHPK3PXP", []string{"AAAAA-BBBBB"}); err != nil {
+			t.Fatalf("EnableTOTP: %v", err)
+		}
+
+		steps := []struct {
+			step int64
+			want bool
+		}{
+			{100, true},
+			{100, false}, // A mesma senha outra vez
+			{99, false},  // Uma senha anterior, ainda dentro da tolerância do relógio
+			{101, true},
+		}
+		for _, tt := range steps {
+			ok, err := s.UseTOTPStep("user", tt.step)
+			if err != nil {
+				t.Fatalf("UseTOTPStep(%d): %v", tt.step, err)
+			}
+			if ok != tt.want {
+				t.Errorf("UseTOTPStep(%d) = %v, esperado %v", tt.step, ok, tt.want)
+			}
+		}
+	})
+}
+
+func TestStoreDeleteUser(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin := userID(t, s, "admin")
+		forum := createForum(t, s, "Geral")
+		createTopic(t, s, forum, admin, "Tópico")
+
+		if err := s.DeleteUser("admin"); !errors.Is(err, ErrUserHasContent) {
+			t.Errorf("DeleteUser de quem tem posts = %v, esperado ErrUserHasContent", err)
+		}
+		if u, _, _ := s.GetUserByUsername("admin"); u == nil {
+			t.Error("a remoção recusada apagou o usuário")
+		}
+
+		if err := s.AddForumModerator(forum, "user"); err != nil {
+			t.Fatalf("AddForumModerator: %v", err)
+		}
+		if err := s.DeleteUser("user"); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		if mods, _ := s.GetForumModerators(forum); len(mods) != 0 {
+			t.Errorf("GetForumModerators após remover o usuário = %+v", mods)
+		}
+	})
+}
+
+func TestStoreRegisterUser(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		admin := userID(t, s, "admin")
+
+		if _, err := s.RegisterUser(NewAccount{Username: "Admin", Password: "senha-longa"}); !errors.Is(err, ErrUsernameTaken) {
+			t.Errorf("RegisterUser de um nome que só muda na caixa = %v, esperado ErrUsernameTaken", err)
+		}
+		if _, err := s.RegisterUser(NewAccount{Username: "novo", Password: "senha-longa", InviteCode: "NAOEXISTE"}); !errors.Is(err, ErrInvalidInvite) {
+			t.Errorf("RegisterUser com convite inexistente = %v, esperado ErrInvalidInvite", err)
+		}
+
+		invite, err := s.CreateInvite(admin, 1, nil)
+		if err != nil {
+			t.Fatalf("CreateInvite: %v", err)
+		}
+		// Um cadastro recusado pelo nome não gasta o convite.
+		if _, err := s.RegisterUser(NewAccount{Username: "ADMIN", Password: "senha-longa", InviteCode: invite.Code}); !errors.Is(err, ErrUsernameTaken) {
+			t.Errorf("RegisterUser com convite e nome em uso = %v, esperado ErrUsernameTaken", err)
+		}
+		user, err := s.RegisterUser(NewAccount{Username: "novo", Password: "senha-longa", InviteCode: invite.Code})
+		if err != nil {
+			t.Fatalf("RegisterUser: %v", err)
+		}
+		if user.InvitedBy != admin {
+			t.Errorf("InvitedBy = %d, esperado %d", user.InvitedBy, admin)
+		}
+		if _, err := s.RegisterUser(NewAccount{Username: "outro", Password: "senha-longa", InviteCode: invite.Code}); !errors.Is(err, ErrInvalidInvite) {
+			t.Errorf("RegisterUser com convite esgotado = %v, esperado ErrInvalidInvite", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 )
@@ -15,9 +16,13 @@ type Topic struct {
 	CreatedAt time.Time
 }
 
-// CreateTopic cria um novo tópico no banco de dados.
-func CreateTopic(forumID, userID int, title string) error {
-	stmt, err := DB.Prepare("INSERT INTO topics(forum_id, user_id, title) VALUES(?, ?, ?)")
+// CreateTopic cria um novo tópico no banco de dados, se o usuário tiver permissão para
+// criar tópicos no fórum.
+func (s *SQLiteStore) CreateTopic(forumID, userID int, title string) error {
+	if err := s.checkForumPermission(int64(userID), int64(forumID), PermissionTopic); err != nil {
+		return err
+	}
+	stmt, err := s.db.Prepare("INSERT INTO topics(forum_id, user_id, title) VALUES(?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -28,13 +33,54 @@ func CreateTopic(forumID, userID int, title string) error {
 }
 
 // GetTopicsByForumID retorna todos os tópicos de um determinado fórum, incluindo o nome do autor.
-// DeleteTopic remove um tópico e todos os seus posts.
-func DeleteTopic(id int) error {
-	tx, err := DB.Begin()
+// DeleteTopic move o tópico para a lixeira, registrando quem o apagou e o motivo. Os
+// posts do tópico ficam ocultos com ele. Quem apaga precisa poder moderar o fórum do
+// tópico; os visitantes (deletedBy 0) nunca podem.
+func (s *SQLiteStore) DeleteTopic(id int, deletedBy int64, reason string) error {
+	if deletedBy <= 0 {
+		return ErrForumPermission
+	}
+	var forumID int64
+	err := s.db.QueryRow("SELECT forum_id FROM topics WHERE id = ? AND deleted_at IS NULL", id).Scan(&forumID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("tópico %d não encontrado", id)
+	}
+	if err != nil {
+		return fmt.Errorf("falha ao buscar tópico: %w", err)
+	}
+	if err := s.checkForumPermission(deletedBy, forumID, PermissionModerate); err != nil {
+		return err
+	}
+	return s.moveToTrash(TrashTopic, int64(id), deletedBy, reason)
+}
+
+// AdminDeleteTopic move o tópico para a lixeira sem verificar permissões e sem registrar
+// quem o apagou. É usado pelo bbs-admin.
+func (s *SQLiteStore) AdminDeleteTopic(id int, reason string) error {
+	return s.moveToTrash(TrashTopic, int64(id), 0, reason)
+}
+
+// purgeTopic remove definitivamente um tópico e todos os seus posts.
+func (s *SQLiteStore) purgeTopic(id int) error {
+	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("falha ao iniciar transação: %w", err)
 	}
 
+	// Remove o estado de leitura do tópico
+	_, err = tx.Exec("DELETE FROM read_state WHERE topic_id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover estado de leitura do tópico: %w", err)
+	}
+
+	// Remove as revisões dos posts do tópico
+	_, err = tx.Exec("DELETE FROM post_revisions WHERE post_id IN (SELECT id FROM posts WHERE topic_id = ?)", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("falha ao remover revisões do tópico: %w", err)
+	}
+
 	// Deleta os posts associados ao tópico
 	_, err = tx.Exec("DELETE FROM posts WHERE topic_id = ?", id)
 	if err != nil {
@@ -52,12 +98,12 @@ func DeleteTopic(id int) error {
 	return tx.Commit()
 }
 
-func GetTopicsByForumID(forumID int) ([]*Topic, error) {
-	rows, err := DB.Query(`
+func (s *SQLiteStore) GetTopicsByForumID(forumID int) ([]*Topic, error) {
+	rows, err := s.db.Query(`
 		SELECT t.id, t.forum_id, t.user_id, u.username, t.title, t.created_at
 		FROM topics t
 		JOIN users u ON t.user_id = u.id
-		WHERE t.forum_id = ?
+		WHERE t.forum_id = ? AND t.deleted_at IS NULL
 		ORDER BY t.created_at DESC
 	`, forumID)
 	if err != nil {
@@ -76,3 +122,112 @@ func GetTopicsByForumID(forumID int) ([]*Topic, error) {
 
 	return topics, nil
 }
+
+// GetTopicsPageByForumID retorna até limit tópicos do fórum, dos mais novos para os mais
+// antigos, começando após o cursor. Com after nil, retorna a primeira página. Se o usuário
+// não puder ver o fórum, a página vem vazia.
+func (s *SQLiteStore) GetTopicsPageByForumID(viewerID int64, forumID int, after *PageCursor, limit int) ([]*Topic, error) {
+	role, err := s.userRole(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, viewerID, role)
+	query := `
+		SELECT t.id, t.forum_id, t.user_id, u.username, t.title, t.created_at
+		FROM topics t
+		JOIN users u ON t.user_id = u.id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE t.forum_id = ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL AND ` + access
+	args := append([]any{forumID}, accessArgs...)
+	if after != nil {
+		query += " AND (t.created_at < ? OR (t.created_at = ? AND t.id < ?))"
+		createdAt := sqliteTime(after.CreatedAt)
+		args = append(args, createdAt, createdAt, after.ID)
+	}
+	query += " ORDER BY t.created_at DESC, t.id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar tópicos: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []*Topic
+	for rows.Next() {
+		topic := &Topic{}
+		if err := rows.Scan(&topic.ID, &topic.ForumID, &topic.UserID, &topic.Username, &topic.Title, &topic.CreatedAt); err != nil {
+			return nil, fmt.Errorf("falha ao escanear tópico: %w", err)
+		}
+		topics = append(topics, topic)
+	}
+
+	return topics, rows.Err()
+}
+
+// CountTopicsByForumID retorna quantos tópicos o fórum possui, ou 0 se o usuário não
+// puder vê-lo.
+func (s *SQLiteStore) CountTopicsByForumID(viewerID int64, forumID int) (int, error) {
+	role, err := s.userRole(viewerID)
+	if err != nil {
+		return 0, err
+	}
+	access, accessArgs := forumAccessFilter("f.id", PermissionView, viewerID, role)
+	var count int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM topics t
+		JOIN users u ON t.user_id = u.id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE t.forum_id = ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL AND `+access,
+		append([]any{forumID}, accessArgs...)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao contar tópicos: %w", err)
+	}
+	return count, nil
+}
+
+// GetTopicByID busca um tópico pelo ID. Retorna nil se o tópico não existir ou se ele,
+// ou o seu fórum, estiver na lixeira.
+func (s *SQLiteStore) GetTopicByID(id int) (*Topic, error) {
+	row := s.db.QueryRow(`
+		SELECT t.id, t.forum_id, t.user_id, u.username, t.title, t.created_at
+		FROM topics t
+		JOIN users u ON t.user_id = u.id
+		JOIN forums f ON f.id = t.forum_id
+		WHERE t.id = ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL
+	`, id)
+
+	topic := &Topic{}
+	if err := row.Scan(&topic.ID, &topic.ForumID, &topic.UserID, &topic.Username, &topic.Title, &topic.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao buscar tópico: %w", err)
+	}
+
+	return topic, nil
+}
+
+// GetVisibleTopic busca um tópico pelo ID, se o usuário puder ver o seu fórum. Retorna nil
+// se o tópico não existir, estiver na lixeira ou estiver em um fórum oculto ao usuário.
+func (s *SQLiteStore) GetVisibleTopic(viewerID int64, id int) (*Topic, error) {
+	topic, err := s.GetTopicByID(id)
+	if err != nil || topic == nil {
+		return nil, err
+	}
+	visible, err := s.HasForumPermission(viewerID, int64(topic.ForumID), PermissionView)
+	if err != nil || !visible {
+		return nil, err
+	}
+	return topic, nil
+}
+
+// GetVisibleTopics retorna os tópicos do fórum, ou ErrForumPermission se o usuário não
+// puder vê-lo.
+func (s *SQLiteStore) GetVisibleTopics(viewerID int64, forumID int) ([]*Topic, error) {
+	if err := s.checkForumPermission(viewerID, int64(forumID), PermissionView); err != nil {
+		return nil, err
+	}
+	return s.GetTopicsByForumID(forumID)
+}
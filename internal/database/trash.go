@@ -0,0 +1,205 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// TrashKind é o tipo de um item da lixeira.
+type TrashKind string
+
+const (
+	TrashForum TrashKind = "forum"
+	TrashTopic TrashKind = "topic"
+	TrashPost  TrashKind = "post"
+)
+
+// ParseTrashKind converte o nome de um tipo de item (forum, topic ou post).
+func ParseTrashKind(name string) (TrashKind, error) {
+	switch k := TrashKind(name); k {
+	case TrashForum, TrashTopic, TrashPost:
+		return k, nil
+	}
+	return "", fmt.Errorf("tipo inválido: %q (use forum, topic ou post)", name)
+}
+
+// Label retorna o nome do tipo exibido aos usuários.
+func (k TrashKind) Label() string {
+	switch k {
+	case TrashForum:
+		return "fórum"
+	case TrashTopic:
+		return "tópico"
+	}
+	return string(k)
+}
+
+// table retorna a tabela dos itens do tipo.
+func (k TrashKind) table() string {
+	switch k {
+	case TrashForum:
+		return "forums"
+	case TrashTopic:
+		return "topics"
+	}
+	return "posts"
+}
+
+// TrashItem é um fórum, tópico ou post apagado, que pode ser restaurado ou removido
+// definitivamente. Os tópicos e posts de um fórum ou tópico apagado não aparecem na
+// lixeira: eles voltam ou são removidos junto com ele.
+type TrashItem struct {
+	Kind      TrashKind
+	ID        int64
+	Title     string // Nome do fórum, título do tópico ou conteúdo do post
+	Forum     string // Fórum do tópico ou do post
+	Topic     string // Tópico do post
+	Author    string // Autor do tópico ou do post
+	DeletedAt time.Time
+	DeletedBy string // Vazio se o item foi apagado pelo bbs-admin ou a conta foi removida
+	Reason    string
+}
+
+// moveToTrash marca o item como apagado. Retorna um erro se ele não existir ou já
+// estiver na lixeira.
+func (s *SQLiteStore) moveToTrash(kind TrashKind, id, deletedBy int64, reason string) error {
+	res, err := s.db.Exec(`
+		UPDATE `+kind.table()+` SET deleted_at = CURRENT_TIMESTAMP, deleted_by = NULLIF(?, 0), delete_reason = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, deletedBy, reason, id)
+	if err != nil {
+		return fmt.Errorf("falha ao mover %s para a lixeira: %w", kind.Label(), err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %d não encontrado", kind.Label(), id)
+	}
+	return nil
+}
+
+// GetTrash lista os itens da lixeira, dos apagados mais recentemente aos mais antigos.
+func (s *SQLiteStore) GetTrash() ([]TrashItem, error) {
+	rows, err := s.db.Query(`
+		SELECT 'forum', f.id, f.name, '', '', '', f.deleted_at, COALESCE(d.username, ''), f.delete_reason
+		FROM forums f
+		LEFT JOIN users d ON d.id = f.deleted_by
+		WHERE f.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'topic', t.id, t.title, f.name, '', COALESCE(a.username, ''), t.deleted_at, COALESCE(d.username, ''), t.delete_reason
+		FROM topics t
+		JOIN forums f ON f.id = t.forum_id
+		LEFT JOIN users a ON a.id = t.user_id
+		LEFT JOIN users d ON d.id = t.deleted_by
+		WHERE t.deleted_at IS NOT NULL AND f.deleted_at IS NULL
+		UNION ALL
+		SELECT 'post', p.id, p.content, f.name, t.title, COALESCE(a.username, ''), p.deleted_at, COALESCE(d.username, ''), p.delete_reason
+		FROM posts p
+		JOIN topics t ON t.id = p.topic_id
+		JOIN forums f ON f.id = t.forum_id
+		LEFT JOIN users a ON a.id = p.user_id
+		LEFT JOIN users d ON d.id = p.deleted_by
+		WHERE p.deleted_at IS NOT NULL AND t.deleted_at IS NULL AND f.deleted_at IS NULL
+		ORDER BY 7 DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao listar a lixeira: %w", err)
+	}
+	defer rows.Close()
+
+	var items []TrashItem
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Kind, &item.ID, &item.Title, &item.Forum, &item.Topic, &item.Author, &item.DeletedAt, &item.DeletedBy, &item.Reason); err != nil {
+			return nil, fmt.Errorf("falha ao escanear item da lixeira: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// RestoreTrashItem tira o item da lixeira, junto com os tópicos e posts que foram
+// apagados com ele.
+func (s *SQLiteStore) RestoreTrashItem(kind TrashKind, id int64) error {
+	res, err := s.db.Exec(`
+		UPDATE `+kind.table()+` SET deleted_at = NULL, deleted_by = NULL, delete_reason = ''
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return ErrForumNameTaken
+		}
+		return fmt.Errorf("falha ao restaurar %s: %w", kind.Label(), err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %d não está na lixeira", kind.Label(), id)
+	}
+	return nil
+}
+
+// PurgeTrashItem remove definitivamente um item da lixeira.
+func (s *SQLiteStore) PurgeTrashItem(kind TrashKind, id int64) error {
+	var trashed bool
+	err := s.db.QueryRow("SELECT deleted_at IS NOT NULL FROM "+kind.table()+" WHERE id = ?", id).Scan(&trashed)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("falha ao buscar %s: %w", kind.Label(), err)
+	}
+	if !trashed {
+		return fmt.Errorf("%s %d não está na lixeira", kind.Label(), id)
+	}
+	return s.purge(kind, id)
+}
+
+func (s *SQLiteStore) purge(kind TrashKind, id int64) error {
+	switch kind {
+	case TrashForum:
+		return s.purgeForum(id)
+	case TrashTopic:
+		return s.purgeTopic(int(id))
+	}
+	return s.purgePost(int(id))
+}
+
+// PurgeTrash remove definitivamente os itens apagados antes de before e retorna quantos
+// foram removidos. Os fóruns vêm primeiro, para que os seus tópicos e posts saiam com eles.
+func (s *SQLiteStore) PurgeTrash(before time.Time) (int, error) {
+	purged := 0
+	for _, kind := range []TrashKind{TrashForum, TrashTopic, TrashPost} {
+		rows, err := s.db.Query("SELECT id FROM "+kind.table()+" WHERE deleted_at IS NOT NULL AND deleted_at < ?", sqliteTime(before))
+		if err != nil {
+			return purged, fmt.Errorf("falha ao buscar itens antigos da lixeira: %w", err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return purged, fmt.Errorf("falha ao escanear item da lixeira: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return purged, fmt.Errorf("falha ao buscar itens antigos da lixeira: %w", err)
+		}
+
+		for _, id := range ids {
+			if err := s.purge(kind, id); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
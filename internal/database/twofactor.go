@@ -0,0 +1,210 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hashRecoveryCode calcula o hash guardado de um código de recuperação. Os códigos são
+// aleatórios e longos o bastante para dispensar um hash lento como o das senhas; o
+// código digitado é aceito em minúsculas, com espaços ou sem o hífen.
+func hashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// userIDByName retorna o ID do usuário, ou um erro se ele não existir.
+func (s *SQLiteStore) userIDByName(username string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow("SELECT id FROM users WHERE username = ?", username).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("usuário '%s' não encontrado", username)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("falha ao buscar usuário: %w", err)
+	}
+	return id, nil
+}
+
+// GetTOTPSecret retorna o segredo TOTP do usuário, ou "" se a verificação em duas etapas
+// estiver desativada.
+func (s *SQLiteStore) GetTOTPSecret(username string) (string, error) {
+	var secret sql.NullString
+	err := s.db.QueryRow(`
+		SELECT a.totp_secret
+		FROM user_auth a
+		JOIN users u ON a.user_id = u.id
+		WHERE u.username = ?
+	`, username).Scan(&secret)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("falha ao consultar verificação em duas etapas: %w", err)
+	}
+	return secret.String, nil
+}
+
+// EnableTOTP ativa a verificação em duas etapas com o segredo informado e substitui os
+// códigos de recuperação do usuário, dos quais apenas o hash é guardado.
+func (s *SQLiteStore) EnableTOTP(username, secret string, recoveryCodes []string) error {
+	userID, err := s.userIDByName(username)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO user_auth (user_id, totp_secret, totp_last_step) VALUES (?, ?, 0)
+		ON CONFLICT(user_id) DO UPDATE SET totp_secret = excluded.totp_secret, totp_last_step = 0
+	`, userID, secret)
+	if err != nil {
+		return fmt.Errorf("falha ao ativar verificação em duas etapas: %w", err)
+	}
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// SetRecoveryCodes substitui os códigos de recuperação de quem já ativou a verificação
+// em duas etapas, invalidando os anteriores.
+func (s *SQLiteStore) SetRecoveryCodes(username string, recoveryCodes []string) error {
+	secret, err := s.GetTOTPSecret(username)
+	if err != nil {
+		return err
+	}
+	if secret == "" {
+		return fmt.Errorf("a verificação em duas etapas não está ativa para '%s'", username)
+	}
+	userID, err := s.userIDByName(username)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int64, recoveryCodes []string) error {
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("falha ao remover códigos de recuperação: %w", err)
+	}
+	for _, code := range recoveryCodes {
+		if _, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hashRecoveryCode(code)); err != nil {
+			return fmt.Errorf("falha ao gravar código de recuperação: %w", err)
+		}
+	}
+	return nil
+}
+
+// DisableTOTP desativa a verificação em duas etapas e apaga os códigos de recuperação.
+func (s *SQLiteStore) DisableTOTP(username string) error {
+	userID, err := s.userIDByName(username)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE user_auth SET totp_secret = NULL, totp_last_step = 0 WHERE user_id = ? AND totp_secret IS NOT NULL", userID)
+	if err != nil {
+		return fmt.Errorf("falha ao desativar verificação em duas etapas: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("a verificação em duas etapas não está ativa para '%s'", username)
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("falha ao remover códigos de recuperação: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep registra o intervalo da senha TOTP aceita. Retorna false se ele não for
+// posterior ao último registrado, ou seja, se a senha já tiver sido usada.
+func (s *SQLiteStore) UseTOTPStep(username string, step int64) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE user_auth SET totp_last_step = ?
+		WHERE user_id = (SELECT id FROM users WHERE username = ?) AND totp_secret IS NOT NULL AND totp_last_step < ?
+	`, step, username, step)
+	if err != nil {
+		return false, fmt.Errorf("falha ao registrar senha de uso único: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	return n > 0, nil
+}
+
+// UseRecoveryCode consome um código de recuperação. Retorna false se o código não
+// pertencer ao usuário ou já tiver sido usado.
+func (s *SQLiteStore) UseRecoveryCode(username, code string) (bool, error) {
+	res, err := s.db.Exec(`
+		UPDATE recovery_codes SET used_at = ?
+		WHERE id = (
+			SELECT c.id FROM recovery_codes c JOIN users u ON c.user_id = u.id
+			WHERE u.username = ? AND c.code_hash = ? AND c.used_at IS NULL
+			LIMIT 1
+		)
+	`, storedTime(time.Now()), username, hashRecoveryCode(code))
+	if err != nil {
+		return false, fmt.Errorf("falha ao usar código de recuperação: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CountRecoveryCodes retorna quantos códigos de recuperação do usuário ainda não foram usados.
+func (s *SQLiteStore) CountRecoveryCodes(username string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM recovery_codes c JOIN users u ON c.user_id = u.id
+		WHERE u.username = ? AND c.used_at IS NULL
+	`, username).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao contar códigos de recuperação: %w", err)
+	}
+	return count, nil
+}
@@ -2,20 +2,36 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Situações de uma conta.
+const (
+	UserStatusActive  = "active"
+	UserStatusPending = "pending" // Cadastrada pelo usuário, aguardando aprovação
+)
+
 // User representa um usuário no sistema.
 type User struct {
 	ID        int64
 	Username  string
 	Role      string
+	Email     string // Opcional, informado no cadastro
+	Status    string // UserStatusActive ou UserStatusPending
+	InvitedBy int64  // ID de quem convidou o usuário, ou 0
 	CreatedAt time.Time
 }
 
+// Pending informa se a conta ainda aguarda a aprovação de um administrador.
+func (u *User) Pending() bool {
+	return u.Status == UserStatusPending
+}
+
 // HashPassword gera um hash bcrypt para uma senha.
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
@@ -29,14 +45,14 @@ func CheckPasswordHash(password, hash string) bool {
 }
 
 // CreateUser cria um novo usuário no banco de dados.
-func CreateUser(username, password string) (*User, error) {
+func (s *SQLiteStore) CreateUser(username, password string) (*User, error) {
 	passwordHash, err := HashPassword(password)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao gerar hash da senha: %w", err)
 	}
 
 	query := `INSERT INTO users (username, password_hash) VALUES (?, ?)`
-	res, err := DB.Exec(query, username, passwordHash)
+	res, err := s.db.Exec(query, username, passwordHash)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao criar usuário: %w", err)
 	}
@@ -49,11 +65,121 @@ func CreateUser(username, password string) (*User, error) {
 	return &User{ID: id, Username: username}, nil
 }
 
+// NewAccount reúne os dados de uma conta criada pelo próprio usuário no cadastro.
+type NewAccount struct {
+	Username string
+	Password string
+	Email    string // Opcional
+	Pending  bool   // A conta só pode ser usada depois de aprovada (ver ApproveUser)
+	// InviteCode, se informado, é consumido junto com a criação da conta, e quem criou
+	// o convite fica registrado em InvitedBy.
+	InviteCode string
+}
+
+// ErrUsernameTaken é retornado pelo RegisterUser quando já existe uma conta com o mesmo
+// nome, sem diferenciar maiúsculas de minúsculas.
+var ErrUsernameTaken = errors.New("este nome de usuário já está em uso")
+
+// RegisterUser cria a conta de um usuário que se cadastrou sozinho. Retorna
+// ErrUsernameTaken se o nome já estiver em uso e ErrInvalidInvite se o código de convite
+// não puder ser usado.
+func (s *SQLiteStore) RegisterUser(account NewAccount) (*User, error) {
+	// O nome e o convite são conferidos antes do hash da senha, que é lento.
+	var exists bool
+	if err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = ? COLLATE NOCASE)", account.Username).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("falha ao consultar usuário: %w", err)
+	}
+	if exists {
+		return nil, ErrUsernameTaken
+	}
+
+	// O uso do convite é reservado numa única instrução, então dois cadastros simultâneos
+	// não conseguem ultrapassar o limite de usos. Se a conta não for criada, a reserva é
+	// desfeita.
+	code := normalizeInviteCode(account.InviteCode)
+	var invitedBy sql.NullInt64
+	if account.InviteCode != "" {
+		res, err := s.db.Exec(`
+			UPDATE invites SET uses = uses + 1
+			WHERE code = ? AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)
+		`, code, storedTime(time.Now()))
+		if err != nil {
+			return nil, fmt.Errorf("falha ao usar convite: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("falha ao usar convite: %w", err)
+		} else if n == 0 {
+			return nil, ErrInvalidInvite
+		}
+		if err := s.db.QueryRow("SELECT created_by FROM invites WHERE code = ?", code).Scan(&invitedBy); err != nil {
+			s.releaseInvite(code)
+			return nil, fmt.Errorf("falha ao consultar convite: %w", err)
+		}
+	}
+
+	user, err := s.insertRegisteredUser(account, invitedBy)
+	if err != nil && account.InviteCode != "" {
+		s.releaseInvite(code)
+	}
+	return user, err
+}
+
+// releaseInvite devolve o uso reservado por um cadastro que não foi concluído. Uma falha
+// aqui só deixa o convite com um uso a menos, então não encobre o erro do cadastro.
+func (s *SQLiteStore) releaseInvite(code string) {
+	s.db.Exec("UPDATE invites SET uses = uses - 1 WHERE code = ? AND uses > 0", code)
+}
+
+func (s *SQLiteStore) insertRegisteredUser(account NewAccount, invitedBy sql.NullInt64) (*User, error) {
+	passwordHash, err := HashPassword(account.Password)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar hash da senha: %w", err)
+	}
+
+	status := UserStatusActive
+	if account.Pending {
+		status = UserStatusPending
+	}
+	res, err := s.db.Exec("INSERT INTO users (username, password_hash, email, status, invited_by) VALUES (?, ?, NULLIF(?, ''), ?, ?)",
+		account.Username, passwordHash, account.Email, status, invitedBy)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("falha ao criar usuário: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao obter o ID do usuário: %w", err)
+	}
+
+	return &User{ID: id, Username: account.Username, Role: "user", Email: account.Email, Status: status, InvitedBy: invitedBy.Int64}, nil
+}
+
+// ApproveUser ativa uma conta que aguarda aprovação.
+func (s *SQLiteStore) ApproveUser(username string) error {
+	res, err := s.db.Exec("UPDATE users SET status = ? WHERE username = ? AND status = ?",
+		UserStatusActive, username, UserStatusPending)
+	if err != nil {
+		return fmt.Errorf("falha ao aprovar usuário: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("falha ao verificar linhas afetadas: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("nenhum cadastro pendente para '%s'", username)
+	}
+	return nil
+}
+
 // GetUserByUsername busca um usuário pelo nome de usuário.
 // GetAllUsers busca todos os usuários do sistema.
-func GetAllUsers() ([]User, error) {
-	query := `SELECT id, username, role, created_at FROM users ORDER BY username ASC`
-	rows, err := DB.Query(query)
+func (s *SQLiteStore) GetAllUsers() ([]User, error) {
+	query := `SELECT id, username, role, COALESCE(email, ''), status, COALESCE(invited_by, 0), created_at FROM users ORDER BY username ASC`
+	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao buscar usuários: %w", err)
 	}
@@ -62,7 +188,7 @@ func GetAllUsers() ([]User, error) {
 	var users []User
 	for rows.Next() {
 		var user User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.Role, &user.Email, &user.Status, &user.InvitedBy, &user.CreatedAt); err != nil {
 			return nil, fmt.Errorf("falha ao escanear usuário: %w", err)
 		}
 		users = append(users, user)
@@ -71,14 +197,14 @@ func GetAllUsers() ([]User, error) {
 	return users, nil
 }
 
-func GetUserByUsername(username string) (*User, string, error) {
-	query := `SELECT id, username, password_hash, role, created_at FROM users WHERE username = ?`
-	row := DB.QueryRow(query, username)
+func (s *SQLiteStore) GetUserByUsername(username string) (*User, string, error) {
+	query := `SELECT id, username, password_hash, role, COALESCE(email, ''), status, COALESCE(invited_by, 0), created_at FROM users WHERE username = ?`
+	row := s.db.QueryRow(query, username)
 
 	var user User
 	var passwordHash string
 
-	err := row.Scan(&user.ID, &user.Username, &passwordHash, &user.Role, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &passwordHash, &user.Role, &user.Email, &user.Status, &user.InvitedBy, &user.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, "", nil // Usuário não encontrado
@@ -91,9 +217,9 @@ func GetUserByUsername(username string) (*User, string, error) {
 
 // SetUserRole atualiza o papel de um usuário no banco de dados.
 // UpdateUserPassword verifica a senha atual e atualiza para a nova senha.
-func UpdateUserPassword(username, currentPassword, newPassword string) error {
+func (s *SQLiteStore) UpdateUserPassword(username, currentPassword, newPassword string) error {
 	// 1. Buscar o usuário e o hash da senha atual.
-	user, passwordHash, err := GetUserByUsername(username)
+	user, passwordHash, err := s.GetUserByUsername(username)
 	if err != nil {
 		return fmt.Errorf("falha ao buscar usuário: %w", err)
 	}
@@ -113,7 +239,7 @@ func UpdateUserPassword(username, currentPassword, newPassword string) error {
 	}
 
 	// 4. Atualizar a senha no banco de dados.
-	stmt, err := DB.Prepare("UPDATE users SET password_hash = ? WHERE username = ?")
+	stmt, err := s.db.Prepare("UPDATE users SET password_hash = ? WHERE username = ?")
 	if err != nil {
 		return fmt.Errorf("falha ao preparar statement: %w", err)
 	}
@@ -127,32 +253,74 @@ func UpdateUserPassword(username, currentPassword, newPassword string) error {
 	return nil
 }
 
+// ErrUserHasContent é retornado ao remover um usuário que escreveu tópicos, posts ou
+// mensagens, ou editou posts.
+var ErrUserHasContent = errors.New("o usuário possui tópicos, posts ou mensagens e não pode ser removido")
+
 // DeleteUser remove um usuário do banco de dados.
-func DeleteUser(username string) error {
+func (s *SQLiteStore) DeleteUser(username string) error {
 	// Futuramente, pode ser necessário lidar com o conteúdo do usuário (posts, tópicos).
-	// Por enquanto, a restrição FOREIGN KEY deve prevenir a deleção se houver conteúdo associado.
-	stmt, err := DB.Prepare("DELETE FROM users WHERE username = ?")
+	// Por enquanto, a restrição FOREIGN KEY previne a deleção se houver conteúdo associado.
+	// As chaves SSH e as preferências de autenticação pertencem apenas ao usuário e saem junto com ele.
+	// Tudo acontece em uma transação, para que uma deleção recusada não apague nada.
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("falha ao preparar statement: %w", err)
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
 	}
-	defer stmt.Close()
+	defer tx.Rollback()
 
-	_, err = stmt.Exec(username)
-	if err != nil {
+	if _, err := tx.Exec("DELETE FROM user_keys WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover chaves do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM user_auth WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover preferências do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover códigos de recuperação do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM read_state WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover estado de leitura do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM user_blocks WHERE user_id = (SELECT id FROM users WHERE username = ?) OR blocked_user_id = (SELECT id FROM users WHERE username = ?)", username, username); err != nil {
+		return fmt.Errorf("falha ao remover bloqueios do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM forum_permissions WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover permissões do usuário: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM forum_moderators WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover moderações do usuário: %w", err)
+	}
+	// Os convites do usuário deixam de valer, e as contas que ele convidou deixam de apontar para ele.
+	if _, err := tx.Exec("DELETE FROM invites WHERE created_by = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover convites do usuário: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE users SET invited_by = NULL WHERE invited_by = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao desvincular convidados do usuário: %w", err)
+	}
+	// O usuário sai das conversas; se ele tiver enviado mensagens, a chave estrangeira recusa a deleção.
+	if _, err := tx.Exec("DELETE FROM conversation_participants WHERE user_id = (SELECT id FROM users WHERE username = ?)", username); err != nil {
+		return fmt.Errorf("falha ao remover conversas do usuário: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM users WHERE username = ?", username); err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey {
+			return ErrUserHasContent
+		}
 		return fmt.Errorf("falha ao deletar usuário: %w", err)
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // AdminResetPassword define uma nova senha para um usuário sem verificar a senha antiga.
-func AdminResetPassword(username, newPassword string) error {
+func (s *SQLiteStore) AdminResetPassword(username, newPassword string) error {
 	newPasswordHash, err := HashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("falha ao gerar hash da nova senha: %w", err)
 	}
 
-	stmt, err := DB.Prepare("UPDATE users SET password_hash = ? WHERE username = ?")
+	stmt, err := s.db.Prepare("UPDATE users SET password_hash = ? WHERE username = ?")
 	if err != nil {
 		return fmt.Errorf("falha ao preparar statement: %w", err)
 	}
@@ -166,16 +334,12 @@ func AdminResetPassword(username, newPassword string) error {
 	return nil
 }
 
-func SetUserRole(username, role string) error {
-	// Validação simples do papel
-	switch role {
-	case "user", "moderator", "admin":
-		// O papel é válido, continuar
-	default:
-		return fmt.Errorf("papel inválido: %s", role)
+func (s *SQLiteStore) SetUserRole(username, role string) error {
+	if err := validateRole(role); err != nil {
+		return err
 	}
 
-	stmt, err := DB.Prepare("UPDATE users SET role = ? WHERE username = ?")
+	stmt, err := s.db.Prepare("UPDATE users SET role = ? WHERE username = ?")
 	if err != nil {
 		return fmt.Errorf("falha ao preparar statement: %w", err)
 	}
@@ -197,3 +361,13 @@ func SetUserRole(username, role string) error {
 
 	return nil
 }
+
+// validateRole verifica se o papel é um dos papéis conhecidos.
+func validateRole(role string) error {
+	switch role {
+	case "user", "moderator", "admin":
+		return nil
+	default:
+		return fmt.Errorf("papel inválido: %s", role)
+	}
+}
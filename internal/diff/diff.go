@@ -0,0 +1,74 @@
+// Package diff compara textos linha a linha, para mostrar o que mudou entre duas
+// versões de um post.
+package diff
+
+import "strings"
+
+// Kind indica se a linha é comum às duas versões, só da nova ou só da antiga.
+type Kind int
+
+const (
+	Equal Kind = iota
+	Insert
+	Delete
+)
+
+// Line é uma linha do resultado da comparação.
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Lines retorna as linhas que transformam a em b: as comuns às duas versões, as
+// removidas de a e as inseridas de b, na ordem em que aparecem. As linhas comuns são as
+// da maior subsequência comum; as remoções vêm antes das inserções no mesmo trecho.
+func Lines(a, b string) []Line {
+	x, y := split(a), split(b)
+
+	// lcs[i][j] é o tamanho da maior subsequência comum de x[i:] e y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Delete, x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Insert, y[j]})
+	}
+	return lines
+}
+
+// split separa o texto em linhas, ignorando a quebra de linha final.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"textos vazios", "", "", nil},
+		{"tudo inserido", "", "a\nb", []Line{{Insert, "a"}, {Insert, "b"}}},
+		{"tudo removido", "a\nb", "", []Line{{Delete, "a"}, {Delete, "b"}}},
+		{"textos iguais", "a\nb", "a\nb", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{
+			"inserção no meio", "a\nc", "a\nb\nc",
+			[]Line{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}},
+		},
+		{
+			"remoção no fim", "a\nb\nc", "a\nb",
+			[]Line{{Equal, "a"}, {Equal, "b"}, {Delete, "c"}},
+		},
+		{
+			"linha alterada: a remoção vem antes da inserção", "a\nb\nc", "a\nx\nc",
+			[]Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}},
+		},
+		{
+			"linhas repetidas", "a\na\nb", "a\nb\nb",
+			[]Line{{Equal, "a"}, {Delete, "a"}, {Equal, "b"}, {Insert, "b"}},
+		},
+		{"quebra de linha final ignorada", "a\nb\n", "a\nb", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{
+			"linha em branco é uma linha", "a\n\nb", "a\nb",
+			[]Line{{Equal, "a"}, {Delete, ""}, {Equal, "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, esperado %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package events implementa o barramento de eventos do servidor: as alterações em
+// tópicos e posts são publicadas uma vez e entregues a todas as sessões conectadas,
+// que atualizam as telas abertas sem que o usuário precise sair e voltar.
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// queueSize é o número de eventos pendentes por assinante antes de descartar.
+const queueSize = 256
+
+// Kind identifica o tipo de um evento.
+type Kind int
+
+const (
+	TopicCreated Kind = iota
+	TopicDeleted
+	PostCreated
+	PostDeleted
+	PostEdited
+	ForumDeleted  // O fórum foi movido para a lixeira
+	TrashRestored // Um item saiu da lixeira; os IDs são os do item restaurado
+	TrashPurged   // Itens da lixeira foram removidos definitivamente
+)
+
+// Event descreve uma alteração no conteúdo do BBS. Os campos que não se aplicam ao
+// evento, ou que não são conhecidos por quem o publicou, ficam zerados.
+type Event struct {
+	Kind    Kind
+	ForumID int64
+	TopicID int
+	PostID  int
+	UserID  int // Autor do tópico ou post criado, ou quem editou o post
+	Time    time.Time
+}
+
+// Bus distribui os eventos publicados entre os assinantes.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]bool
+}
+
+// NewBus cria um barramento sem assinantes.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]bool)}
+}
+
+// Subscription é a assinatura de uma sessão no barramento.
+type Subscription struct {
+	bus     *Bus
+	deliver func(Event)
+	queue   chan Event
+	done    chan struct{}
+	close   sync.Once
+}
+
+// Subscribe registra um assinante. deliver é chamada em uma goroutine própria da
+// assinatura, de modo que uma sessão lenta não atrasa quem publica nem as demais.
+func (b *Bus) Subscribe(deliver func(Event)) *Subscription {
+	s := &Subscription{
+		bus:     b,
+		deliver: deliver,
+		queue:   make(chan Event, queueSize),
+		done:    make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.subs[s] = true
+	b.mu.Unlock()
+	go s.run()
+	return s
+}
+
+func (s *Subscription) run() {
+	for {
+		select {
+		case ev := <-s.queue:
+			s.deliver(ev)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Close cancela a assinatura.
+func (s *Subscription) Close() {
+	s.close.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Publish entrega o evento a todos os assinantes sem bloquear; se a fila de um
+// assinante estiver cheia, o evento é descartado para ele.
+func (b *Bus) Publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		select {
+		case s.queue <- ev:
+		default:
+			log.Printf("Eventos: fila cheia, evento %d descartado para um assinante", ev.Kind)
+		}
+	}
+}
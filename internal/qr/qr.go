@@ -0,0 +1,226 @@
+// Package qr gera códigos QR para exibição no terminal, como o da configuração da
+// verificação em duas etapas. Implementa apenas o necessário para isso: modo byte,
+// correção de erros de nível M e as versões 1 a 10 (até 213 bytes).
+package qr
+
+import (
+	"errors"
+)
+
+// ErrTooLong é retornado quando o texto não cabe na maior versão suportada.
+var ErrTooLong = errors.New("texto longo demais para o código QR")
+
+// Code é um código QR pronto para ser desenhado.
+type Code struct {
+	Size    int // Número de módulos em cada lado, sem a margem
+	modules [][]bool
+}
+
+// Dark informa se o módulo na coluna x e na linha y é escuro. Fora do código, os
+// módulos são claros, o que permite desenhar a margem com a mesma função.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// blockLayout descreve os blocos de correção de erros de uma versão no nível M.
+type blockLayout struct {
+	ecPerBlock int
+	groups     [][2]int // Pares {número de blocos, palavras de dados por bloco}
+}
+
+// Tabelas das versões 1 a 10 no nível M (ISO/IEC 18004, tabela 9).
+var (
+	layouts = []blockLayout{
+		{10, [][2]int{{1, 16}}},
+		{16, [][2]int{{1, 28}}},
+		{26, [][2]int{{1, 44}}},
+		{18, [][2]int{{2, 32}}},
+		{24, [][2]int{{2, 43}}},
+		{16, [][2]int{{4, 27}}},
+		{18, [][2]int{{4, 31}}},
+		{22, [][2]int{{2, 38}, {2, 39}}},
+		{22, [][2]int{{3, 36}, {2, 37}}},
+		{26, [][2]int{{4, 43}, {1, 44}}},
+	}
+	alignment = [][]int{
+		nil,
+		{6, 18},
+		{6, 22},
+		{6, 26},
+		{6, 30},
+		{6, 34},
+		{6, 22, 38},
+		{6, 24, 42},
+		{6, 26, 46},
+		{6, 28, 50},
+	}
+)
+
+// Bits do nível M no formato e o valor que o padrão combina com eles por XOR.
+const (
+	levelMBits = 0
+	formatMask = 0x5412
+)
+
+func (l blockLayout) dataCodewords() int {
+	n := 0
+	for _, g := range l.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// Encode gera o código QR do texto, escolhendo a menor versão que o comporta e a
+// máscara de menor penalidade.
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v <= len(layouts); v++ {
+		if 4+countBits(v)+8*len(data) <= 8*layouts[v-1].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version), layouts[version-1])
+
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormat(mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		m.applyMask(mask) // Desfaz, já que o XOR é a própria inversa
+	}
+	m.applyMask(best)
+	m.drawFormat(best)
+
+	return &Code{Size: m.size, modules: m.modules}, nil
+}
+
+// countBits é o tamanho do contador de caracteres do modo byte.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// bitBuffer acumula os bits dos dados, do mais significativo para o menos.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+// encodeData monta as palavras de dados: modo, contador, bytes, terminador e preenchimento.
+func encodeData(data []byte, version int) []byte {
+	capacity := 8 * layouts[version-1].dataCodewords()
+
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits(version))
+	for _, c := range data {
+		bits.append(int(c), 8)
+	}
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	out := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// addErrorCorrection divide os dados em blocos, calcula a correção de cada um e
+// intercala as palavras na ordem em que são gravadas no símbolo.
+func addErrorCorrection(data []byte, layout blockLayout) []byte {
+	divisor := rsDivisor(layout.ecPerBlock)
+
+	var blocks, ecBlocks [][]byte
+	for _, g := range layout.groups {
+		for range g[0] {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			blocks = append(blocks, block)
+			ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+		}
+	}
+
+	var out []byte
+	longest := layout.groups[len(layout.groups)-1][1]
+	for i := range longest {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range layout.ecPerBlock {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// gfMultiply multiplica dois elementos do GF(256) usado pelo QR (polinômio 0x11D).
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor retorna os coeficientes do polinômio gerador de Reed-Solomon de grau n,
+// sem o coeficiente do termo de maior grau, que é sempre 1.
+func rsDivisor(n int) []byte {
+	result := make([]byte, n)
+	result[n-1] = 1
+	root := byte(1)
+	for range n {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < n {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder calcula as palavras de correção de erros de um bloco.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Os vetores de Reed-Solomon são os exemplos publicados de símbolos 1-M: o do anexo I da
+// ISO/IEC 18004 ("01234567") e o do tutorial da Thonky ("HELLO WORLD").
+func TestRSRemainder(t *testing.T) {
+	tests := []struct {
+		name       string
+		data, want []byte
+	}{
+		{
+			"ISO/IEC 18004, anexo I",
+			[]byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11},
+			[]byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55},
+		},
+		{
+			"HELLO WORLD",
+			[]byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rsRemainder(tt.data, rsDivisor(len(tt.want))); !bytes.Equal(got, tt.want) {
+				t.Errorf("rsRemainder = % X, esperado % X", got, tt.want)
+			}
+		})
+	}
+}
+
+// formatBits lê as duas cópias da informação de formato, do bit 0 ao 14.
+func formatBits(m *matrix) (first, second int) {
+	for i := range 15 {
+		var x, y int
+		switch {
+		case i <= 5:
+			x, y = 8, i
+		case i == 6:
+			x, y = 8, 7
+		case i == 7:
+			x, y = 8, 8
+		case i == 8:
+			x, y = 7, 8
+		default:
+			x, y = 14-i, 8
+		}
+		if m.modules[y][x] {
+			first |= 1 << i
+		}
+		if i < 8 {
+			x, y = m.size-1-i, 8
+		} else {
+			x, y = 8, m.size-15+i
+		}
+		if m.modules[y][x] {
+			second |= 1 << i
+		}
+	}
+	return first, second
+}
+
+// A tabela é a da informação de formato do nível M, do anexo C da ISO/IEC 18004.
+func TestDrawFormat(t *testing.T) {
+	want := []int{
+		0b101010000010010,
+		0b101000100100101,
+		0b101111001111100,
+		0b101101101001011,
+		0b100010111111001,
+		0b100000011001110,
+		0b100111110010111,
+		0b100101010100000,
+	}
+	for mask, bits := range want {
+		m := newMatrix(2)
+		m.drawFormat(mask)
+		if first, second := formatBits(m); first != bits || second != bits {
+			t.Errorf("máscara %d: formato = %015b e %015b, esperado %015b", mask, first, second, bits)
+		}
+		if !m.modules[m.size-8][8] {
+			t.Errorf("máscara %d: o módulo escuro fixo está claro", mask)
+		}
+	}
+}
+
+// A informação de versão é a do anexo D da ISO/IEC 18004.
+func TestDrawVersion(t *testing.T) {
+	want := map[int]int{7: 0x07C94, 8: 0x085BC, 9: 0x09A99, 10: 0x0A4D3}
+	for version, bits := range want {
+		m := newMatrix(version)
+		m.drawVersion()
+		var right, bottom int
+		for i := range 18 {
+			a, b := m.size-11+i%3, i/3
+			if m.modules[b][a] {
+				right |= 1 << i
+			}
+			if m.modules[a][b] {
+				bottom |= 1 << i
+			}
+		}
+		if right != bits || bottom != bits {
+			t.Errorf("versão %d: informação = %05X e %05X, esperado %05X", version, right, bottom, bits)
+		}
+	}
+}
+
+// Os símbolos de testdata foram gerados por outro codificador (github.com/skip2/go-qrcode),
+// com a versão e a máscara escolhidas por Encode.
+func TestEncodeGolden(t *testing.T) {
+	tests := []struct {
+		file string
+		text string
+	}{
+		{"v1.txt", "otpauth://totp"},
+		{"v5.txt", "otpauth://totp/modern-bbs:alice?secret=JBSWY3DPEHPK3PXP&issuer=modern-bbs"},
+		{"v7.txt", strings.Repeat("modern-bbs ", 11)},
+		{"v10.txt", strings.Repeat("modern-bbs ", 19) + "bbs!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			golden, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Fields(string(golden))
+
+			code, err := Encode(tt.text)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if code.Size != len(want) {
+				t.Fatalf("Size = %d, esperado %d", code.Size, len(want))
+			}
+			for y, row := range want {
+				var got strings.Builder
+				for x := range code.Size {
+					if code.Dark(x, y) {
+						got.WriteByte('#')
+					} else {
+						got.WriteByte('.')
+					}
+				}
+				if got.String() != row {
+					t.Errorf("linha %d = %s\n         esperado %s", y, got.String(), row)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("a", 213)); err != nil {
+		t.Errorf("Encode de 213 bytes: %v", err)
+	}
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode de 214 bytes = %v, esperado ErrTooLong", err)
+	}
+}
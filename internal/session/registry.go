@@ -0,0 +1,118 @@
+// Package session mantém o registro das sessões conectadas ao servidor, usado pela
+// tela "Quem está online", pelo socket de controle e para desconectar sessões.
+package session
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Info é um retrato de uma sessão em um dado momento.
+type Info struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	RemoteAddr   string    `json:"remote_addr"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	LastActivity time.Time `json:"last_activity"`
+	View         string    `json:"view"`               // Tela atual, ex.: "Home > Fóruns > Geral"
+	ForumID      int64     `json:"forum_id,omitempty"` // Fórum da tela atual; 0 fora dos fóruns
+}
+
+// Session é uma conexão registrada. É seguro usá-la a partir de várias goroutines.
+type Session struct {
+	mu   sync.Mutex
+	info Info
+	kick func()
+}
+
+// ID retorna o identificador da sessão no registro.
+func (s *Session) ID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info.ID
+}
+
+// Touch registra atividade do usuário na tela informada. forumID é o fórum a que a tela
+// pertence, ou 0 se ela estiver fora dos fóruns.
+func (s *Session) Touch(view string, forumID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info.LastActivity = time.Now()
+	s.info.View = view
+	s.info.ForumID = forumID
+}
+
+// Info retorna os dados atuais da sessão.
+func (s *Session) Info() Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info
+}
+
+// Registry guarda as sessões conectadas.
+type Registry struct {
+	mu       sync.RWMutex
+	sessions map[int64]*Session
+	lastID   int64
+}
+
+// NewRegistry cria um registro vazio.
+func NewRegistry() *Registry {
+	return &Registry{sessions: make(map[int64]*Session)}
+}
+
+// Add registra uma nova sessão. kick é chamada para encerrar a conexão quando a
+// sessão for desconectada por um administrador.
+func (r *Registry) Add(username, remoteAddr string, kick func()) *Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	now := time.Now()
+	s := &Session{
+		info: Info{
+			ID:           r.lastID,
+			Username:     username,
+			RemoteAddr:   remoteAddr,
+			ConnectedAt:  now,
+			LastActivity: now,
+		},
+		kick: kick,
+	}
+	r.sessions[s.info.ID] = s
+	return s
+}
+
+// Remove tira a sessão do registro quando a conexão termina.
+func (r *Registry) Remove(s *Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, s.ID())
+}
+
+// List retorna as sessões conectadas, das mais antigas para as mais novas.
+func (r *Registry) List() []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		infos = append(infos, s.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Kick encerra a conexão da sessão informada.
+func (r *Registry) Kick(id int64) error {
+	r.mu.RLock()
+	s, ok := r.sessions[id]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("sessão %d não encontrada", id)
+	}
+	s.kick()
+	return nil
+}
@@ -0,0 +1,65 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/control"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// controlUser é o autor registrado para os avisos enviados pelo bbs-admin.
+const controlUser = "sysop"
+
+// handleControl executa os comandos recebidos pelo socket de controle.
+func (s *Server) handleControl(req control.Request) control.Response {
+	switch req.Command {
+	case "who":
+		return control.Response{Sessions: s.sessions.List()}
+	case "kick":
+		if len(req.Args) != 1 {
+			return control.Response{Error: "uso: kick <id da sessão>"}
+		}
+		id, err := strconv.ParseInt(req.Args[0], 10, 64)
+		if err != nil {
+			return control.Response{Error: fmt.Sprintf("id de sessão inválido: %s", req.Args[0])}
+		}
+		if err := s.sessions.Kick(id); err != nil {
+			return control.Response{Error: err.Error()}
+		}
+		log.Printf("Sessão %d desconectada pelo bbs-admin.", id)
+		return control.Response{}
+	case "broadcast":
+		if err := s.Broadcast(controlUser, strings.Join(req.Args, " ")); err != nil {
+			return control.Response{Error: err.Error()}
+		}
+		return control.Response{Message: fmt.Sprintf("Aviso enviado a %d sessão(ões).", len(s.sessions.List()))}
+	case "shutdown":
+		return s.handleShutdownControl(req.Args)
+	}
+	return control.Response{Error: fmt.Sprintf("comando desconhecido: %s", req.Command)}
+}
+
+// handleShutdownControl trata "shutdown <minutos> [motivo]" e "shutdown cancel".
+func (s *Server) handleShutdownControl(args []string) control.Response {
+	if len(args) == 0 {
+		return control.Response{Error: "uso: shutdown <minutos> [motivo] | shutdown cancel"}
+	}
+	if args[0] == "cancel" {
+		if err := s.CancelShutdown(controlUser); err != nil {
+			return control.Response{Error: err.Error()}
+		}
+		return control.Response{Message: "Desligamento cancelado."}
+	}
+
+	minutes, err := strconv.Atoi(args[0])
+	if err != nil || minutes < 0 {
+		return control.Response{Error: fmt.Sprintf("número de minutos inválido: %s", args[0])}
+	}
+	if err := s.ScheduleShutdown(controlUser, time.Duration(minutes)*time.Minute, strings.Join(args[1:], " ")); err != nil {
+		return control.Response{Error: err.Error()}
+	}
+	at, _, _ := s.PendingShutdown()
+	return control.Response{Message: fmt.Sprintf("Desligamento programado para %s.", at.Format("15:04:05"))}
+}
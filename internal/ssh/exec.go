@@ -0,0 +1,398 @@
+package ssh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"modern-bbs/internal/database"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Códigos de saída devolvidos ao cliente na requisição "exit-status".
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	maxPostBytes = 64 * 1024
+)
+
+// execRequest é o payload de uma requisição "exec" (RFC 4254, seção 6.5).
+type execRequest struct {
+	Command string
+}
+
+// exitStatusRequest é o payload da requisição "exit-status" enviada ao cliente.
+type exitStatusRequest struct {
+	Status uint32
+}
+
+// usageError indica que o comando foi chamado com argumentos inválidos.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+// execContext reúne o que um subcomando precisa para executar.
+type execContext struct {
+	store  database.Store
+	user   *database.User
+	stdin  io.Reader
+	stdout io.Writer
+	json   bool
+}
+
+// execCommand descreve um subcomando do modo não interativo.
+type execCommand struct {
+	usage string
+	desc  string
+	run   func(ctx *execContext, args []string) error
+}
+
+// execCommands retorna a tabela de subcomandos disponíveis via `ssh bbs <comando>`.
+func execCommands() map[string]execCommand {
+	return map[string]execCommand{
+		"forums":   {usage: "forums", desc: "Lista os fóruns", run: execForums},
+		"topics":   {usage: "topics <fórum>", desc: "Lista os tópicos de um fórum (ID ou nome)", run: execTopics},
+		"read":     {usage: "read <tópico>", desc: "Mostra os posts de um tópico", run: execRead},
+		"post":     {usage: "post <tópico>", desc: "Responde a um tópico com o conteúdo lido da entrada padrão", run: execPost},
+		"newtopic": {usage: "newtopic <fórum> <título>", desc: "Cria um tópico (se o fórum permitir)", run: execNewTopic},
+		"whoami":   {usage: "whoami", desc: "Mostra o usuário autenticado e seu papel", run: execWhoami},
+	}
+}
+
+// runExec interpreta e executa um comando recebido via requisição "exec",
+// escrevendo a saída em stdout e os erros em stderr. Retorna o código de saída.
+func runExec(store database.Store, user *database.User, command string, stdin io.Reader, stdout, stderr io.Writer) int {
+	args, err := splitCommandLine(command)
+	if err != nil {
+		fmt.Fprintf(stderr, "erro: %v\n", err)
+		return exitUsage
+	}
+
+	ctx := &execContext{store: store, user: user, stdin: stdin, stdout: stdout}
+	var rest []string
+	for _, arg := range args {
+		if arg == "--json" {
+			ctx.json = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	commands := execCommands()
+	if len(rest) == 0 || rest[0] == "help" {
+		printExecUsage(stdout, commands)
+		if len(rest) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	cmd, ok := commands[rest[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "comando desconhecido: %s\n", rest[0])
+		printExecUsage(stderr, commands)
+		return exitUsage
+	}
+
+	if err := cmd.run(ctx, rest[1:]); err != nil {
+		var usage usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintf(stderr, "uso: %s\n", cmd.usage)
+			return exitUsage
+		}
+		fmt.Fprintf(stderr, "erro: %v\n", err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+func printExecUsage(w io.Writer, commands map[string]execCommand) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Uso: ssh <servidor> <comando> [argumentos] [--json]")
+	fmt.Fprintln(w, "Comandos:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", commands[name].usage, commands[name].desc)
+	}
+	tw.Flush()
+}
+
+// splitCommandLine separa a linha de comando em argumentos, respeitando aspas simples e duplas.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("aspas não fechadas")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+func (ctx *execContext) writeJSON(v any) error {
+	enc := json.NewEncoder(ctx.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type forumOutput struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type topicOutput struct {
+	ID        int       `json:"id"`
+	ForumID   int       `json:"forum_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type postOutput struct {
+	ID        int       `json:"id"`
+	TopicID   int       `json:"topic_id"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func execForums(ctx *execContext, args []string) error {
+	if len(args) != 0 {
+		return usageError{}
+	}
+
+	forums, err := ctx.store.GetVisibleForums(ctx.user.ID)
+	if err != nil {
+		return err
+	}
+
+	if ctx.json {
+		out := make([]forumOutput, 0, len(forums))
+		for _, f := range forums {
+			out = append(out, forumOutput{ID: f.ID, Name: f.Name, Description: f.Description, CreatedAt: f.CreatedAt})
+		}
+		return ctx.writeJSON(out)
+	}
+
+	tw := tabwriter.NewWriter(ctx.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNOME\tDESCRIÇÃO")
+	for _, f := range forums {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", f.ID, f.Name, f.Description)
+	}
+	return tw.Flush()
+}
+
+func execTopics(ctx *execContext, args []string) error {
+	if len(args) != 1 {
+		return usageError{}
+	}
+
+	forum, err := findForum(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	topics, err := ctx.store.GetVisibleTopics(ctx.user.ID, int(forum.ID))
+	if err != nil {
+		return err
+	}
+
+	if ctx.json {
+		out := make([]topicOutput, 0, len(topics))
+		for _, t := range topics {
+			out = append(out, topicOutput{ID: t.ID, ForumID: t.ForumID, Title: t.Title, Author: t.Username, CreatedAt: t.CreatedAt})
+		}
+		return ctx.writeJSON(out)
+	}
+
+	tw := tabwriter.NewWriter(ctx.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTÍTULO\tAUTOR\tCRIADO EM")
+	for _, t := range topics {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", t.ID, t.Title, t.Username, t.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+func execRead(ctx *execContext, args []string) error {
+	if len(args) != 1 {
+		return usageError{}
+	}
+
+	topic, err := findTopic(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	posts, err := ctx.store.GetVisiblePosts(ctx.user.ID, topic.ID)
+	if err != nil {
+		return err
+	}
+
+	if ctx.json {
+		out := make([]postOutput, 0, len(posts))
+		for _, p := range posts {
+			out = append(out, postOutput{ID: p.ID, TopicID: p.TopicID, Author: p.Username, Content: p.Content, CreatedAt: p.CreatedAt})
+		}
+		return ctx.writeJSON(struct {
+			Topic topicOutput  `json:"topic"`
+			Posts []postOutput `json:"posts"`
+		}{
+			Topic: topicOutput{ID: topic.ID, ForumID: topic.ForumID, Title: topic.Title, Author: topic.Username, CreatedAt: topic.CreatedAt},
+			Posts: out,
+		})
+	}
+
+	fmt.Fprintf(ctx.stdout, "%s (por %s)\n\n", topic.Title, topic.Username)
+	for _, p := range posts {
+		fmt.Fprintf(ctx.stdout, "#%d De: %s em %s\n%s\n---\n", p.ID, p.Username, p.CreatedAt.Format("2006-01-02 15:04"), p.Content)
+	}
+	return nil
+}
+
+func execPost(ctx *execContext, args []string) error {
+	if len(args) != 1 {
+		return usageError{}
+	}
+
+	topic, err := findTopic(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(ctx.stdin, maxPostBytes+1))
+	if err != nil {
+		return fmt.Errorf("falha ao ler o conteúdo: %w", err)
+	}
+	if len(body) > maxPostBytes {
+		return fmt.Errorf("o conteúdo excede o limite de %d bytes", maxPostBytes)
+	}
+	content := strings.TrimSpace(string(body))
+	if content == "" {
+		return fmt.Errorf("o conteúdo não pode estar vazio")
+	}
+
+	if err := ctx.store.CreatePost(topic.ID, int(ctx.user.ID), content); err != nil {
+		return fmt.Errorf("falha ao criar post: %w", err)
+	}
+
+	if ctx.json {
+		return ctx.writeJSON(map[string]any{"topic_id": topic.ID, "status": "ok"})
+	}
+	fmt.Fprintf(ctx.stdout, "Post criado em '%s'.\n", topic.Title)
+	return nil
+}
+
+func execNewTopic(ctx *execContext, args []string) error {
+	if len(args) != 2 {
+		return usageError{}
+	}
+
+	forum, err := findForum(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	title := strings.TrimSpace(args[1])
+	if title == "" {
+		return fmt.Errorf("o título não pode estar vazio")
+	}
+
+	if err := ctx.store.CreateTopic(int(forum.ID), int(ctx.user.ID), title); err != nil {
+		return fmt.Errorf("falha ao criar tópico: %w", err)
+	}
+
+	if ctx.json {
+		return ctx.writeJSON(map[string]any{"forum_id": forum.ID, "title": title, "status": "ok"})
+	}
+	fmt.Fprintf(ctx.stdout, "Tópico '%s' criado em '%s'.\n", title, forum.Name)
+	return nil
+}
+
+func execWhoami(ctx *execContext, args []string) error {
+	if len(args) != 0 {
+		return usageError{}
+	}
+
+	if ctx.json {
+		return ctx.writeJSON(map[string]any{"id": ctx.user.ID, "username": ctx.user.Username, "role": ctx.user.Role})
+	}
+	fmt.Fprintf(ctx.stdout, "%s (%s)\n", ctx.user.Username, ctx.user.Role)
+	return nil
+}
+
+// findForum localiza um fórum pelo ID ou, se o argumento não for numérico, pelo nome,
+// entre os que o usuário pode ver.
+func findForum(ctx *execContext, arg string) (*database.Forum, error) {
+	forums, err := ctx.store.GetVisibleForums(ctx.user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, idErr := strconv.ParseInt(arg, 10, 64)
+	for i := range forums {
+		if (idErr == nil && forums[i].ID == id) || strings.EqualFold(forums[i].Name, arg) {
+			return &forums[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("fórum '%s' não encontrado", arg)
+}
+
+// findTopic localiza um tópico pelo ID. Os tópicos dos fóruns que o usuário não pode ver
+// são tratados como inexistentes.
+func findTopic(ctx *execContext, arg string) (*database.Topic, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, usageError{}
+	}
+
+	topic, err := ctx.store.GetVisibleTopic(ctx.user.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if topic == nil {
+		return nil, fmt.Errorf("tópico %d não encontrado", id)
+	}
+
+	return topic, nil
+}
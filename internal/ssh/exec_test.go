@@ -0,0 +1,94 @@
+package ssh
+
+import (
+	"bytes"
+	"encoding/json"
+	"modern-bbs/internal/database"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"forums", []string{"forums"}},
+		{"topics  Geral\t--json", []string{"topics", "Geral", "--json"}},
+		{`newtopic 1 "olá, mundo"`, []string{"newtopic", "1", "olá, mundo"}},
+		{`newtopic 'Fórum Geral' "Título com 'aspas'"`, []string{"newtopic", "Fórum Geral", "Título com 'aspas'"}},
+		{`read ""`, []string{"read", ""}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{"read 1\n", []string{"read", "1"}},
+	}
+	for _, tt := range tests {
+		got, err := splitCommandLine(tt.line)
+		if err != nil {
+			t.Errorf("splitCommandLine(%q): %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommandLine(%q) = %q, esperado %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCommandLineUnclosedQuote(t *testing.T) {
+	for _, line := range []string{`post 1 "sem fim`, `topics 'Geral`} {
+		if _, err := splitCommandLine(line); err == nil {
+			t.Errorf("splitCommandLine(%q) aceitou aspas não fechadas", line)
+		}
+	}
+}
+
+func TestRunExecExitStatus(t *testing.T) {
+	store := database.NewMemoryStore()
+	user, err := store.CreateUser("user", "senha123456")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := store.CreateForum("Geral", "Conversas"); err != nil {
+		t.Fatalf("CreateForum: %v", err)
+	}
+
+	tests := []struct {
+		command string
+		want    int
+	}{
+		{"", exitUsage},
+		{"help", exitOK},
+		{"forums", exitOK},
+		{"forums extra", exitUsage},
+		{"desconhecido", exitUsage},
+		{`topics "Geral`, exitUsage},
+		{"topics Inexistente", exitError},
+		{"newtopic Geral Título", exitError}, // Usuários comuns não criam tópicos por padrão
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if got := runExec(store, user, tt.command, strings.NewReader(""), &stdout, &stderr); got != tt.want {
+			t.Errorf("runExec(%q) = %d, esperado %d (stderr: %q)", tt.command, got, tt.want, stderr.String())
+		}
+	}
+}
+
+func TestRunExecJSON(t *testing.T) {
+	store := database.NewMemoryStore()
+	user, _ := store.CreateUser("user", "senha123456")
+	store.CreateForum("Geral", "Conversas")
+
+	var stdout, stderr bytes.Buffer
+	if code := runExec(store, user, "forums --json", strings.NewReader(""), &stdout, &stderr); code != exitOK {
+		t.Fatalf("runExec = %d: %s", code, stderr.String())
+	}
+	var forums []forumOutput
+	if err := json.Unmarshal(stdout.Bytes(), &forums); err != nil {
+		t.Fatalf("saída JSON inválida: %v\n%s", err, stdout.String())
+	}
+	if len(forums) != 1 || forums[0].Name != "Geral" || forums[0].Description != "Conversas" {
+		t.Errorf("forums --json = %+v", forums)
+	}
+}
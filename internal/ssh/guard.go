@@ -0,0 +1,171 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/database"
+	"net"
+	"sync"
+	"time"
+)
+
+// Política de bloqueio do login por senha. Cada falha acima das toleradas bloqueia o
+// alvo por baseLockout, dobrando a cada nova falha até maxLockout. Uma falha que chega
+// depois de failureWindow sem falhas nem bloqueio recomeça a contagem.
+const (
+	baseLockout   = 30 * time.Second
+	maxLockout    = time.Hour
+	failureWindow = time.Hour
+	purgeInterval = time.Hour
+)
+
+// Falhas toleradas antes do primeiro bloqueio. O limite por IP é maior porque vários
+// usuários podem compartilhar o mesmo endereço (NAT). No cadastro, toda tentativa conta.
+var freeLoginFailures = map[string]int{
+	database.LockoutScopeIP:       10,
+	database.LockoutScopeUser:     5,
+	database.LockoutScopeRegister: 5,
+}
+
+// Limites do handshake SSH: conexões que ainda não se autenticaram e o tempo que cada
+// uma tem para concluir a autenticação.
+const (
+	maxHandshakes    = 32
+	handshakeTimeout = 30 * time.Second
+)
+
+// loginGuard aplica a política de bloqueio às tentativas de login por senha. As falhas
+// ficam no Store, então sobrevivem a reinícios e podem ser limpas pelo bbs-admin.
+type loginGuard struct {
+	store database.Store
+
+	mu        sync.Mutex // Serializa a leitura e a gravação das falhas
+	lastPurge time.Time
+}
+
+func newLoginGuard(store database.Store) *loginGuard {
+	return &loginGuard{store: store}
+}
+
+// remoteIP retorna o endereço de origem sem a porta.
+func remoteIP(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// loginTarget identifica um alvo das falhas de login.
+type loginTarget struct {
+	scope, target string
+}
+
+// loginTargets retorna os alvos de uma tentativa: o endereço de origem e o nome de usuário.
+func loginTargets(ip, username string) []loginTarget {
+	return []loginTarget{{database.LockoutScopeIP, ip}, {database.LockoutScopeUser, username}}
+}
+
+// check retorna um erro se o endereço ou o usuário estiverem bloqueados. É chamada antes
+// da verificação da senha, para que tentativas bloqueadas não custem um bcrypt.
+func (g *loginGuard) check(ip, username string) error {
+	now := time.Now()
+	for _, t := range loginTargets(ip, username) {
+		l, err := g.store.GetLockout(t.scope, t.target)
+		if err != nil {
+			return err
+		}
+		if l != nil && l.Locked(now) {
+			return fmt.Errorf("muitas tentativas de login; tente novamente em %s",
+				l.LockedUntil.Sub(now).Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// fail registra uma senha incorreta para o endereço e para o usuário.
+func (g *loginGuard) fail(ip, username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range loginTargets(ip, username) {
+		if err := g.recordFailure(t.scope, t.target, now); err != nil {
+			log.Printf("Erro ao registrar falha de login (%s %s): %v", t.scope, t.target, err)
+		}
+	}
+
+	if now.Sub(g.lastPurge) >= purgeInterval {
+		g.lastPurge = now
+		if _, err := g.store.PurgeLockouts(now.Add(-failureWindow)); err != nil {
+			log.Printf("Erro ao expurgar falhas de login antigas: %v", err)
+		}
+	}
+}
+
+func (g *loginGuard) recordFailure(scope, target string, now time.Time) error {
+	l, err := g.store.GetLockout(scope, target)
+	if err != nil {
+		return err
+	}
+	if l == nil || now.Sub(lastActivity(l)) > failureWindow {
+		l = &database.Lockout{Scope: scope, Target: target}
+	}
+	l.Failures++
+	l.LastFailureAt = now
+
+	if excess := l.Failures - freeLoginFailures[scope]; excess > 0 {
+		until := now.Add(lockoutDuration(excess))
+		l.LockedUntil = &until
+		log.Printf("Login bloqueado para %s %s após %d falha(s), até %s.", scope, target, l.Failures, until.Format("15:04:05"))
+	}
+	return g.store.SaveLockout(l)
+}
+
+// lastActivity retorna o fim do último bloqueio ou a última falha, o que for mais recente.
+func lastActivity(l *database.Lockout) time.Time {
+	if l.LockedUntil != nil && l.LockedUntil.After(l.LastFailureAt) {
+		return *l.LockedUntil
+	}
+	return l.LastFailureAt
+}
+
+// lockoutDuration retorna a duração do n-ésimo bloqueio seguido (a partir de 1).
+func lockoutDuration(n int) time.Duration {
+	d := baseLockout
+	for i := 1; i < n && d < maxLockout; i++ {
+		d *= 2
+	}
+	return min(d, maxLockout)
+}
+
+// register registra uma tentativa de cadastro do endereço, ou retorna um erro se os
+// cadastros dele estiverem bloqueados. É chamada antes do RegisterUser, para limitar o
+// custo do bcrypt e a criação de contas em massa.
+func (g *loginGuard) register(ip string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	l, err := g.store.GetLockout(database.LockoutScopeRegister, ip)
+	if err != nil {
+		return err
+	}
+	if l != nil && l.Locked(now) {
+		return fmt.Errorf("muitas tentativas de cadastro; tente novamente em %s",
+			l.LockedUntil.Sub(now).Round(time.Second))
+	}
+	return g.recordFailure(database.LockoutScopeRegister, ip, now)
+}
+
+// succeed apaga as falhas do usuário depois de um login por senha válido. As falhas do
+// endereço só expiram com o tempo: do contrário, quem tem uma conta poderia zerá-las
+// entre as tentativas contra as contas dos outros.
+func (g *loginGuard) succeed(username string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := g.store.DeleteLockout(database.LockoutScopeUser, username); err != nil {
+		log.Printf("Erro ao limpar falhas de login (%s %s): %v", database.LockoutScopeUser, username, err)
+	}
+}
@@ -0,0 +1,119 @@
+package ssh
+
+import (
+	"modern-bbs/internal/database"
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{1, baseLockout},
+		{2, 2 * baseLockout},
+		{3, 4 * baseLockout},
+		{7, 64 * baseLockout},
+		{8, maxLockout}, // 128 × 30s passaria de uma hora
+		{100, maxLockout},
+	}
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.n); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, esperado %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRecordFailureBackoff(t *testing.T) {
+	g := newLoginGuard(database.NewMemoryStore())
+	now := time.Now().UTC().Truncate(time.Second)
+	free := freeLoginFailures[database.LockoutScopeUser]
+
+	for i := 1; i <= free; i++ {
+		if err := g.recordFailure(database.LockoutScopeUser, "user", now); err != nil {
+			t.Fatalf("recordFailure: %v", err)
+		}
+	}
+	if err := g.check("192.0.2.1", "user"); err != nil {
+		t.Fatalf("bloqueado após %d falhas toleradas: %v", free, err)
+	}
+
+	// Cada falha acima das toleradas dobra o bloqueio, contado a partir dela.
+	for excess := 1; excess <= 3; excess++ {
+		if err := g.recordFailure(database.LockoutScopeUser, "user", now); err != nil {
+			t.Fatalf("recordFailure: %v", err)
+		}
+		l, _ := g.store.GetLockout(database.LockoutScopeUser, "user")
+		if l.Failures != free+excess {
+			t.Errorf("Failures = %d, esperado %d", l.Failures, free+excess)
+		}
+		if want := now.Add(lockoutDuration(excess)); l.LockedUntil == nil || !l.LockedUntil.Equal(want) {
+			t.Errorf("LockedUntil após %d falha(s) a mais = %v, esperado %s", excess, l.LockedUntil, want)
+		}
+	}
+}
+
+func TestRecordFailureWindow(t *testing.T) {
+	g := newLoginGuard(database.NewMemoryStore())
+	start := time.Now().UTC().Truncate(time.Second)
+	free := freeLoginFailures[database.LockoutScopeUser]
+
+	for i := 0; i <= free; i++ {
+		g.recordFailure(database.LockoutScopeUser, "user", start)
+	}
+
+	// A janela conta a partir do fim do bloqueio, e não da última falha.
+	l, _ := g.store.GetLockout(database.LockoutScopeUser, "user")
+	inside := l.LockedUntil.Add(failureWindow - time.Second)
+	g.recordFailure(database.LockoutScopeUser, "user", inside)
+	if l, _ := g.store.GetLockout(database.LockoutScopeUser, "user"); l.Failures != free+2 {
+		t.Errorf("Failures dentro da janela = %d, esperado %d", l.Failures, free+2)
+	}
+
+	l, _ = g.store.GetLockout(database.LockoutScopeUser, "user")
+	after := l.LockedUntil.Add(failureWindow + time.Second)
+	g.recordFailure(database.LockoutScopeUser, "user", after)
+	l, _ = g.store.GetLockout(database.LockoutScopeUser, "user")
+	if l.Failures != 1 || l.LockedUntil != nil {
+		t.Errorf("depois da janela = %+v, esperado a contagem recomeçada", l)
+	}
+}
+
+func TestSucceedKeepsIPFailures(t *testing.T) {
+	g := newLoginGuard(database.NewMemoryStore())
+	g.fail("192.0.2.1", "user")
+	g.succeed("user")
+
+	if l, _ := g.store.GetLockout(database.LockoutScopeUser, "user"); l != nil {
+		t.Errorf("as falhas do usuário continuaram após o login: %+v", l)
+	}
+	if l, _ := g.store.GetLockout(database.LockoutScopeIP, "192.0.2.1"); l == nil || l.Failures != 1 {
+		t.Errorf("as falhas do endereço = %+v, esperado 1", l)
+	}
+}
+
+func TestRegisterThrottle(t *testing.T) {
+	g := newLoginGuard(database.NewMemoryStore())
+	free := freeLoginFailures[database.LockoutScopeRegister]
+
+	for i := 1; i <= free; i++ {
+		if err := g.register("192.0.2.1"); err != nil {
+			t.Fatalf("cadastro %d recusado: %v", i, err)
+		}
+	}
+	// A tentativa seguinte ainda passa, mas bloqueia as próximas.
+	if err := g.register("192.0.2.1"); err != nil {
+		t.Fatalf("cadastro %d recusado: %v", free+1, err)
+	}
+	if err := g.register("192.0.2.1"); err == nil {
+		t.Error("cadastro aceito durante o bloqueio")
+	}
+	if err := g.register("192.0.2.2"); err != nil {
+		t.Errorf("o bloqueio de um endereço recusou outro: %v", err)
+	}
+	// Os cadastros não contam como falhas de login do endereço.
+	if err := g.check("192.0.2.1", "user"); err != nil {
+		t.Errorf("check após os cadastros: %v", err)
+	}
+}
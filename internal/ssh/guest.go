@@ -0,0 +1,87 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/database"
+	"modern-bbs/pkg/tui"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultGuestLimit é o número de sessões de visitante simultâneas por endereço IP quando
+// BBS_GUEST_LIMIT não está definida.
+const DefaultGuestLimit = 2
+
+// guestExtension marca, nas permissões da conexão, as sessões de visitante.
+const guestExtension = "guest"
+
+// guestAuth aceita o login de visitante sem autenticação. O limite por endereço é
+// aplicado depois do handshake, em acquireGuest, quando a conexão já é contada.
+func (s *Server) guestAuth(c ssh.ConnMetadata) (*ssh.Permissions, error) {
+	log.Printf("Visitante conectando a partir de %s.", c.RemoteAddr())
+	return &ssh.Permissions{Extensions: map[string]string{guestExtension: "1"}}, nil
+}
+
+// isGuest informa se a conexão é do login de visitante.
+func isGuest(conn *ssh.ServerConn) bool {
+	return conn.Permissions != nil && conn.Permissions.Extensions[guestExtension] != ""
+}
+
+// guestUser é o usuário sem conta das sessões de visitante, com o papel somente leitura.
+func (s *Server) guestUser() *database.User {
+	return &database.User{Username: s.GuestUser, Role: tui.GuestRole, Status: database.UserStatusActive}
+}
+
+// acquireGuest ocupa uma das vagas de visitante do endereço. Retorna false se todas
+// estiverem em uso.
+func (s *Server) acquireGuest(ip string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.guests[ip] >= s.GuestLimit {
+		return false
+	}
+	s.guests[ip]++
+	return true
+}
+
+// releaseGuest libera a vaga ocupada por acquireGuest.
+func (s *Server) releaseGuest(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.guests[ip]--; s.guests[ip] <= 0 {
+		delete(s.guests, ip)
+	}
+}
+
+// rejectGuest recusa as sessões de uma conexão de visitante acima do limite. A sessão é
+// aberta apenas para mostrar o motivo, que o cliente SSH não exibe em uma falha de
+// autenticação ou na recusa do canal.
+func (s *Server) rejectGuest(newChannels <-chan ssh.NewChannel) {
+	for newChannel := range newChannels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "tipo de canal desconhecido")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				switch req.Type {
+				case "pty-req", "env":
+					req.Reply(true, nil)
+				case "shell", "exec":
+					req.Reply(true, nil)
+					fmt.Fprintf(channel.Stderr(), "O limite de %d sessões de visitante por endereço foi atingido. Tente novamente mais tarde.\r\n", s.GuestLimit)
+					channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: 1}))
+					return
+				default:
+					req.Reply(false, nil)
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,45 @@
+package ssh
+
+import "sort"
+
+// ptyRequest é o payload de uma requisição "pty-req" (RFC 4254, seção 6.2).
+type ptyRequest struct {
+	Term     string
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+	Modes    string
+}
+
+// windowChangeRequest é o payload de uma requisição "window-change" (RFC 4254, seção 6.7).
+type windowChangeRequest struct {
+	Columns  uint32
+	Rows     uint32
+	WidthPx  uint32
+	HeightPx uint32
+}
+
+// envRequest é o payload de uma requisição "env" (RFC 4254, seção 6.4).
+type envRequest struct {
+	Name  string
+	Value string
+}
+
+// sessionEnv guarda as variáveis de ambiente informadas pelo cliente SSH.
+// Implementa termenv.Environ para que o perfil de cores seja detectado a partir
+// do terminal do cliente, e não das variáveis do processo do servidor.
+type sessionEnv map[string]string
+
+func (e sessionEnv) Getenv(key string) string {
+	return e[key]
+}
+
+func (e sessionEnv) Environ() []string {
+	environ := make([]string, 0, len(e))
+	for k, v := range e {
+		environ = append(environ, k+"="+v)
+	}
+	sort.Strings(environ)
+	return environ
+}
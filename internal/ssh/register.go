@@ -0,0 +1,76 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/database"
+	"modern-bbs/internal/session"
+	"modern-bbs/pkg/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultRegisterUser é o login de cadastro quando BBS_REGISTER_USER não está definida.
+const DefaultRegisterUser = "new"
+
+// registerExtension marca, nas permissões da conexão, as sessões do login de cadastro.
+const registerExtension = "register"
+
+// noClientAuth aceita sem autenticação apenas o login de visitante e o login de cadastro,
+// quando habilitados. Os demais usuários seguem para a senha ou a chave pública.
+func (s *Server) noClientAuth(c ssh.ConnMetadata) (*ssh.Permissions, error) {
+	if s.GuestUser != "" && c.User() == s.GuestUser {
+		return s.guestAuth(c)
+	}
+	if s.Registration == nil || s.RegisterUser == "" || c.User() != s.RegisterUser {
+		return nil, fmt.Errorf("autenticação necessária")
+	}
+	log.Printf("Cadastro iniciado a partir de %s.", c.RemoteAddr())
+	return &ssh.Permissions{Extensions: map[string]string{registerExtension: "1"}}, nil
+}
+
+// isRegistration informa se a conexão é do login de cadastro.
+func isRegistration(conn *ssh.ServerConn) bool {
+	return conn.Permissions != nil && conn.Permissions.Extensions[registerExtension] != ""
+}
+
+// runRegistration executa o formulário de cadastro na sessão. O cadastro precisa de um
+// terminal, então comandos via ssh exec são recusados.
+func (s *Server) runRegistration(sshConn *ssh.ServerConn, sess *session.Session, channel ssh.Channel, env sessionEnv, interactive bool) {
+	if !interactive {
+		fmt.Fprintf(channel.Stderr(), "O cadastro é interativo: conecte-se com ssh -t %s@<servidor>.\n", s.RegisterUser)
+		channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: 1}))
+		return
+	}
+	sess.Touch("Cadastro", 0)
+
+	config := *s.Registration
+	config.Reserved = append([]string{s.RegisterUser, controlUser}, config.Reserved...)
+	if s.GuestUser != "" {
+		config.Reserved = append(config.Reserved, s.GuestUser)
+	}
+	remoteAddr := sshConn.RemoteAddr()
+	config.Throttle = func() error {
+		return s.guard.register(remoteIP(remoteAddr))
+	}
+	config.OnRegister = func(user *database.User) {
+		if user.Pending() {
+			log.Printf("Nova conta cadastrada a partir de %s: '%s' (aguardando aprovação).", remoteAddr, user.Username)
+		} else {
+			log.Printf("Nova conta cadastrada a partir de %s: '%s'.", remoteAddr, user.Username)
+		}
+	}
+
+	renderer := lipgloss.NewRenderer(channel, termenv.WithEnvironment(env), termenv.WithTTY(true))
+	p := tea.NewProgram(tui.NewRegistrationModel(s.store, config, renderer),
+		tea.WithInput(channel), tea.WithOutput(channel), tea.WithEnvironment(env.Environ()), tea.WithoutSignalHandler())
+	s.addProgram(p)
+	defer s.removeProgram(p)
+
+	if _, err := p.Run(); err != nil {
+		log.Printf("Erro ao executar o cadastro para %s: %v", remoteAddr, err)
+	}
+}
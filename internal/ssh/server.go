@@ -1,44 +1,143 @@
 package ssh
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"log"
+	"modern-bbs/internal/chat"
+	"modern-bbs/internal/control"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
+	"modern-bbs/internal/session"
 	"modern-bbs/pkg/tui"
 	"net"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"golang.org/x/crypto/ssh"
 )
 
 // Server representa o servidor SSH do BBS.
 type Server struct {
-	Addr   string
-	config *ssh.ServerConfig
+	Addr string
+	// ControlSocket é o caminho do socket de controle usado pelo bbs-admin. Vazio desabilita o socket.
+	ControlSocket string
+	// RegisterUser é o login que abre o cadastro de novas contas sem autenticação (ex.:
+	// ssh new@host), com a política de Registration. Registration nil desabilita o cadastro.
+	RegisterUser string
+	Registration *tui.Registration
+	// GuestUser é o login de visitante, que entra sem autenticação e só lê os fóruns abertos
+	// aos visitantes. Vazio desabilita o acesso de visitante. GuestLimit limita as sessões de
+	// visitante simultâneas de um mesmo endereço IP.
+	GuestUser  string
+	GuestLimit int
+	// TwoFactorRoles são os papéis que precisam ativar a verificação em duas etapas para
+	// usar o BBS. Quem já a ativou passa por ela no login, qualquer que seja o papel.
+	TwoFactorRoles []string
+	// EditWindow é o prazo, contado da publicação, em que os autores podem editar os
+	// próprios posts. Zero não limita o prazo; os moderadores editam a qualquer momento.
+	EditWindow time.Duration
+
+	store    database.Store
+	config   *ssh.ServerConfig
+	guard    *loginGuard       // Bloqueio das tentativas de login com senha ou código incorretos
+	chat     *chat.Hub         // Salas de chat compartilhadas por todas as sessões
+	events   *events.Bus       // Alterações de tópicos e posts, entregues às telas abertas
+	sessions *session.Registry // Sessões conectadas
+
+	mu       sync.Mutex
+	programs map[*tea.Program]bool // Programas das sessões interativas, que recebem os avisos
+	guests   map[string]int        // Sessões de visitante abertas por endereço IP
+	shutdown *pendingShutdown
+	notified bool // As sessões já receberam o aviso de desconexão
+	listener net.Listener
+	stopping bool
+	conns    map[net.Conn]bool // Conexões abertas, fechadas à força se o desligamento expirar
+	wg       sync.WaitGroup
+
+	handshakes chan struct{} // Vagas para conexões ainda não autenticadas
 }
 
-// NewServer cria e configura uma nova instância do servidor SSH.
-func NewServer(addr string) (*Server, error) {
+// NewServer cria e configura uma nova instância do servidor SSH sobre o Store informado.
+func NewServer(addr string, store database.Store) (*Server, error) {
+	// As alterações feitas por qualquer sessão, inclusive via ssh exec, são publicadas no barramento.
+	bus := events.NewBus()
+	store = database.WithEvents(store, bus)
+	guard := newLoginGuard(store)
+
+	// Os callbacks concluem a autenticação com s.secondFactor; s é criado logo abaixo.
+	var s *Server
 	config := &ssh.ServerConfig{
 		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
-			user, passwordHash, err := database.GetUserByUsername(c.User())
+			// Endereços e usuários bloqueados são recusados antes do bcrypt, que é caro.
+			ip := remoteIP(c.RemoteAddr())
+			if err := guard.check(ip, c.User()); err != nil {
+				log.Printf("Tentativa de login bloqueada para '%s' de %s: %v", c.User(), ip, err)
+				return nil, err
+			}
+
+			user, passwordHash, err := store.GetUserByUsername(c.User())
 			if err != nil {
 				log.Printf("Erro ao buscar usuário '%s': %v", c.User(), err)
 				return nil, fmt.Errorf("erro interno do servidor")
 			}
 
+			// Com o login por senha desabilitado, a senha nem é comparada: a resposta é a
+			// mesma de uma senha errada, para não confirmar uma senha que ainda esteja certa.
+			if user != nil {
+				disabled, err := store.IsPasswordLoginDisabled(user.Username)
+				if err != nil {
+					log.Printf("Erro ao verificar login por senha de '%s': %v", c.User(), err)
+					return nil, fmt.Errorf("erro interno do servidor")
+				}
+				if disabled {
+					log.Printf("Login por senha desabilitado para o usuário: %s", c.User())
+					return nil, fmt.Errorf("usuário ou senha inválidos")
+				}
+			}
+
 			if user == nil || !database.CheckPasswordHash(string(pass), passwordHash) {
 				log.Printf("Falha na autenticação para o usuário: %s", c.User())
+				guard.fail(ip, c.User())
 				return nil, fmt.Errorf("usuário ou senha inválidos")
 			}
 
-			log.Printf("Usuário '%s' autenticado com sucesso.", c.User())
-			return nil, nil // Autenticação bem-sucedida
+			// Com a verificação em duas etapas, as falhas só são limpas depois do código.
+			return s.secondFactor(c, nil, func() {
+				guard.succeed(c.User())
+				log.Printf("Usuário '%s' autenticado com sucesso.", c.User())
+			})
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, pubKey ssh.PublicKey) (*ssh.Permissions, error) {
+			key, err := store.FindUserKey(c.User(), pubKey)
+			if err != nil {
+				log.Printf("Erro ao buscar chaves do usuário '%s': %v", c.User(), err)
+				return nil, fmt.Errorf("erro interno do servidor")
+			}
+			if key == nil {
+				// O cliente costuma oferecer várias chaves; só as recusadas não são erro.
+				return nil, fmt.Errorf("chave não autorizada")
+			}
+
+			// O callback também é chamado quando o cliente apenas consulta se a chave é aceita,
+			// por isso o uso da chave só é registrado após o handshake (ver handleConnection).
+			return s.secondFactor(c, &ssh.Permissions{
+				Extensions: map[string]string{
+					"pubkey-id": strconv.FormatInt(key.ID, 10),
+					"pubkey-fp": key.Fingerprint,
+				},
+			}, nil)
 		},
 	}
 
@@ -48,33 +147,177 @@ func NewServer(addr string) (*Server, error) {
 	}
 	config.AddHostKey(signer)
 
-	return &Server{
-		Addr:   addr,
-		config: config,
-	}, nil
+	s = &Server{
+		Addr:     addr,
+		store:    store,
+		config:   config,
+		guard:    guard,
+		chat:     chat.NewHub(store),
+		events:   bus,
+		sessions: session.NewRegistry(),
+		programs: make(map[*tea.Program]bool),
+		guests:   make(map[string]int),
+		conns:    make(map[net.Conn]bool),
+
+		handshakes: make(chan struct{}, maxHandshakes),
+
+		GuestLimit: DefaultGuestLimit,
+		EditWindow: tui.DefaultEditWindow,
+	}
+
+	// O método "none" só é aceito para os logins de visitante e de cadastro (ver noClientAuth).
+	config.NoClientAuth = true
+	config.NoClientAuthCallback = s.noClientAuth
+
+	return s, nil
 }
 
-// ListenAndServe inicia o listener e serve as conexões SSH.
-func (s *Server) ListenAndServe() error {
+// ErrServerClosed é retornado por ListenAndServe depois que o servidor é desligado.
+var ErrServerClosed = errors.New("servidor SSH encerrado")
+
+// ListenAndServe inicia o listener e serve as conexões SSH até que ctx seja cancelado
+// ou o servidor seja desligado, quando retorna ErrServerClosed. As sessões abertas
+// continuam em andamento; Shutdown avisa os usuários e espera que elas terminem.
+func (s *Server) ListenAndServe(ctx context.Context) error {
 	listener, err := net.Listen("tcp", s.Addr)
 	if err != nil {
 		return fmt.Errorf("falha ao escutar em %s: %w", s.Addr, err)
 	}
 
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	if s.ControlSocket != "" {
+		controlListener, err := control.Listen(s.ControlSocket)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		defer controlListener.Close()
+		log.Printf("Socket de controle em %s", s.ControlSocket)
+		go control.Serve(controlListener, s.handleControl)
+	}
+
+	// O cancelamento do contexto apenas interrompe o Accept abaixo.
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.closeListener()
+		case <-stopped:
+		}
+	}()
+
 	for {
 		nConn, err := listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return ErrServerClosed
+			}
 			log.Printf("Falha ao aceitar conexão: %v", err)
 			continue
 		}
 
-		go s.handleConnection(nConn)
+		// Com todas as vagas de handshake ocupadas, novas conexões são recusadas de imediato,
+		// para que um scanner não consiga acumular autenticações pendentes.
+		select {
+		case s.handshakes <- struct{}{}:
+		default:
+			log.Printf("Limite de %d handshakes simultâneos atingido; recusando %s.", maxHandshakes, nConn.RemoteAddr())
+			nConn.Close()
+			continue
+		}
+
+		// A conexão só é registrada se o servidor não estiver sendo desligado, para que
+		// Shutdown não espere por conexões aceitas depois do início da espera.
+		s.mu.Lock()
+		if s.stopping {
+			s.mu.Unlock()
+			<-s.handshakes
+			nConn.Close()
+			continue
+		}
+		s.conns[nConn] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, nConn)
+				s.mu.Unlock()
+				s.wg.Done()
+			}()
+			s.handleConnection(nConn)
+		}()
+	}
+}
+
+// closeListener faz o servidor parar de aceitar conexões.
+func (s *Server) closeListener() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopping = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+}
+
+// forcedCloseWait é quanto Shutdown espera os handlers terminarem depois de fechar as
+// conexões à força.
+const forcedCloseWait = 2 * time.Second
+
+// Shutdown desliga o servidor: para de aceitar conexões, pede às sessões interativas
+// que se despeçam dos usuários e espera que todas as conexões terminem. Se ctx expirar
+// antes, as conexões restantes são fechadas, os handlers têm mais forcedCloseWait para
+// terminar e o erro de ctx é retornado.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeListener()
+	s.disconnectAll("O servidor está sendo desligado. Até logo!")
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		log.Printf("Prazo do desligamento esgotado; fechando %d conexão(ões).", len(s.conns))
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+
+		// Os handlers ainda podem estar usando o Store, que o chamador fecha em seguida.
+		select {
+		case <-done:
+		case <-time.After(forcedCloseWait):
+			log.Printf("Algumas sessões não terminaram após o fechamento das conexões.")
+		}
+		return ctx.Err()
 	}
 }
 
 func (s *Server) handleConnection(nConn net.Conn) {
 	defer nConn.Close()
+
+	// A vaga de handshake ocupada em ListenAndServe é liberada quando a autenticação
+	// termina, com sucesso ou não; o prazo impede que uma conexão ociosa a segure.
+	nConn.SetDeadline(time.Now().Add(handshakeTimeout))
 	sshConn, newChannels, reqs, err := ssh.NewServerConn(nConn, s.config)
+	<-s.handshakes
+	nConn.SetDeadline(time.Time{})
 	if err != nil {
 		// Este erro ocorre se a autenticação falhar, o que já é logado no callback.
 		// log.Printf("Falha no handshake SSH para %s: %v", nConn.RemoteAddr(), err)
@@ -82,16 +325,42 @@ func (s *Server) handleConnection(nConn net.Conn) {
 	}
 	log.Printf("Login bem-sucedido para %s (%s)", sshConn.User(), sshConn.RemoteAddr())
 
+	if isGuest(sshConn) {
+		ip := remoteIP(sshConn.RemoteAddr())
+		if !s.acquireGuest(ip) {
+			log.Printf("Visitante recusado de %s: limite de %d sessões por endereço.", ip, s.GuestLimit)
+			go ssh.DiscardRequests(reqs)
+			s.rejectGuest(newChannels)
+			return
+		}
+		defer s.releaseGuest(ip)
+	}
+
+	if sshConn.Permissions != nil {
+		if keyID, ok := sshConn.Permissions.Extensions["pubkey-id"]; ok {
+			log.Printf("Usuário '%s' autenticado com a chave %s.", sshConn.User(), sshConn.Permissions.Extensions["pubkey-fp"])
+			if id, err := strconv.ParseInt(keyID, 10, 64); err == nil {
+				if err := s.store.TouchUserKey(id); err != nil {
+					log.Printf("Erro ao registrar uso da chave: %v", err)
+				}
+			}
+		}
+	}
+
+	// Registra a conexão para a lista de quem está online; desconectar a sessão fecha a conexão SSH.
+	sess := s.sessions.Add(sshConn.User(), sshConn.RemoteAddr().String(), func() { sshConn.Close() })
+	defer s.sessions.Remove(sess)
+
 	// Descarte de requisições globais que não nos interessam.
 	go ssh.DiscardRequests(reqs)
 
 	// Aceita e lida com novos canais (sessões).
 	for newChannel := range newChannels {
-		go s.handleChannel(sshConn, newChannel)
+		go s.handleChannel(sshConn, sess, newChannel)
 	}
 }
 
-func (s *Server) handleChannel(sshConn *ssh.ServerConn, newChannel ssh.NewChannel) {
+func (s *Server) handleChannel(sshConn *ssh.ServerConn, sess *session.Session, newChannel ssh.NewChannel) {
 	if newChannel.ChannelType() != "session" {
 		newChannel.Reject(ssh.UnknownChannelType, "tipo de canal desconhecido")
 		return
@@ -104,32 +373,179 @@ func (s *Server) handleChannel(sshConn *ssh.ServerConn, newChannel ssh.NewChanne
 	}
 	defer channel.Close()
 
-	// Lida com requisições na sessão (pty-req, shell, etc.)
-	go func(in <-chan *ssh.Request) {
-		for req := range in {
-			switch req.Type {
-			case "pty-req":
-				// O cliente está solicitando um PTY. Aceitamos.
-				req.Reply(true, nil)
-			case "shell":
-				// O cliente está solicitando um shell. Aceitamos.
+	// Lida com as requisições que antecedem o shell ou o exec (pty-req, env), guardando o
+	// tamanho e o tipo do terminal para configurar o programa TUI.
+	env := sessionEnv{}
+	var size *tea.WindowSizeMsg
+	var command *string
+	started := false
+	for !started {
+		req, ok := <-requests
+		if !ok {
+			return
+		}
+		switch req.Type {
+		case "pty-req":
+			var pty ptyRequest
+			if err := ssh.Unmarshal(req.Payload, &pty); err != nil {
+				log.Printf("pty-req inválido de %s: %v", sshConn.User(), err)
+				req.Reply(false, nil)
+				continue
+			}
+			env["TERM"] = pty.Term
+			size = &tea.WindowSizeMsg{Width: int(pty.Columns), Height: int(pty.Rows)}
+			req.Reply(true, nil)
+		case "env":
+			var kv envRequest
+			if err := ssh.Unmarshal(req.Payload, &kv); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			// Apenas as variáveis que influenciam o perfil de cores são aceitas.
+			switch kv.Name {
+			case "COLORTERM", "NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE":
+				env[kv.Name] = kv.Value
 				req.Reply(true, nil)
 			default:
 				req.Reply(false, nil)
 			}
+		case "shell":
+			// O cliente está solicitando um shell. Aceitamos.
+			req.Reply(true, nil)
+			started = true
+		case "exec":
+			// Comando não interativo, ex.: `ssh bbs -p 7778 forums`.
+			var exec execRequest
+			if err := ssh.Unmarshal(req.Payload, &exec); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			command = &exec.Command
+			started = true
+		default:
+			req.Reply(false, nil)
 		}
-	}(requests)
+	}
+
+	if isRegistration(sshConn) {
+		go ssh.DiscardRequests(requests)
+		s.runRegistration(sshConn, sess, channel, env, command == nil)
+		return
+	}
+
+	// Os visitantes não têm conta e usam apenas a interface, no papel somente leitura.
+	if isGuest(sshConn) && command != nil {
+		go ssh.DiscardRequests(requests)
+		fmt.Fprintf(channel.Stderr(), "O acesso de visitante é interativo: conecte-se com ssh -t %s@<servidor>.\r\n", s.GuestUser)
+		channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: 1}))
+		return
+	}
 
 	// Busca os dados completos do usuário para obter o papel (role).
-	user, _, err := database.GetUserByUsername(sshConn.User())
+	user, _, err := s.store.GetUserByUsername(sshConn.User())
+	if isGuest(sshConn) {
+		user, err = s.guestUser(), nil
+	}
 	if err != nil || user == nil {
 		log.Printf("Erro crítico: não foi possível obter dados do usuário '%s' após a autenticação: %v", sshConn.User(), err)
 		return
 	}
 
+	// As contas cadastradas sob a política de aprovação autenticam, mas não entram até serem aprovadas.
+	if user.Pending() {
+		log.Printf("Acesso negado a '%s': cadastro aguardando aprovação.", user.Username)
+		fmt.Fprint(channel.Stderr(), "Sua conta ainda aguarda a aprovação de um administrador.\r\n")
+		channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: 1}))
+		return
+	}
+
+	// Os papéis que exigem a verificação em duas etapas precisam ativá-la antes de usar o BBS.
+	if s.requiresTwoFactor(user.Role) {
+		secret, err := s.store.GetTOTPSecret(user.Username)
+		if err != nil {
+			log.Printf("Erro ao consultar a verificação em duas etapas de '%s': %v", user.Username, err)
+			return
+		}
+		if secret == "" {
+			log.Printf("Usuário '%s' precisa ativar a verificação em duas etapas.", user.Username)
+			go ssh.DiscardRequests(requests)
+			s.runTwoFactorSetup(user, sess, channel, env, size, command == nil)
+			channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: 1}))
+			return
+		}
+	}
+
+	if command != nil {
+		go ssh.DiscardRequests(requests)
+		log.Printf("Executando comando de %s: %q", sshConn.User(), *command)
+		sess.Touch("exec: "+*command, 0)
+		status := runExec(s.store, user, *command, channel, channel, channel.Stderr())
+		if _, err := channel.SendRequest("exit-status", false, ssh.Marshal(exitStatusRequest{Status: uint32(status)})); err != nil {
+			log.Printf("Falha ao enviar exit-status para %s: %v", sshConn.User(), err)
+		}
+		return
+	}
+
+	// O renderer usa o TERM do cliente para escolher o perfil de cores da sessão.
+	renderer := lipgloss.NewRenderer(channel, termenv.WithEnvironment(env), termenv.WithTTY(true))
+
+	// Os eventos do chat chegam ao programa pelo p.Send. O cliente é criado antes do
+	// programa, porque o modelo o recebe na criação; até o programa existir, os eventos
+	// são descartados.
+	var program atomic.Pointer[tea.Program]
+	opts := []tui.Option{tui.WithRenderer(renderer), tui.WithSession(s.sessions, sess), tui.WithSysop(s), tui.WithEditWindow(s.EditWindow)}
+	if isGuest(sshConn) {
+		// Os visitantes não participam do chat; a tela inicial sugere o cadastro.
+		if s.Registration != nil {
+			opts = append(opts, tui.WithSignupLogin(s.RegisterUser))
+		}
+	} else {
+		chatClient := s.chat.Connect(user.Username, func(ev chat.Event) {
+			if p := program.Load(); p != nil {
+				p.Send(ev)
+			}
+		})
+		defer chatClient.Close()
+		opts = append(opts, tui.WithChat(chatClient), tui.WithRegistration(s.Registration))
+	}
+
 	// Inicia a aplicação TUI com Bubble Tea.
-	m := tui.InitialModel(user.Username, user.Role)
-	p := tea.NewProgram(m, tea.WithInput(channel), tea.WithOutput(channel))
+	if s.requiresTwoFactor(user.Role) {
+		opts = append(opts, tui.WithTwoFactorRequired())
+	}
+	m := tui.InitialModel(s.store, user.Username, user.Role, opts...)
+	// Os sinais do processo (SIGINT/SIGTERM) são tratados pelo app, que desliga o servidor com Shutdown.
+	p := tea.NewProgram(m, tea.WithInput(channel), tea.WithOutput(channel), tea.WithEnvironment(env.Environ()), tea.WithoutSignalHandler())
+	program.Store(p)
+	s.addProgram(p)
+	defer s.removeProgram(p)
+
+	// Ao contrário do chat, os eventos de tópicos e posts podem chegar a qualquer momento,
+	// por isso a assinatura só é feita depois que o programa existe.
+	subscription := s.events.Subscribe(func(ev events.Event) { p.Send(ev) })
+	defer subscription.Close()
+
+	// Requisições recebidas durante a sessão (window-change) são repassadas ao programa.
+	go func() {
+		if size != nil {
+			p.Send(*size)
+		}
+		for req := range requests {
+			switch req.Type {
+			case "window-change":
+				var wc windowChangeRequest
+				if err := ssh.Unmarshal(req.Payload, &wc); err != nil {
+					req.Reply(false, nil)
+					continue
+				}
+				p.Send(tea.WindowSizeMsg{Width: int(wc.Columns), Height: int(wc.Rows)})
+				req.Reply(false, nil) // window-change não espera resposta
+			default:
+				req.Reply(false, nil)
+			}
+		}
+	}()
 
 	if _, err := p.Run(); err != nil {
 		log.Printf("Erro ao executar o programa TUI para %s: %v", sshConn.User(), err)
@@ -0,0 +1,136 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/pkg/tui"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingShutdown é um desligamento programado.
+type pendingShutdown struct {
+	at     time.Time
+	reason string
+	timer  *time.Timer
+}
+
+var _ tui.Sysop = (*Server)(nil)
+
+// addProgram registra o programa de uma sessão interativa para receber os avisos.
+func (s *Server) addProgram(p *tea.Program) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs[p] = true
+}
+
+func (s *Server) removeProgram(p *tea.Program) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.programs, p)
+}
+
+// sendAll entrega a mensagem a todos os programas em execução.
+func (s *Server) sendAll(msg tea.Msg) {
+	s.mu.Lock()
+	programs := make([]*tea.Program, 0, len(s.programs))
+	for p := range s.programs {
+		programs = append(programs, p)
+	}
+	s.mu.Unlock()
+
+	for _, p := range programs {
+		p.Send(msg)
+	}
+}
+
+// Broadcast exibe um aviso no topo da tela de todas as sessões.
+func (s *Server) Broadcast(from, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("o aviso não pode estar vazio")
+	}
+	log.Printf("Aviso de %s para todas as sessões: %s", from, text)
+	s.sendAll(tui.BroadcastMsg{From: from, Text: text, Time: time.Now()})
+	return nil
+}
+
+// ScheduleShutdown programa o desligamento do servidor. Até lá, as sessões exibem a
+// contagem regressiva; no prazo, são desconectadas e o servidor para de aceitar conexões.
+func (s *Server) ScheduleShutdown(from string, delay time.Duration, reason string) error {
+	if delay < 0 {
+		return fmt.Errorf("o prazo do desligamento não pode ser negativo")
+	}
+	reason = strings.TrimSpace(reason)
+
+	s.mu.Lock()
+	if s.shutdown != nil {
+		at := s.shutdown.at
+		s.mu.Unlock()
+		return fmt.Errorf("já há um desligamento programado para %s", at.Local().Format("15:04:05"))
+	}
+	at := time.Now().Add(delay)
+	s.shutdown = &pendingShutdown{at: at, reason: reason}
+	s.shutdown.timer = time.AfterFunc(delay, func() { s.shutdownNow(reason) })
+	s.mu.Unlock()
+
+	log.Printf("Desligamento programado por %s para %s: %s", from, at.Format("15:04:05"), reason)
+	s.sendAll(tui.ShutdownMsg{At: at, Reason: reason})
+	return nil
+}
+
+// CancelShutdown cancela o desligamento programado.
+func (s *Server) CancelShutdown(from string) error {
+	s.mu.Lock()
+	if s.shutdown == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("não há desligamento programado")
+	}
+	if !s.shutdown.timer.Stop() {
+		s.mu.Unlock()
+		return fmt.Errorf("o desligamento já está em andamento")
+	}
+	s.shutdown = nil
+	s.mu.Unlock()
+
+	log.Printf("Desligamento cancelado por %s.", from)
+	s.sendAll(tui.ShutdownMsg{})
+	return nil
+}
+
+// PendingShutdown retorna o desligamento programado, se houver.
+func (s *Server) PendingShutdown() (time.Time, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.shutdown == nil {
+		return time.Time{}, "", false
+	}
+	return s.shutdown.at, s.shutdown.reason, true
+}
+
+// disconnectAll encerra as sessões interativas exibindo o motivo. Só o primeiro aviso
+// é enviado, para que a mensagem de um desligamento programado não seja substituída.
+func (s *Server) disconnectAll(reason string) {
+	s.mu.Lock()
+	if s.notified {
+		s.mu.Unlock()
+		return
+	}
+	s.notified = true
+	s.mu.Unlock()
+	s.sendAll(tui.DisconnectMsg{Reason: reason})
+}
+
+// shutdownNow desconecta as sessões com o aviso de manutenção e para de aceitar
+// conexões; ListenAndServe então retorna e o app conclui o desligamento com Shutdown.
+func (s *Server) shutdownNow(reason string) {
+	log.Printf("Desligando o servidor: %s", reason)
+	goodbye := "O servidor foi desligado para manutenção."
+	if reason != "" {
+		goodbye = fmt.Sprintf("O servidor foi desligado para manutenção: %s.", strings.TrimSuffix(reason, "."))
+	}
+	s.disconnectAll(goodbye + " Até logo!")
+	s.closeListener()
+}
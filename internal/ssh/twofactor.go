@@ -0,0 +1,130 @@
+package ssh
+
+import (
+	"fmt"
+	"log"
+	"modern-bbs/internal/database"
+	"modern-bbs/internal/session"
+	"modern-bbs/internal/totp"
+	"modern-bbs/pkg/tui"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/crypto/ssh"
+)
+
+// twoFactorInstruction é exibida pelo cliente SSH antes de pedir o código.
+const twoFactorInstruction = "Verificação em duas etapas: informe o código do aplicativo autenticador ou um código de recuperação."
+
+// secondFactor conclui a autenticação de quem passou pela senha ou pela chave pública.
+// Se o usuário ativou a verificação em duas etapas, retorna um PartialSuccessError que
+// pede o código por keyboard-interactive; as permissões da primeira etapa são mantidas.
+// done, se informada, é chamada quando a autenticação termina com sucesso.
+func (s *Server) secondFactor(c ssh.ConnMetadata, perms *ssh.Permissions, done func()) (*ssh.Permissions, error) {
+	secret, err := s.store.GetTOTPSecret(c.User())
+	if err != nil {
+		log.Printf("Erro ao consultar a verificação em duas etapas de '%s': %v", c.User(), err)
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if secret == "" {
+		if done != nil {
+			done()
+		}
+		return perms, nil
+	}
+
+	return nil, &ssh.PartialSuccessError{
+		Next: ssh.ServerAuthCallbacks{
+			KeyboardInteractiveCallback: func(c ssh.ConnMetadata, client ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+				if err := s.verifySecondFactor(c, secret, client); err != nil {
+					return nil, err
+				}
+				if done != nil {
+					done()
+				}
+				return perms, nil
+			},
+		},
+	}
+}
+
+// verifySecondFactor pede e confere o código. As falhas contam para o bloqueio de login,
+// como as senhas incorretas, para que os códigos não possam ser testados por força bruta.
+func (s *Server) verifySecondFactor(c ssh.ConnMetadata, secret string, client ssh.KeyboardInteractiveChallenge) error {
+	ip := remoteIP(c.RemoteAddr())
+	if err := s.guard.check(ip, c.User()); err != nil {
+		log.Printf("Verificação em duas etapas bloqueada para '%s' de %s: %v", c.User(), ip, err)
+		return err
+	}
+
+	answers, err := client(c.User(), twoFactorInstruction, []string{"Código: "}, []bool{true})
+	if err != nil {
+		return err
+	}
+	if len(answers) != 1 {
+		return fmt.Errorf("resposta inválida")
+	}
+	answer := strings.TrimSpace(answers[0])
+
+	// Os códigos do aplicativo têm apenas dígitos; o resto é tratado como código de recuperação.
+	var ok bool
+	if len(answer) == totp.Digits && strings.Trim(answer, "0123456789") == "" {
+		if step, valid := totp.Verify(secret, answer, time.Now()); valid {
+			// Cada código só vale uma vez, mesmo dentro do seu intervalo.
+			ok, err = s.store.UseTOTPStep(c.User(), step)
+		}
+	} else {
+		ok, err = s.store.UseRecoveryCode(c.User(), answer)
+		if ok {
+			left, _ := s.store.CountRecoveryCodes(c.User())
+			log.Printf("Usuário '%s' entrou com um código de recuperação; restam %d.", c.User(), left)
+		}
+	}
+	if err != nil {
+		log.Printf("Erro ao verificar o código de '%s': %v", c.User(), err)
+		return fmt.Errorf("erro interno do servidor")
+	}
+	if !ok {
+		log.Printf("Código de verificação inválido para o usuário: %s", c.User())
+		s.guard.fail(ip, c.User())
+		return fmt.Errorf("código de verificação inválido")
+	}
+	return nil
+}
+
+// requiresTwoFactor informa se a política exige a verificação em duas etapas do papel.
+func (s *Server) requiresTwoFactor(role string) bool {
+	return slices.Contains(s.TwoFactorRoles, role)
+}
+
+// runTwoFactorSetup executa a ativação obrigatória da verificação em duas etapas. Ao
+// final, a sessão é encerrada e o usuário entra novamente, já com o código do aplicativo.
+// A ativação precisa de um terminal, então comandos via ssh exec são recusados.
+func (s *Server) runTwoFactorSetup(user *database.User, sess *session.Session, channel ssh.Channel, env sessionEnv, size *tea.WindowSizeMsg, interactive bool) {
+	if !interactive {
+		fmt.Fprint(channel.Stderr(), "O seu papel exige a verificação em duas etapas. Conecte-se pelo terminal para ativá-la.\r\n")
+		return
+	}
+	sess.Touch("Verificação em duas etapas", 0)
+
+	renderer := lipgloss.NewRenderer(channel, termenv.WithEnvironment(env), termenv.WithTTY(true))
+	p := tea.NewProgram(tui.NewTwoFactorSetupModel(s.store, user.Username, renderer),
+		tea.WithInput(channel), tea.WithOutput(channel), tea.WithEnvironment(env.Environ()), tea.WithoutSignalHandler())
+	s.addProgram(p)
+	defer s.removeProgram(p)
+
+	if size != nil {
+		go p.Send(*size)
+	}
+	if _, err := p.Run(); err != nil {
+		log.Printf("Erro ao executar a ativação da verificação em duas etapas para %s: %v", user.Username, err)
+		return
+	}
+	if secret, err := s.store.GetTOTPSecret(user.Username); err == nil && secret != "" {
+		log.Printf("Usuário '%s' ativou a verificação em duas etapas.", user.Username)
+	}
+}
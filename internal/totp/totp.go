@@ -0,0 +1,116 @@
+// Package totp implementa as senhas de uso único baseadas em tempo (RFC 6238) usadas
+// na verificação em duas etapas, compatíveis com os aplicativos autenticadores comuns,
+// e os códigos de recuperação que substituem o aplicativo em caso de perda.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros das senhas: os padrões do RFC 6238, que todos os aplicativos aceitam.
+const (
+	Digits = 6
+	Period = 30 * time.Second
+
+	// skew é o número de intervalos aceitos antes e depois do atual, para tolerar
+	// relógios um pouco fora de sincronia.
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret gera um segredo aleatório de 160 bits, codificado em base32.
+func NewSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("falha ao gerar segredo: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step retorna o intervalo de tempo que contém t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code calcula a senha do intervalo informado.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("segredo inválido: %w", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000), nil
+}
+
+// Verify confere a senha digitada no instante now e retorna o intervalo a que ela
+// corresponde, para que quem chama possa recusar a reutilização da mesma senha.
+func Verify(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI monta o endereço otpauth:// lido pelos aplicativos autenticadores no código QR.
+func URI(issuer, account, secret string) string {
+	// Os aplicativos esperam os espaços como %20, e não como o + de url.Values.
+	issuer = url.PathEscape(issuer)
+	return "otpauth://totp/" + issuer + ":" + url.PathEscape(account) + "?secret=" + secret + "&issuer=" + issuer
+}
+
+// Formato dos códigos de recuperação: dois grupos de cinco caracteres, sem os que se confundem.
+const (
+	RecoveryCodeCount = 10
+	recoveryAlphabet  = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+)
+
+// NewRecoveryCodes gera os códigos de recuperação, cada um válido para um único login.
+// Cada caractere é sorteado de modo uniforme no alfabeto, com rand.Int.
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	size := big.NewInt(int64(len(recoveryAlphabet)))
+	for i := range codes {
+		code := make([]byte, 0, 11)
+		for j := range 10 {
+			if j == 5 {
+				code = append(code, '-')
+			}
+			n, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return nil, fmt.Errorf("falha ao gerar códigos de recuperação: %w", err)
+			}
+			code = append(code, recoveryAlphabet[n.Int64()])
+		}
+		codes[i] = string(code)
+	}
+	return codes, nil
+}
@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret é o segredo dos vetores de teste do RFC 6238 para o SHA-1
+// ("12345678901234567890"), em base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// Os vetores do RFC têm 8 dígitos; as senhas de 6 dígitos são os últimos 6.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code em %d = %s, esperado %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeLowercaseSecret(t *testing.T) {
+	upper, _ := Code(rfcSecret, 1)
+	lower, err := Code(strings.ToLower(rfcSecret), 1)
+	if err != nil || lower != upper {
+		t.Errorf("Code com o segredo em minúsculas = %q, %v, esperado %q", lower, err, upper)
+	}
+	if _, err := Code("não é base32!", 1); err == nil {
+		t.Error("Code aceitou um segredo inválido")
+	}
+}
+
+func TestVerifySteps(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name string
+		step int64
+		ok   bool
+	}{
+		{"intervalo atual", current, true},
+		{"intervalo anterior", current - 1, true},
+		{"intervalo seguinte", current + 1, true},
+		{"dois intervalos antes", current - 2, false},
+		{"dois intervalos depois", current + 2, false},
+	}
+	for _, tt := range tests {
+		code, _ := Code(rfcSecret, tt.step)
+		step, ok := Verify(rfcSecret, code, now)
+		if ok != tt.ok {
+			t.Errorf("%s: Verify = %v, esperado %v", tt.name, ok, tt.ok)
+			continue
+		}
+		// O intervalo retornado é o da senha, para que quem chama recuse a reutilização.
+		if ok && step != tt.step {
+			t.Errorf("%s: Verify retornou o intervalo %d, esperado %d", tt.name, step, tt.step)
+		}
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := Code(rfcSecret, Step(now))
+	if _, ok := Verify(rfcSecret, " "+code+"\n", now); !ok {
+		t.Error("Verify recusou a senha com espaços em volta")
+	}
+	for _, bad := range []string{"", "12345", code + "0", "abcdef"} {
+		if _, ok := Verify(rfcSecret, bad, now); ok {
+			t.Errorf("Verify aceitou %q", bad)
+		}
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("NewRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("NewRecoveryCodes gerou %d códigos, esperado %d", len(codes), RecoveryCodeCount)
+	}
+	seen := make(map[string]bool)
+	for _, c := range codes {
+		if len(c) != 11 || c[5] != '-' || strings.Trim(strings.Replace(c, "-", "", 1), recoveryAlphabet) != "" {
+			t.Errorf("código fora do formato: %q", c)
+		}
+		if seen[c] {
+			t.Errorf("código repetido: %q", c)
+		}
+		seen[c] = true
+	}
+}
@@ -3,11 +3,8 @@ package tui
 import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-var adminTitleStyle = lipgloss.NewStyle().MarginLeft(2)
-
 type adminMenuItem struct {
 	title, desc string
 }
@@ -18,10 +15,12 @@ func (i adminMenuItem) FilterValue() string { return i.title }
 
 // adminModel gerencia a tela de administração.
 type adminModel struct {
-	main                       *mainModel
-	list                       list.Model
-	navigateToUserManagement   bool
-	navigateToForumManagement  bool
+	main                      *mainModel
+	list                      list.Model
+	navigateToUserManagement  bool
+	navigateToForumManagement bool
+	navigateToSysop           bool
+	navigateToTrash           bool
 }
 
 // NewAdminModel cria um novo modelo para a tela de administração.
@@ -29,12 +28,18 @@ func NewAdminModel(main *mainModel) *adminModel {
 	items := []list.Item{
 		adminMenuItem{title: "Gerenciamento de Usuários", desc: "Editar, deletar e alterar papéis de usuários"},
 		adminMenuItem{title: "Gerenciamento de Fóruns", desc: "Criar, editar e deletar fóruns"},
+		adminMenuItem{title: "Lixeira", desc: "Restaurar ou remover definitivamente fóruns, tópicos e posts apagados"},
+	}
+	if main.sysop != nil {
+		items = append(items, adminMenuItem{title: "Avisos do Sistema", desc: "Enviar avisos a todos e programar o desligamento do servidor"})
 	}
 
 	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
 	l.Title = "Menu de Administração"
 
-	return &adminModel{main: main, list: l}
+	m := &adminModel{main: main, list: l}
+	m.setSize(main.width, main.height)
+	return m
 }
 
 // Init inicializa o modelo.
@@ -46,7 +51,7 @@ func (m *adminModel) Init() tea.Cmd {
 func (m *adminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height)
+		m.setSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
@@ -60,6 +65,10 @@ func (m *adminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				case "Gerenciamento de Fóruns":
 					m.navigateToForumManagement = true
 					return m, nil
+				case "Lixeira":
+					m.navigateToTrash = true
+				case "Avisos do Sistema":
+					m.navigateToSysop = true
 				}
 			}
 			return m, nil
@@ -71,7 +80,13 @@ func (m *adminModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// setSize ajusta a lista ao tamanho do terminal, descontando as linhas do
+// cabeçalho, da mensagem de status e do rodapé desenhados pelo mainModel.
+func (m *adminModel) setSize(width, height int) {
+	m.list.SetSize(width, max(height-4, 0))
+}
+
 // View renderiza a tela de administração.
 func (m *adminModel) View() string {
-	return adminTitleStyle.Render(m.list.View())
+	return m.main.styles.adminTitle.Render(m.list.View())
 }
@@ -0,0 +1,379 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/chat"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// chatBufferSize limita as linhas guardadas por sala na tela de chat.
+const chatBufferSize = 500
+
+// chatModel representa a tela de chat em tempo real. O usuário pode estar em várias
+// salas ao mesmo tempo; os eventos chegam ao programa pelo p.Send do servidor SSH.
+type chatModel struct {
+	keys     *KeyMap
+	parent   *mainModel
+	client   *chat.Client
+	input    textinput.Model
+	viewport viewport.Model
+	quitting bool
+
+	channels []string // Salas em que o usuário está, na ordem em que entrou
+	current  int
+	buffers  map[string][]chat.Event
+	unread   map[string]int
+}
+
+type chatJoinedMsg struct {
+	channel string
+	history []chat.Event
+	err     error
+}
+
+// NewChatModel cria a tela de chat para o cliente da sessão.
+func NewChatModel(parent *mainModel, client *chat.Client) *chatModel {
+	ti := textinput.New()
+	ti.Placeholder = "Mensagem, /me ação, /join sala, /part, /salas, /quem"
+	ti.CharLimit = 500
+	ti.Prompt = "> "
+	ti.Focus()
+
+	m := &chatModel{
+		keys:     DefaultKeyMap,
+		parent:   parent,
+		client:   client,
+		input:    ti,
+		viewport: viewport.New(0, 0),
+		buffers:  make(map[string][]chat.Event),
+		unread:   make(map[string]int),
+	}
+	m.setSize(parent.width, parent.height)
+	return m
+}
+
+// Init entra na sala padrão ao abrir o chat.
+func (m *chatModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.joinCmd(chat.DefaultChannel))
+}
+
+func (m *chatModel) setSize(width, height int) {
+	if width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+	m.input.Width = width - 4
+	m.viewport.Width = width
+	m.viewport.Height = max(height-11, 3)
+	m.render()
+}
+
+func (m *chatModel) joinCmd(channel string) tea.Cmd {
+	return func() tea.Msg {
+		history, err := m.client.Join(channel)
+		name, _ := chat.NormalizeChannel(channel)
+		return chatJoinedMsg{channel: name, history: history, err: err}
+	}
+}
+
+// currentChannel retorna a sala exibida, ou "" se o usuário não estiver em nenhuma.
+func (m *chatModel) currentChannel() string {
+	if len(m.channels) == 0 {
+		return ""
+	}
+	return m.channels[m.current]
+}
+
+// leave sai de todas as salas; é chamado ao fechar a tela de chat.
+func (m *chatModel) leave() {
+	for _, channel := range m.channels {
+		m.client.Part(channel)
+	}
+	m.channels = nil
+	m.current = 0
+	m.buffers = make(map[string][]chat.Event)
+	m.unread = make(map[string]int)
+}
+
+func (m *chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case chatJoinedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		// Eventos ao vivo podem ter chegado antes do histórico; o histórico vem antes deles.
+		m.buffers[msg.channel] = append(msg.history, m.buffers[msg.channel]...)
+		m.current = m.addChannel(msg.channel)
+		m.unread[msg.channel] = 0
+		m.render()
+		m.viewport.GotoBottom()
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Back):
+			m.leave()
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case msg.String() == "tab" || msg.String() == "shift+tab":
+			if len(m.channels) > 1 {
+				step := 1
+				if msg.String() == "shift+tab" {
+					step = len(m.channels) - 1
+				}
+				m.current = (m.current + step) % len(m.channels)
+				m.unread[m.currentChannel()] = 0
+				m.render()
+				m.viewport.GotoBottom()
+			}
+			return m, nil
+		case msg.String() == "enter":
+			line := m.input.Value()
+			m.input.SetValue("")
+			return m, m.execute(line)
+		case key.Matches(msg, m.keys.Up), key.Matches(msg, m.keys.Down),
+			key.Matches(msg, m.keys.PageUp), key.Matches(msg, m.keys.PageDown):
+			// As setas rolam o histórico; j e k continuam indo para o campo de texto.
+			if msg.Type != tea.KeyRunes {
+				var cmd tea.Cmd
+				m.viewport, cmd = m.viewport.Update(msg)
+				return m, cmd
+			}
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// addChannel inclui a sala na lista, se necessário, e retorna a sua posição.
+func (m *chatModel) addChannel(channel string) int {
+	for i, c := range m.channels {
+		if c == channel {
+			return i
+		}
+	}
+	m.channels = append(m.channels, channel)
+	return len(m.channels) - 1
+}
+
+// receive guarda o evento no buffer da sala e atualiza a tela.
+func (m *chatModel) receive(ev chat.Event) {
+	if ev.Kind == chat.EventPart && ev.User == m.client.User() && !m.joined(ev.Channel) {
+		return // Aviso da própria saída, já tratado por /part
+	}
+	if !m.joined(ev.Channel) && m.buffers[ev.Channel] == nil {
+		// Entrada ainda em andamento: guarda até o histórico chegar.
+		if ev.Kind != chat.EventJoin || ev.User != m.client.User() {
+			return
+		}
+	}
+
+	buffer := append(m.buffers[ev.Channel], ev)
+	if len(buffer) > chatBufferSize {
+		buffer = buffer[len(buffer)-chatBufferSize:]
+	}
+	m.buffers[ev.Channel] = buffer
+
+	if ev.Channel != m.currentChannel() {
+		if ev.Kind == chat.EventMessage || ev.Kind == chat.EventAction {
+			m.unread[ev.Channel]++
+		}
+		return
+	}
+
+	// Acompanha as mensagens novas apenas se o usuário não estiver lendo o histórico.
+	atBottom := m.viewport.AtBottom()
+	m.render()
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *chatModel) joined(channel string) bool {
+	for _, c := range m.channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// notice exibe um aviso local na sala atual.
+func (m *chatModel) notice(text string) {
+	channel := m.currentChannel()
+	m.buffers[channel] = append(m.buffers[channel], chat.Event{Kind: chat.EventNotice, Channel: channel, Text: text})
+	m.render()
+	m.viewport.GotoBottom()
+}
+
+// execute interpreta a linha digitada: um comando iniciado por '/' ou uma mensagem.
+func (m *chatModel) execute(line string) tea.Cmd {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	channel := m.currentChannel()
+
+	if !strings.HasPrefix(line, "/") {
+		if channel == "" {
+			m.notice("Você não está em nenhuma sala. Use /join <sala>.")
+			return nil
+		}
+		return m.clientCmd(func() error { return m.client.Say(channel, line) })
+	}
+
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(command) {
+	case "/me":
+		if channel == "" {
+			m.notice("Você não está em nenhuma sala. Use /join <sala>.")
+			return nil
+		}
+		return m.clientCmd(func() error { return m.client.Act(channel, arg) })
+	case "/join", "/entrar":
+		if _, err := chat.NormalizeChannel(arg); err != nil {
+			m.notice(err.Error())
+			return nil
+		}
+		return m.joinCmd(arg)
+	case "/part", "/sair":
+		if arg != "" {
+			name, err := chat.NormalizeChannel(arg)
+			if err != nil {
+				m.notice(err.Error())
+				return nil
+			}
+			channel = name
+		}
+		if !m.joined(channel) {
+			m.notice("Você não está nessa sala.")
+			return nil
+		}
+		m.part(channel)
+		return nil
+	case "/salas", "/list":
+		channels, err := m.client.Channels()
+		if err != nil {
+			return func() tea.Msg { return errorMsg{err} }
+		}
+		names := make([]string, len(channels))
+		for i, c := range channels {
+			names[i] = fmt.Sprintf("#%s (%d)", c.Name, c.Members)
+		}
+		m.notice("Salas: " + strings.Join(names, ", "))
+	case "/quem", "/who":
+		if channel == "" {
+			return nil
+		}
+		m.notice(fmt.Sprintf("Em #%s: %s", channel, strings.Join(m.client.Members(channel), ", ")))
+	default:
+		m.notice(fmt.Sprintf("Comando desconhecido: %s", command))
+	}
+	return nil
+}
+
+// part sai da sala e passa a exibir a sala anterior da lista.
+func (m *chatModel) part(channel string) {
+	m.client.Part(channel)
+	for i, c := range m.channels {
+		if c == channel {
+			m.channels = append(m.channels[:i], m.channels[i+1:]...)
+			break
+		}
+	}
+	delete(m.buffers, channel)
+	delete(m.unread, channel)
+	m.current = max(min(m.current, len(m.channels)-1), 0)
+	m.render()
+	m.viewport.GotoBottom()
+}
+
+func (m *chatModel) clientCmd(action func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
+}
+
+// render monta o conteúdo do viewport com os eventos da sala atual.
+func (m *chatModel) render() {
+	width := max(m.viewport.Width, 10)
+	var lines []string
+	for _, ev := range m.buffers[m.currentChannel()] {
+		lines = append(lines, ansi.Wrap(m.renderEvent(ev), width, ""))
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+func (m *chatModel) renderEvent(ev chat.Event) string {
+	styles := m.parent.styles
+	stamp := ""
+	if !ev.Time.IsZero() {
+		stamp = styles.footer.Render(ev.Time.Local().Format("15:04")) + " "
+	}
+
+	switch ev.Kind {
+	case chat.EventAction:
+		return stamp + styles.highlight.Render("* "+ev.User) + " " + ev.Text
+	case chat.EventJoin:
+		return stamp + styles.footer.Render(fmt.Sprintf("→ %s entrou em #%s", ev.User, ev.Channel))
+	case chat.EventPart:
+		return stamp + styles.footer.Render(fmt.Sprintf("← %s saiu de #%s", ev.User, ev.Channel))
+	case chat.EventNotice:
+		return styles.statusMessage.Render("-- " + ev.Text)
+	}
+	return stamp + styles.header.Render("<"+ev.User+">") + " " + ev.Text
+}
+
+func (m *chatModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	var tabs []string
+	for i, c := range m.channels {
+		label := "#" + c
+		if n := m.unread[c]; n > 0 && i != m.current {
+			label += fmt.Sprintf(" (%d)", n)
+		}
+		if i == m.current {
+			tabs = append(tabs, m.parent.styles.selectedItem.Render("["+label+"]"))
+		} else {
+			tabs = append(tabs, m.parent.styles.item.Render(label))
+		}
+	}
+	b.WriteString(strings.Join(tabs, " ") + "\n")
+
+	if channel := m.currentChannel(); channel != "" {
+		members := m.client.Members(channel)
+		b.WriteString(m.parent.styles.footer.Render(fmt.Sprintf("%d na sala: %s", len(members), strings.Join(members, ", "))) + "\n\n")
+	} else {
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.viewport.View() + "\n\n")
+	b.WriteString(m.input.View() + "\n")
+	return b.String()
+}
+
+func (m *chatModel) helpView() string {
+	help := []string{
+		"enter enviar",
+		"tab trocar de sala",
+		"↑/↓/pgup/pgdn rolar",
+		m.keys.Back.Help().Key + " sair do chat",
+	}
+	return strings.Join(help, " • ")
+}
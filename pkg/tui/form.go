@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -34,6 +36,7 @@ type formModel struct {
 	focusIndex   int
 	submitAction func(map[string]string) tea.Cmd
 	quitting     bool
+	hint         string // Dica exibida abaixo dos campos (opcional)
 }
 
 func newTextInput(placeholder string) formInput {
@@ -72,11 +75,11 @@ func NewTopicFormModel(parent *mainModel, forum *database.Forum) *formModel {
 			if topicTitle == "" {
 				return func() tea.Msg { return statusMessage{success: false, message: "O título não pode estar vazio."} }
 			}
-			user, _, err := database.GetUserByUsername(parent.User)
+			user, _, err := parent.store.GetUserByUsername(parent.User)
 			if err != nil {
 				return func() tea.Msg { return errorMsg{err} }
 			}
-			err = database.CreateTopic(int(forum.ID), int(user.ID), topicTitle)
+			err = parent.store.CreateTopic(int(forum.ID), int(user.ID), topicTitle)
 			if err != nil {
 				return func() tea.Msg { return errorMsg{err} }
 			}
@@ -87,7 +90,8 @@ func NewTopicFormModel(parent *mainModel, forum *database.Forum) *formModel {
 
 // NewPostFormModel cria um formulário para um novo post (resposta).
 func NewPostFormModel(parent *mainModel, topic *database.Topic) *formModel {
-	postTextArea := newTextArea("Escreva sua resposta...")
+	postTextArea := newTextArea("Escreva sua resposta... (Markdown)")
+	postTextArea.(*TextArea).SetHeight(10)
 	postTextArea.Focus()
 
 	fields := []FormField{
@@ -99,16 +103,17 @@ func NewPostFormModel(parent *mainModel, topic *database.Topic) *formModel {
 		title:      fmt.Sprintf("Re: %s", topic.Title),
 		fields:     fields,
 		focusIndex: 0,
+		hint:       "Markdown: **negrito**, *itálico*, `código`, # título, - lista, > citação, [texto](url), ``` bloco de código",
 		submitAction: func(values map[string]string) tea.Cmd {
 			postContent := values["Conteúdo"]
 			if postContent == "" {
 				return func() tea.Msg { return statusMessage{success: false, message: "O conteúdo não pode estar vazio."} }
 			}
-			user, _, err := database.GetUserByUsername(parent.User)
+			user, _, err := parent.store.GetUserByUsername(parent.User)
 			if err != nil {
 				return func() tea.Msg { return errorMsg{err} }
 			}
-			err = database.CreatePost(topic.ID, int(user.ID), postContent)
+			err = parent.store.CreatePost(topic.ID, int(user.ID), postContent)
 			if err != nil {
 				return func() tea.Msg { return errorMsg{err} }
 			}
@@ -117,6 +122,219 @@ func NewPostFormModel(parent *mainModel, topic *database.Topic) *formModel {
 	}
 }
 
+// NewEditPostFormModel cria um formulário para editar um post, preenchido com o conteúdo
+// atual. A permissão e o prazo de edição são verificados de novo ao salvar.
+func NewEditPostFormModel(parent *mainModel, topic *database.Topic, post *database.Post) *formModel {
+	postTextArea := newTextArea("Conteúdo do post (Markdown)")
+	postTextArea.(*TextArea).SetHeight(10)
+	postTextArea.(*TextArea).SetValue(post.Content)
+	postTextArea.Focus()
+
+	fields := []FormField{
+		{Name: "Conteúdo", Input: postTextArea},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      fmt.Sprintf("Editando post de %s em '%s'", post.Username, topic.Title),
+		fields:     fields,
+		focusIndex: 0,
+		hint:       "A versão anterior fica no histórico do post (tecla h no tópico).",
+		submitAction: func(values map[string]string) tea.Cmd {
+			postContent := values["Conteúdo"]
+			if strings.TrimSpace(postContent) == "" {
+				return func() tea.Msg { return errorMsg{fmt.Errorf("o conteúdo não pode estar vazio")} }
+			}
+			// O Store verifica de novo a permissão e o prazo de edição.
+			if err := parent.store.UpdatePost(post.ID, parent.userID, postContent, parent.editWindow); err != nil {
+				return func() tea.Msg { return errorMsg{err} }
+			}
+			return func() tea.Msg { return postEditedMsg{} }
+		},
+	}
+}
+
+// NewTrashFormModel cria um formulário que pede o motivo antes de mover um fórum, tópico
+// ou post para a lixeira. Ao confirmar, a tela volta para back.
+func NewTrashFormModel(parent *mainModel, title, status string, back view, trash func(reason string) error) *formModel {
+	reasonInput := newTextInput("Motivo (opcional)")
+	reasonInput.Focus()
+
+	fields := []FormField{
+		{Name: "Motivo", Input: reasonInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      title,
+		fields:     fields,
+		focusIndex: 0,
+		hint:       "Os administradores podem restaurar o item pela Lixeira.",
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				if err := trash(strings.TrimSpace(values["Motivo"])); err != nil {
+					return errorMsg{err}
+				}
+				return trashActionMsg{status: status, back: back}
+			}
+		},
+	}
+}
+
+// newUsernameInput cria um campo de nome de usuário com autocompletar (Tab aceita a sugestão).
+func newUsernameInput(placeholder string, usernames []string) formInput {
+	input := newTextInput(placeholder)
+	ti := input.(*TextInput)
+	ti.ShowSuggestions = true
+	ti.SetSuggestions(usernames)
+	return input
+}
+
+// NewMessageFormModel cria um formulário para iniciar uma conversa privada.
+func NewMessageFormModel(parent *mainModel, usernames []string) *formModel {
+	recipientInput := newUsernameInput("Destinatário", usernames)
+	subjectInput := newTextInput("Assunto")
+	bodyArea := newTextArea("Escreva sua mensagem... (Markdown)")
+	bodyArea.(*TextArea).SetHeight(8)
+	recipientInput.Focus()
+
+	fields := []FormField{
+		{Name: "Para", Input: recipientInput},
+		{Name: "Assunto", Input: subjectInput},
+		{Name: "Mensagem", Input: bodyArea},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Nova Mensagem",
+		fields:     fields,
+		focusIndex: 0,
+		hint:       "Tab completa o nome do destinatário; ↑/↓ alternam entre as sugestões.",
+		submitAction: func(values map[string]string) tea.Cmd {
+			recipient := strings.TrimSpace(values["Para"])
+			return func() tea.Msg {
+				if recipient == "" {
+					return errorMsg{fmt.Errorf("informe o destinatário")}
+				}
+				_, err := parent.store.SendMessage(parent.userID, recipient, values["Assunto"], values["Mensagem"])
+				if err != nil {
+					return errorMsg{err}
+				}
+				return messageActionMsg{status: fmt.Sprintf("Mensagem enviada para %s.", recipient)}
+			}
+		},
+	}
+}
+
+// NewReplyFormModel cria um formulário para responder a uma conversa privada.
+func NewReplyFormModel(parent *mainModel, conversation *database.Conversation) *formModel {
+	bodyArea := newTextArea("Escreva sua resposta... (Markdown)")
+	bodyArea.(*TextArea).SetHeight(8)
+	bodyArea.Focus()
+
+	fields := []FormField{
+		{Name: "Mensagem", Input: bodyArea},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      fmt.Sprintf("Re: %s", conversation.Subject),
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				_, err := parent.store.ReplyToConversation(parent.userID, conversation.ID, values["Mensagem"])
+				if err != nil {
+					return errorMsg{err}
+				}
+				return messageActionMsg{status: "Resposta enviada."}
+			}
+		},
+	}
+}
+
+// NewBlockUserFormModel cria um formulário para bloquear as mensagens de um usuário.
+func NewBlockUserFormModel(parent *mainModel, usernames []string) *formModel {
+	usernameInput := newUsernameInput("Nome de Usuário", usernames)
+	usernameInput.Focus()
+
+	fields := []FormField{
+		{Name: "Username", Input: usernameInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Bloquear Remetente",
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			username := strings.TrimSpace(values["Username"])
+			return func() tea.Msg {
+				if err := parent.store.BlockUser(parent.userID, username); err != nil {
+					return errorMsg{err}
+				}
+				return messageActionMsg{status: fmt.Sprintf("%s foi bloqueado.", username)}
+			}
+		},
+	}
+}
+
+// NewBroadcastFormModel cria um formulário para enviar um aviso a todas as sessões.
+func NewBroadcastFormModel(parent *mainModel) *formModel {
+	textInput := newTextInput("Ex.: O servidor passará por manutenção às 22h.")
+	textInput.Focus()
+
+	fields := []FormField{
+		{Name: "Aviso", Input: textInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Enviar Aviso a Todos",
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				if err := parent.sysop.Broadcast(parent.User, values["Aviso"]); err != nil {
+					return errorMsg{err}
+				}
+				return sysopActionMsg{status: "Aviso enviado a todas as sessões."}
+			}
+		},
+	}
+}
+
+// NewShutdownFormModel cria um formulário para programar o desligamento do servidor.
+func NewShutdownFormModel(parent *mainModel) *formModel {
+	minutesInput := newTextInput("Minutos até o desligamento")
+	reasonInput := newTextInput("Motivo (opcional)")
+	minutesInput.Focus()
+
+	fields := []FormField{
+		{Name: "Minutos", Input: minutesInput},
+		{Name: "Motivo", Input: reasonInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Programar Desligamento",
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				minutes, err := strconv.Atoi(strings.TrimSpace(values["Minutos"]))
+				if err != nil || minutes < 1 {
+					return errorMsg{fmt.Errorf("informe um número de minutos maior que zero")}
+				}
+				if err := parent.sysop.ScheduleShutdown(parent.User, time.Duration(minutes)*time.Minute, values["Motivo"]); err != nil {
+					return errorMsg{err}
+				}
+				return sysopActionMsg{status: fmt.Sprintf("Desligamento programado para daqui a %d minuto(s).", minutes)}
+			}
+		},
+	}
+}
+
 // NewUserFormModel cria um formulário para um novo usuário.
 func NewUserFormModel(parent *mainModel) *formModel {
 	usernameInput := newTextInput("Nome de Usuário")
@@ -151,12 +369,12 @@ func NewUserFormModel(parent *mainModel) *formModel {
 					return statusMessage{success: false, message: "Papel inválido. Use 'user', 'moderator' ou 'admin'."}
 				}
 
-				_, err := database.CreateUser(username, password)
+				_, err := parent.store.CreateUser(username, password)
 				if err != nil {
 					return errorMsg{err}
 				}
 
-				err = database.SetUserRole(username, role)
+				err = parent.store.SetUserRole(username, role)
 				if err != nil {
 					return errorMsg{fmt.Errorf("usuário criado, mas falha ao definir o papel: %w", err)}
 				}
@@ -167,6 +385,71 @@ func NewUserFormModel(parent *mainModel) *formModel {
 	}
 }
 
+// Valores padrão dos convites criados em Configurações.
+const (
+	defaultInviteUses = 1
+	defaultInviteDays = 7
+)
+
+// NewInviteFormModel cria um formulário para gerar um convite, respeitando a cota do papel.
+func NewInviteFormModel(parent *mainModel) *formModel {
+	usesInput := newTextInput(fmt.Sprintf("Usos (padrão: %d)", defaultInviteUses))
+	daysInput := newTextInput(fmt.Sprintf("Validade em dias (padrão: %d; 0 para sem prazo)", defaultInviteDays))
+	usesInput.Focus()
+
+	fields := []FormField{
+		{Name: "Usos", Input: usesInput},
+		{Name: "Dias", Input: daysInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Novo Convite",
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			uses, days := defaultInviteUses, defaultInviteDays
+			if v := strings.TrimSpace(values["Usos"]); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 {
+					return func() tea.Msg { return errorMsg{fmt.Errorf("o número de usos deve ser um inteiro maior que zero")} }
+				}
+				uses = n
+			}
+			if v := strings.TrimSpace(values["Dias"]); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return func() tea.Msg { return errorMsg{fmt.Errorf("a validade deve ser um número de dias")} }
+				}
+				days = n
+			}
+
+			return func() tea.Msg {
+				if quota := parent.registration.InviteQuota(parent.Role); quota >= 0 {
+					open, err := parent.store.CountOpenInviteUses(parent.userID)
+					if err != nil {
+						return errorMsg{err}
+					}
+					if open+uses > quota {
+						return errorMsg{fmt.Errorf("sua cota é de %d cadastro(s) em aberto e restam %d", quota, max(quota-open, 0))}
+					}
+				}
+
+				var expiresAt *time.Time
+				if days > 0 {
+					t := time.Now().AddDate(0, 0, days)
+					expiresAt = &t
+				}
+				invite, err := parent.store.CreateInvite(parent.userID, uses, expiresAt)
+				if err != nil {
+					return errorMsg{err}
+				}
+				return statusMessage{success: true, message: fmt.Sprintf("Convite %s criado.", invite.Code)}
+			}
+		},
+	}
+}
+
 func NewChangePasswordFormModel(parent *mainModel) *formModel {
 	currentPasswordInput := newTextInput("Senha Atual")
 	newPasswordInput := newTextInput("Nova Senha")
@@ -192,7 +475,7 @@ func NewChangePasswordFormModel(parent *mainModel) *formModel {
 				if currentPassword == "" || newPassword == "" {
 					return statusMessage{success: false, message: "Os campos de senha não podem estar vazios."}
 				}
-				err := database.UpdateUserPassword(parent.User, currentPassword, newPassword)
+				err := parent.store.UpdateUserPassword(parent.User, currentPassword, newPassword)
 				if err != nil {
 					return errorMsg{err}
 				}
@@ -202,6 +485,41 @@ func NewChangePasswordFormModel(parent *mainModel) *formModel {
 	}
 }
 
+// NewAddKeyFormModel cria um formulário para cadastrar uma chave SSH do usuário atual.
+func NewAddKeyFormModel(parent *mainModel) *formModel {
+	keyArea := newTextArea("ssh-ed25519 AAAA... usuario@maquina")
+	keyArea.(*TextArea).CharLimit = 16384
+	commentInput := newTextInput("Comentário (opcional)")
+
+	keyArea.Focus()
+
+	fields := []FormField{
+		{Name: "Chave", Input: keyArea},
+		{Name: "Comentário", Input: commentInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      "Adicionar Chave SSH",
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			authorizedKey := values["Chave"]
+			comment := values["Comentário"]
+			return func() tea.Msg {
+				if strings.TrimSpace(authorizedKey) == "" {
+					return statusMessage{success: false, message: "A chave não pode estar vazia."}
+				}
+				key, err := parent.store.AddUserKey(parent.User, authorizedKey, comment)
+				if err != nil {
+					return statusMessage{success: false, message: err.Error()}
+				}
+				return statusMessage{success: true, message: fmt.Sprintf("Chave %s adicionada com sucesso!", key.Fingerprint)}
+			}
+		},
+	}
+}
+
 // NewForumFormModel cria um formulário para um novo fórum.
 // NewEditForumFormModel cria um formulário para editar um fórum existente.
 func NewEditForumFormModel(parent *mainModel, forum *database.Forum) *formModel {
@@ -225,7 +543,7 @@ func NewEditForumFormModel(parent *mainModel, forum *database.Forum) *formModel
 		focusIndex: 0,
 		submitAction: func(values map[string]string) tea.Cmd {
 			return func() tea.Msg {
-				err := database.UpdateForum(forum.ID, values["Nome"], values["Descrição"])
+				err := parent.store.UpdateForum(forum.ID, values["Nome"], values["Descrição"])
 				if err != nil {
 					return statusMessage{success: false, message: "Erro ao atualizar fórum: " + err.Error()}
 				}
@@ -235,6 +553,75 @@ func NewEditForumFormModel(parent *mainModel, forum *database.Forum) *formModel
 	}
 }
 
+// NewForumGrantFormModel cria um formulário para conceder uma permissão no fórum a um
+// papel ou a um usuário.
+func NewForumGrantFormModel(parent *mainModel, forum *database.Forum, usernames []string) *formModel {
+	permissions := make([]string, len(database.ForumPermissions))
+	for i, p := range database.ForumPermissions {
+		permissions[i] = string(p)
+	}
+	permissionInput := newUsernameInput("Permissão: view, topic, reply ou moderate", permissions)
+	roleInput := newUsernameInput("Papel: user, moderator ou admin (ou deixe vazio)", []string{"user", "moderator", "admin"})
+	userInput := newUsernameInput("Usuário (ou deixe vazio)", usernames)
+	permissionInput.Focus()
+
+	fields := []FormField{
+		{Name: "Permissão", Input: permissionInput},
+		{Name: "Papel", Input: roleInput},
+		{Name: "Usuário", Input: userInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      fmt.Sprintf("Conceder Permissão em '%s'", forum.Name),
+		fields:     fields,
+		focusIndex: 0,
+		hint:       "Preencha o papel ou o usuário, não os dois. moderate permite apagar tópicos e posts.",
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				perm, err := database.ParseForumPermission(strings.TrimSpace(values["Permissão"]))
+				if err != nil {
+					return errorMsg{err}
+				}
+				grant := database.ForumGrant{Permission: perm, Role: strings.TrimSpace(values["Papel"]), Username: strings.TrimSpace(values["Usuário"])}
+				if err := parent.store.GrantForumPermission(forum.ID, perm, grant.Role, grant.Username); err != nil {
+					return errorMsg{err}
+				}
+				return forumAccessActionMsg{status: fmt.Sprintf("Permissão de %s concedida ao %s.", perm.Label(), grant.Subject())}
+			}
+		},
+	}
+}
+
+// NewForumModeratorFormModel cria um formulário para designar um moderador do fórum.
+func NewForumModeratorFormModel(parent *mainModel, forum *database.Forum, usernames []string) *formModel {
+	userInput := newUsernameInput("Usuário", usernames)
+	userInput.Focus()
+
+	fields := []FormField{
+		{Name: "Usuário", Input: userInput},
+	}
+
+	return &formModel{
+		parent:     parent,
+		title:      fmt.Sprintf("Designar Moderador de '%s'", forum.Name),
+		fields:     fields,
+		focusIndex: 0,
+		submitAction: func(values map[string]string) tea.Cmd {
+			return func() tea.Msg {
+				username := strings.TrimSpace(values["Usuário"])
+				if username == "" {
+					return errorMsg{fmt.Errorf("informe o usuário")}
+				}
+				if err := parent.store.AddForumModerator(forum.ID, username); err != nil {
+					return errorMsg{err}
+				}
+				return forumAccessActionMsg{status: fmt.Sprintf("%s agora modera '%s'.", username, forum.Name)}
+			}
+		},
+	}
+}
+
 func NewForumFormModel(parent *mainModel, callback func(map[string]string) tea.Cmd) *formModel {
 	nameInput := newTextInput("Nome do Fórum")
 	descArea := newTextArea("Descrição do Fórum")
@@ -277,10 +664,14 @@ func (m *formModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyCtrlS: // Usar Ctrl+S para submeter.
 			return m, m.submit()
 		case tea.KeyTab, tea.KeyShiftTab:
+			// Em campos com autocompletar, Tab aceita a sugestão antes de mudar de campo.
+			if ti, ok := m.fields[m.focusIndex].Input.(*TextInput); ok && msg.Type == tea.KeyTab && ti.pendingSuggestion() {
+				break
+			}
 			if msg.String() == "tab" {
-					cmd = m.nextInput()
+				cmd = m.nextInput()
 			} else {
-					cmd = m.prevInput()
+				cmd = m.prevInput()
 			}
 			return m, cmd
 		case tea.KeyCtrlC, tea.KeyEsc:
@@ -311,8 +702,8 @@ func (m *formModel) prevInput() tea.Cmd {
 	m.fields[m.focusIndex].Input.Blur()
 	m.focusIndex--
 	if m.focusIndex < 0 {
-			m.focusIndex = len(m.fields) - 1
-		}
+		m.focusIndex = len(m.fields) - 1
+	}
 	return m.fields[m.focusIndex].Input.Focus()
 }
 
@@ -325,6 +716,9 @@ func (m *formModel) View() string {
 			b.WriteString("\n")
 		}
 	}
+	if m.hint != "" {
+		b.WriteString("\n\n" + m.parent.styles.footer.Render(m.hint))
+	}
 	b.WriteString("\n\n(pressione Ctrl+S para submeter, Esc para cancelar)")
 	return b.String()
 }
@@ -7,7 +7,7 @@ import (
 )
 
 // TextInput é um wrapper para textinput.Model que implementa formInput.
-type TextInput struct{
+type TextInput struct {
 	textinput.Model
 }
 
@@ -17,8 +17,14 @@ func (ti *TextInput) Update(msg tea.Msg) (formInput, tea.Cmd) {
 	return ti, cmd
 }
 
+// pendingSuggestion informa se há uma sugestão de autocompletar que completa o valor digitado.
+func (ti *TextInput) pendingSuggestion() bool {
+	suggestion := ti.CurrentSuggestion()
+	return ti.ShowSuggestions && suggestion != "" && suggestion != ti.Value()
+}
+
 // TextArea é um wrapper para textarea.Model que implementa formInput.
-type TextArea struct{
+type TextArea struct {
 	textarea.Model
 }
 
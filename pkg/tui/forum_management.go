@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"modern-bbs/internal/database"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,16 +11,38 @@ import (
 
 // forumManagementModel gerencia a tela de gerenciamento de fóruns.
 type forumManagementModel struct {
-	parent           *mainModel
-	forums           []database.Forum
-	cursor           int
-	keys             *KeyMap
-	navigateToForm   bool
-	navigateToEditForm bool
-	selectedForum    *database.Forum
-	confirmingDelete bool
+	parent              *mainModel
+	forums              []database.Forum
+	cursor              int
+	keys                *KeyMap
+	navigateToForm      bool
+	navigateToEditForm  bool
+	selectedForum       *database.Forum
+	navigateToTrashForm bool
+
+	// Permissões do fórum selecionado (tecla p).
+	managingPermissions bool
+	grants              []database.ForumGrant
+	grantCursor         int
+	confirmingRevoke    bool
+	navigateToGrantForm bool
+
+	// Moderadores do fórum selecionado (tecla m).
+	managingModerators      bool
+	moderators              []database.User
+	moderatorCursor         int
+	confirmingUnassign      bool
+	navigateToModeratorForm bool
 }
 
+type forumGrantsLoadedMsg struct{ grants []database.ForumGrant }
+
+type forumModeratorsLoadedMsg struct{ moderators []database.User }
+
+// forumAccessActionMsg informa o resultado de uma alteração nas permissões ou nos
+// moderadores do fórum selecionado.
+type forumAccessActionMsg struct{ status string }
+
 // NewForumManagementModel cria um novo modelo para a tela de gerenciamento de fóruns.
 func NewForumManagementModel(parent *mainModel) *forumManagementModel {
 	return &forumManagementModel{
@@ -31,7 +54,7 @@ func NewForumManagementModel(parent *mainModel) *forumManagementModel {
 // Init carrega os fóruns do banco de dados.
 func (m *forumManagementModel) Init() tea.Cmd {
 	return func() tea.Msg {
-		forums, err := database.GetAllForums()
+		forums, err := m.parent.store.GetAllForums()
 		if err != nil {
 			return errorMsg{err}
 		}
@@ -39,34 +62,50 @@ func (m *forumManagementModel) Init() tea.Cmd {
 	}
 }
 
+// loadGrantsCmd carrega as permissões do fórum selecionado.
+func (m *forumManagementModel) loadGrantsCmd() tea.Msg {
+	grants, err := m.parent.store.GetForumGrants(m.selectedForum.ID)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return forumGrantsLoadedMsg{grants}
+}
+
+// loadModeratorsCmd carrega os moderadores do fórum selecionado.
+func (m *forumManagementModel) loadModeratorsCmd() tea.Msg {
+	moderators, err := m.parent.store.GetForumModerators(m.selectedForum.ID)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return forumModeratorsLoadedMsg{moderators}
+}
+
+// refreshCmd recarrega a tela aberta depois de uma alteração nas permissões ou nos moderadores.
+func (m *forumManagementModel) refreshCmd() tea.Cmd {
+	if m.managingModerators {
+		return m.loadModeratorsCmd
+	}
+	return m.loadGrantsCmd
+}
+
 // Update processa as mensagens para o modelo.
 func (m *forumManagementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case forumsLoadedMsg:
 		m.forums = msg.forums
+	case forumGrantsLoadedMsg:
+		m.grants = msg.grants
+		m.grantCursor = max(min(m.grantCursor, len(m.grants)-1), 0)
+	case forumModeratorsLoadedMsg:
+		m.moderators = msg.moderators
+		m.moderatorCursor = max(min(m.moderatorCursor, len(m.moderators)-1), 0)
 	case tea.KeyMsg:
-		if m.confirmingDelete {
-			switch msg.String() {
-			case "s", "S":
-				if len(m.forums) > 0 {
-					selectedForumID := m.forums[m.cursor].ID
-					cmd := func() tea.Msg {
-						err := database.DeleteForum(selectedForumID)
-						if err != nil {
-							return statusMessage{success: false, message: "Erro ao deletar fórum: " + err.Error()}
-						}
-						// Recarrega a lista de fóruns após a deleção
-						return m.Init()()
-					}
-					m.confirmingDelete = false
-					return m, cmd
-				}
-			case "n", "N":
-				m.confirmingDelete = false
-				return m, nil
-			}
+		if m.managingPermissions {
+			return m.updatePermissions(msg)
+		}
+		if m.managingModerators {
+			return m.updateModerators(msg)
 		}
-
 		switch {
 		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
@@ -88,7 +127,40 @@ func (m *forumManagementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.Delete):
 			if len(m.forums) > 0 {
-				m.confirmingDelete = true
+				m.selectedForum = &m.forums[m.cursor]
+				m.navigateToTrashForm = true
+			}
+			return m, nil
+
+		case msg.String() == "v": // Abrir ou fechar o fórum aos visitantes
+			if len(m.forums) > 0 {
+				forum := m.forums[m.cursor]
+				return m, func() tea.Msg {
+					if err := m.parent.store.SetForumGuestAccess(forum.ID, !forum.GuestAccess); err != nil {
+						return errorMsg{err}
+					}
+					return m.Init()()
+				}
+			}
+			return m, nil
+
+		case msg.String() == "p": // Permissões do fórum
+			if len(m.forums) > 0 {
+				m.selectedForum = &m.forums[m.cursor]
+				m.managingPermissions = true
+				m.grants = nil
+				m.grantCursor = 0
+				return m, m.loadGrantsCmd
+			}
+			return m, nil
+
+		case msg.String() == "m": // Moderadores do fórum
+			if len(m.forums) > 0 {
+				m.selectedForum = &m.forums[m.cursor]
+				m.managingModerators = true
+				m.moderators = nil
+				m.moderatorCursor = 0
+				return m, m.loadModeratorsCmd
 			}
 			return m, nil
 
@@ -99,21 +171,181 @@ func (m *forumManagementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updatePermissions lida com as teclas na tela de permissões do fórum.
+func (m *forumManagementModel) updatePermissions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmingRevoke {
+		switch msg.String() {
+		case "s", "S":
+			m.confirmingRevoke = false
+			if len(m.grants) == 0 {
+				return m, nil
+			}
+			g := m.grants[m.grantCursor]
+			return m, func() tea.Msg {
+				if err := m.parent.store.RevokeForumPermission(g.ForumID, g.Permission, g.Role, g.Username); err != nil {
+					return errorMsg{err}
+				}
+				return forumAccessActionMsg{status: fmt.Sprintf("Permissão de %s revogada do %s.", g.Permission.Label(), g.Subject())}
+			}
+		case "n", "N":
+			m.confirmingRevoke = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.grantCursor > 0 {
+			m.grantCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.grantCursor < len(m.grants)-1 {
+			m.grantCursor++
+		}
+	case key.Matches(msg, m.keys.New):
+		m.navigateToGrantForm = true
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.grants) > 0 {
+			m.confirmingRevoke = true
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.managingPermissions = false
+		m.confirmingRevoke = false
+	}
+	return m, nil
+}
+
+// updateModerators lida com as teclas na tela de moderadores do fórum.
+func (m *forumManagementModel) updateModerators(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmingUnassign {
+		switch msg.String() {
+		case "s", "S":
+			m.confirmingUnassign = false
+			if len(m.moderators) == 0 {
+				return m, nil
+			}
+			forum, username := m.selectedForum, m.moderators[m.moderatorCursor].Username
+			return m, func() tea.Msg {
+				if err := m.parent.store.RemoveForumModerator(forum.ID, username); err != nil {
+					return errorMsg{err}
+				}
+				return forumAccessActionMsg{status: fmt.Sprintf("%s não modera mais '%s'.", username, forum.Name)}
+			}
+		case "n", "N":
+			m.confirmingUnassign = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.moderatorCursor > 0 {
+			m.moderatorCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.moderatorCursor < len(m.moderators)-1 {
+			m.moderatorCursor++
+		}
+	case key.Matches(msg, m.keys.New):
+		m.navigateToModeratorForm = true
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.moderators) > 0 {
+			m.confirmingUnassign = true
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.managingModerators = false
+		m.confirmingUnassign = false
+	}
+	return m, nil
+}
+
 // View renderiza a tela de gerenciamento de fóruns.
 func (m *forumManagementModel) View() string {
+	if m.managingPermissions {
+		return m.viewPermissions()
+	}
+	if m.managingModerators {
+		return m.viewModerators()
+	}
 	body := "Gerenciamento de Fóruns:\n\n"
 	for i, forum := range m.forums {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
 		}
-		body += fmt.Sprintf("%s %s\n", cursor, forum.Name)
+		name := forum.Name
+		if forum.GuestAccess {
+			name += " (aberto a visitantes)"
+		}
+		body += fmt.Sprintf("%s %s\n", cursor, name)
+	}
+
+	return body
+}
+
+// viewPermissions lista as permissões concedidas no fórum selecionado. As permissões
+// sem concessões seguem o padrão de cada papel.
+func (m *forumManagementModel) viewPermissions() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Permissões do fórum '%s':\n\n", m.selectedForum.Name))
+
+	if len(m.grants) == 0 {
+		b.WriteString("Nenhuma permissão concedida: o fórum segue o padrão dos papéis.\n")
+	}
+	for i, g := range m.grants {
+		line := fmt.Sprintf("%-14s %s", g.Permission.Label(), g.Subject())
+		if m.grantCursor == i {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
 	}
 
-	if m.confirmingDelete && len(m.forums) > 0 {
-		forumName := m.forums[m.cursor].Name
-		body += fmt.Sprintf("\n\nTem certeza que deseja deletar o fórum '%s'? (s/n)", forumName)
+	b.WriteString("\nPadrão sem concessões: todos veem e respondem; moderadores criam tópicos e moderam.\n")
+	b.WriteString("Com concessões, só quem as recebeu tem a permissão. Administradores e moderadores do fórum têm todas.\n")
+
+	if m.confirmingRevoke && len(m.grants) > 0 {
+		g := m.grants[m.grantCursor]
+		b.WriteString(fmt.Sprintf("\nTem certeza que deseja revogar a permissão de %s do %s? (s/n)\n", g.Permission.Label(), g.Subject()))
 	}
 
-	return body
+	return b.String()
+}
+
+// viewModerators lista os moderadores do fórum selecionado.
+func (m *forumManagementModel) viewModerators() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Moderadores do fórum '%s':\n\n", m.selectedForum.Name))
+
+	if len(m.moderators) == 0 {
+		b.WriteString("Nenhum moderador designado.\n")
+	}
+	for i, u := range m.moderators {
+		line := fmt.Sprintf("%s (%s)", u.Username, u.Role)
+		if m.moderatorCursor == i {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nOs moderadores do fórum têm todas as permissões nele, qualquer que seja o papel.\n")
+
+	if m.confirmingUnassign && len(m.moderators) > 0 {
+		b.WriteString(fmt.Sprintf("\nTem certeza que deseja retirar %s da moderação? (s/n)\n", m.moderators[m.moderatorCursor].Username))
+	}
+
+	return b.String()
+}
+
+func (m *forumManagementModel) helpView() string {
+	if m.managingPermissions {
+		return "n conceder • d revogar • esc voltar"
+	}
+	if m.managingModerators {
+		return "n designar • d retirar • esc voltar"
+	}
+	return "n novo • e editar • d deletar • p permissões • m moderadores • v abrir/fechar aos visitantes • esc voltar"
 }
@@ -3,12 +3,12 @@ package tui
 import (
 	"fmt"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-
 // forumsModel representa a visão da lista de fóruns.
 type forumsModel struct {
 	parent      *mainModel
@@ -17,8 +17,11 @@ type forumsModel struct {
 	quitting    bool
 	navToTopics *database.Forum // Fórum selecionado para navegação
 	keys        *KeyMap
+	unread      map[int64]int // Posts não lidos por fórum
 }
 
+type forumUnreadLoadedMsg struct{ counts map[int64]int }
+
 // NewForumsModel cria um novo modelo para a visão de fóruns.
 func NewForumsModel(parent *mainModel) *forumsModel {
 	return &forumsModel{
@@ -29,12 +32,32 @@ func NewForumsModel(parent *mainModel) *forumsModel {
 
 func (m *forumsModel) Init() tea.Cmd {
 	m.parent.isLoading = true
-	return m.loadForumsCmd
+	// Os visitantes não têm estado de leitura.
+	if m.parent.readOnly() {
+		return m.loadForumsCmd
+	}
+	return tea.Batch(m.loadForumsCmd, m.loadUnreadCmd)
+}
+
+// loadUnreadCmd carrega a quantidade de posts não lidos em cada fórum.
+func (m *forumsModel) loadUnreadCmd() tea.Msg {
+	counts, err := m.parent.store.GetForumUnreadCounts(m.parent.userID)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return forumUnreadLoadedMsg{counts}
 }
 
-// loadForumsCmd é um comando que carrega os fóruns do banco de dados.
+// loadForumsCmd é um comando que carrega os fóruns do banco de dados. Cada usuário vê
+// apenas os fóruns que tem permissão de ver, e os visitantes, os abertos a eles.
 func (m *forumsModel) loadForumsCmd() tea.Msg {
-	forums, err := database.GetAllForums()
+	var forums []database.Forum
+	var err error
+	if m.parent.readOnly() {
+		forums, err = m.parent.store.GetGuestForums()
+	} else {
+		forums, err = m.parent.store.GetVisibleForums(m.parent.userID)
+	}
 	if err != nil {
 		return errorMsg{err}
 	}
@@ -46,6 +69,10 @@ func (m *forumsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case forumsLoadedMsg:
 		m.parent.isLoading = false
 		m.forums = msg.forums
+		m.cursor = max(min(m.cursor, len(m.forums)-1), 0)
+		return m, nil
+	case forumUnreadLoadedMsg:
+		m.unread = msg.counts
 		return m, nil
 	case tea.KeyMsg:
 		switch {
@@ -73,6 +100,27 @@ func (m *forumsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleEvent atualiza as contagens de não lidos quando outra sessão altera tópicos ou
+// posts, e a lista quando um fórum é apagado ou restaurado.
+func (m *forumsModel) handleEvent(ev events.Event) tea.Cmd {
+	switch ev.Kind {
+	case events.ForumDeleted:
+		return m.loadForumsCmd
+	case events.TrashRestored:
+		if ev.TopicID == 0 {
+			return tea.Batch(m.loadForumsCmd, m.loadUnreadCmd)
+		}
+	case events.TrashPurged:
+		// Os itens removidos já estavam ocultos.
+		return nil
+	}
+	// Editar um post não muda as contagens de não lidos.
+	if m.parent.readOnly() || ev.Kind == events.PostEdited || (ev.Kind == events.PostCreated || ev.Kind == events.TopicCreated) && ev.UserID == int(m.parent.userID) {
+		return nil
+	}
+	return m.loadUnreadCmd
+}
+
 func (m *forumsModel) View() string {
 	if m.quitting {
 		return ""
@@ -80,12 +128,16 @@ func (m *forumsModel) View() string {
 
 	body := ""
 	for i, forum := range m.forums {
+		name := forum.Name
+		if n := m.unread[forum.ID]; n > 0 {
+			name += fmt.Sprintf(" (%d novos)", n)
+		}
 		cursor := " " // Espaço em branco para o cursor não selecionado
 		if m.cursor == i {
 			cursor = ">" // Cursor para o item selecionado
-			body += selectedItemStyle.Render(fmt.Sprintf("%s %s", cursor, forum.Name))
+			body += m.parent.styles.selectedItem.Render(fmt.Sprintf("%s %s", cursor, name))
 		} else {
-			body += itemStyle.Render(fmt.Sprintf("%s %s", cursor, forum.Name))
+			body += m.parent.styles.item.Render(fmt.Sprintf("%s %s", cursor, name))
 		}
 		body += "\n"
 	}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type invitesLoadedMsg struct{ invites []database.Invite }
+
+// loadInvitesCmd carrega os convites criados pelo usuário.
+func (m *settingsModel) loadInvitesCmd() tea.Msg {
+	invites, err := m.parent.store.GetInvitesByUser(m.parent.userID)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return invitesLoadedMsg{invites: invites}
+}
+
+// updateInvites lida com as teclas na tela de convites.
+func (m *settingsModel) updateInvites(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmingRevoke {
+		switch msg.String() {
+		case "s", "S":
+			m.confirmingRevoke = false
+			if len(m.invites) == 0 {
+				return m, nil
+			}
+			code := m.invites[m.inviteCursor].Code
+			return m, func() tea.Msg {
+				if err := m.parent.store.RevokeInvite(code); err != nil {
+					return statusMessage{success: false, message: err.Error()}
+				}
+				return statusMessage{success: true, message: fmt.Sprintf("Convite %s revogado.", code)}
+			}
+		case "n", "N":
+			m.confirmingRevoke = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.inviteCursor > 0 {
+			m.inviteCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.inviteCursor < len(m.invites)-1 {
+			m.inviteCursor++
+		}
+	case key.Matches(msg, m.keys.New):
+		m.parent.currentView = formView
+		m.parent.formModel = NewInviteFormModel(m.parent)
+		return m.parent, m.parent.formModel.Init()
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.invites) > 0 {
+			m.confirmingRevoke = true
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.managingInvites = false
+		m.confirmingRevoke = false
+	}
+	return m, nil
+}
+
+func (m *settingsModel) viewInvites() string {
+	var b strings.Builder
+	b.WriteString("Seus convites:\n\n")
+
+	if len(m.invites) == 0 {
+		b.WriteString("Nenhum convite criado.\n")
+	}
+	now := time.Now()
+	for i, invite := range m.invites {
+		line := fmt.Sprintf("%s  %d/%d uso(s), %s", invite.Code, invite.Uses, invite.MaxUses, inviteValidity(&invite, now))
+		if m.inviteCursor == i {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	if quota := m.parent.registration.InviteQuota(m.parent.Role); quota > 0 {
+		b.WriteString(fmt.Sprintf("\nSua cota: até %d cadastro(s) em aberto nos convites ativos.\n", quota))
+	}
+	b.WriteString("Quem for convidado se cadastra com o login de cadastro e informa o código.\n")
+
+	if m.confirmingRevoke && len(m.invites) > 0 {
+		b.WriteString(fmt.Sprintf("\nTem certeza que deseja revogar o convite %s? (s/n)\n", m.invites[m.inviteCursor].Code))
+	}
+	return b.String()
+}
+
+// inviteValidity descreve a situação de um convite: esgotado, expirado ou até quando vale.
+func inviteValidity(invite *database.Invite, now time.Time) string {
+	switch {
+	case invite.Uses >= invite.MaxUses:
+		return "esgotado"
+	case invite.ExpiresAt != nil && !now.Before(*invite.ExpiresAt):
+		return "expirado"
+	case invite.ExpiresAt != nil:
+		return "válido até " + invite.ExpiresAt.Local().Format("02/01/2006 15:04")
+	}
+	return "sem prazo"
+}
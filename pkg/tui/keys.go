@@ -8,22 +8,35 @@ import (
 
 // KeyMap define um conjunto de atalhos de teclado para a aplicação.
 type KeyMap struct {
-	Up    key.Binding
-	Down  key.Binding
-	Enter key.Binding
-	Back  key.Binding
-	Quit  key.Binding
-	New   key.Binding // Para criar novos itens (tópicos/posts)
-	Delete key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Enter    key.Binding
+	Back     key.Binding
+	Quit     key.Binding
+	New      key.Binding // Para criar novos itens (tópicos/posts)
+	Delete   key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	HalfUp   key.Binding
+	HalfDown key.Binding
+	Raw      key.Binding
+	Unread   key.Binding
+	Reply    key.Binding
+	Block    key.Binding
+	Edit     key.Binding
+	History  key.Binding
+	Restore  key.Binding
 }
 
 // DefaultKeyMap é a instância global dos atalhos de teclado.
 var DefaultKeyMap = &KeyMap{
-	Up: key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "mover para cima")),
-	Down: key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "mover para baixo")),
+	Up:    key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "mover para cima")),
+	Down:  key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "mover para baixo")),
 	Enter: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "selecionar")),
-	Back: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "voltar")),
-	Quit: key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q/ctrl+c", "sair")),
+	Back:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "voltar")),
+	Quit:  key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q/ctrl+c", "sair")),
 	New: key.NewBinding(
 		key.WithKeys("n"),
 		key.WithHelp("n", "novo"),
@@ -32,11 +45,63 @@ var DefaultKeyMap = &KeyMap{
 		key.WithKeys("d"),
 		key.WithHelp("d", "deletar"),
 	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "página anterior"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdn", "próxima página"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g", "início"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G", "fim"),
+	),
+	HalfUp: key.NewBinding(
+		key.WithKeys("ctrl+u"),
+		key.WithHelp("ctrl+u", "meia tela acima"),
+	),
+	HalfDown: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "meia tela abaixo"),
+	),
+	Raw: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "ver fonte"),
+	),
+	Unread: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "primeiro não lido"),
+	),
+	Reply: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "responder"),
+	),
+	Block: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "bloquear"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "editar"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "histórico"),
+	),
+	Restore: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "restaurar"),
+	),
 }
 
 // HelpView retorna uma string com a ajuda dos atalhos de teclado.
 func (k *KeyMap) HelpView() string {
-	return fmt.Sprintf("%s: %s, %s: %s, %s: %s, %s: %s, %s: %s, %s: %s, %s: %s",
+	return fmt.Sprintf("%s: %s, %s: %s, %s: %s, %s: %s, %s: %s, %s: %s",
 		k.Up.Help().Key, k.Up.Help().Desc,
 		k.Down.Help().Key, k.Down.Help().Desc,
 		k.Enter.Help().Key, k.Enter.Help().Desc,
@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// markdownRenderer converte o Markdown dos posts em texto para o terminal. Suporta
+// títulos, listas, citações, blocos de código, linhas horizontais, links, ênfase e
+// código inline. Em modo plain (terminais sem suporte a estilos), a estrutura é
+// mantida apenas com texto, sem sequências de escape.
+type markdownRenderer struct {
+	styles *styles
+	plain  bool
+}
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRule     = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+	mdListItem = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdFence    = regexp.MustCompile("^\\s*(```|~~~)")
+
+	mdLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdAutolink = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	mdStrong   = regexp.MustCompile(`\*\*([^*\s](?:[^*]*[^*\s])?)\*\*|__([^_\s](?:[^_]*[^_\s])?)__`)
+	mdEmphasis = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+)
+
+// render converte o texto Markdown em linhas de no máximo width colunas.
+func (r *markdownRenderer) render(src string, width int) string {
+	width = max(width, 10)
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var out []string
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			out = append(out, r.wrap(r.inline(strings.Join(paragraph, " ")), width))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+		case mdFence.MatchString(line):
+			flush()
+			fence := mdFence.FindStringSubmatch(line)[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			out = append(out, r.codeBlock(code, width))
+		case mdHeading.MatchString(line):
+			flush()
+			m := mdHeading.FindStringSubmatch(line)
+			out = append(out, r.heading(len(m[1]), m[2], width))
+		case mdRule.MatchString(line):
+			flush()
+			out = append(out, r.style(r.styles.mdQuote, strings.Repeat(r.ruleChar(), width)))
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			i--
+			out = append(out, r.blockquote(strings.Join(quote, "\n"), width))
+		case mdListItem.MatchString(line):
+			flush()
+			m := mdListItem.FindStringSubmatch(line)
+			out = append(out, r.listItem(len(m[1])/2, m[2], m[3], width))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+func (r *markdownRenderer) heading(level int, text string, width int) string {
+	text = r.inlinePlain(text)
+	if r.plain {
+		if level <= 2 {
+			underline := "="
+			if level == 2 {
+				underline = "-"
+			}
+			return ansi.Wrap(text, width, "") + "\n" + strings.Repeat(underline, min(ansi.StringWidth(text), width))
+		}
+		return ansi.Wrap(strings.Repeat("#", level)+" "+text, width, "")
+	}
+	if level == 1 {
+		text = strings.ToUpper(text)
+	}
+	return r.styles.mdHeading.Render(ansi.Wrap(text, width, ""))
+}
+
+func (r *markdownRenderer) codeBlock(code []string, width int) string {
+	rendered := make([]string, len(code))
+	for i, line := range code {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		rendered[i] = r.style(r.styles.mdCode, ansi.Hardwrap("  "+line, width, true))
+	}
+	return strings.Join(rendered, "\n")
+}
+
+func (r *markdownRenderer) blockquote(text string, width int) string {
+	inner := r.render(text, width-2)
+	bar := "│ "
+	if r.plain {
+		bar = "> "
+	}
+	lines := strings.Split(inner, "\n")
+	for i, line := range lines {
+		lines[i] = r.style(r.styles.mdQuote, bar) + r.style(r.styles.mdQuote, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *markdownRenderer) listItem(depth int, marker, text string, width int) string {
+	bullet := "•"
+	if r.plain {
+		bullet = "-"
+	}
+	if marker[0] >= '0' && marker[0] <= '9' {
+		bullet = strings.TrimRight(marker, ".)") + "."
+	}
+
+	indent := strings.Repeat("  ", depth)
+	prefix := indent + bullet + " "
+	hanging := strings.Repeat(" ", ansi.StringWidth(prefix))
+
+	lines := strings.Split(r.wrap(r.inline(text), width-len(hanging)), "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = prefix + lines[i]
+		} else {
+			lines[i] = hanging + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline aplica a formatação de código, links e ênfase dentro de uma linha.
+// O conteúdo entre crases é mantido literalmente.
+func (r *markdownRenderer) inline(text string) string {
+	parts := strings.Split(text, "`")
+	var b strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			if r.plain {
+				b.WriteString(part)
+			} else {
+				b.WriteString(r.styles.mdCode.Render(part))
+			}
+		case i%2 == 1:
+			// Crase sem par: mantém o texto como está.
+			b.WriteString("`" + r.inlineText(part))
+		default:
+			b.WriteString(r.inlineText(part))
+		}
+	}
+	return b.String()
+}
+
+// inlinePlain remove a marcação inline, para textos que recebem um estilo próprio (ex.: títulos).
+func (r *markdownRenderer) inlinePlain(text string) string {
+	plain := markdownRenderer{styles: r.styles, plain: true}
+	return plain.inline(text)
+}
+
+func (r *markdownRenderer) inlineText(text string) string {
+	text = mdAutolink.ReplaceAllStringFunc(text, func(s string) string {
+		url := mdAutolink.FindStringSubmatch(s)[1]
+		if r.plain {
+			return url
+		}
+		return r.styles.mdLink.Render(url)
+	})
+	text = mdLink.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		if r.plain {
+			return fmt.Sprintf("%s <%s>", m[1], m[2])
+		}
+		return r.styles.mdLink.Render(m[1]) + " " + r.styles.footer.Render("("+m[2]+")")
+	})
+	text = mdStrong.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdStrong.FindStringSubmatch(s)
+		return r.style(r.styles.mdStrong, m[1]+m[2])
+	})
+	text = mdEmphasis.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdEmphasis.FindStringSubmatch(s)
+		return r.style(r.styles.mdEmphasis, m[1]+m[2])
+	})
+	return text
+}
+
+// wrap quebra o texto na largura, preservando as sequências de escape dos estilos.
+func (r *markdownRenderer) wrap(text string, width int) string {
+	return ansi.Wrap(text, max(width, 1), "")
+}
+
+func (r *markdownRenderer) style(s lipgloss.Style, text string) string {
+	if r.plain {
+		return text
+	}
+	return s.Render(text)
+}
+
+func (r *markdownRenderer) ruleChar() string {
+	if r.plain {
+		return "-"
+	}
+	return "─"
+}
@@ -0,0 +1,457 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+type messagesMode int
+
+const (
+	inboxMode messagesMode = iota
+	conversationMode
+	blockedMode
+)
+
+// messagesModel representa a caixa de mensagens privadas: a lista de conversas,
+// a leitura de uma conversa e a lista de remetentes bloqueados.
+type messagesModel struct {
+	keys     *KeyMap
+	parent   *mainModel
+	mode     messagesMode
+	quitting bool
+
+	conversations []database.Conversation
+	cursor        int
+	loaded        bool
+
+	// Conversa aberta
+	conversation *database.Conversation
+	messages     []database.Message
+	viewport     viewport.Model
+	markdown     *markdownRenderer
+
+	blocked       []database.User
+	blockedCursor int
+
+	// confirming guarda a pergunta da ação que aguarda confirmação (s/n), e confirmAction a ação.
+	confirming    string
+	confirmAction func() tea.Cmd
+
+	// Navegação para os formulários, tratada pelo mainModel
+	composing    bool
+	replying     bool
+	blockingUser bool
+}
+
+type conversationsLoadedMsg struct {
+	conversations []database.Conversation
+	err           error
+}
+
+type conversationMessagesLoadedMsg struct {
+	conversationID int64
+	messages       []database.Message
+	err            error
+}
+
+type blockedUsersLoadedMsg struct {
+	users []database.User
+	err   error
+}
+
+// messageActionMsg sinaliza que uma ação sobre as mensagens (envio, exclusão, bloqueio) foi concluída.
+type messageActionMsg struct{ status string }
+
+// unreadMessagesMsg traz o número de mensagens não lidas exibido no cabeçalho.
+type unreadMessagesMsg struct{ count int }
+
+// NewMessagesModel cria um novo modelo para a caixa de mensagens.
+func NewMessagesModel(parent *mainModel) *messagesModel {
+	m := &messagesModel{
+		keys:     DefaultKeyMap,
+		parent:   parent,
+		viewport: viewport.New(0, 0),
+		markdown: &markdownRenderer{
+			styles: parent.styles,
+			plain:  parent.renderer.ColorProfile() == termenv.Ascii,
+		},
+	}
+	m.setSize(parent.width, parent.height)
+	return m
+}
+
+// Init recarrega os dados do modo atual.
+func (m *messagesModel) Init() tea.Cmd {
+	switch m.mode {
+	case conversationMode:
+		return tea.Batch(m.loadConversationsCmd(), m.loadMessagesCmd(m.conversation.ID))
+	case blockedMode:
+		return m.loadBlockedCmd()
+	}
+	return m.loadConversationsCmd()
+}
+
+func (m *messagesModel) setSize(width, height int) {
+	if width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+	m.viewport.Width = width
+	m.viewport.Height = max(height-10, 3)
+	m.render()
+}
+
+func (m *messagesModel) loadConversationsCmd() tea.Cmd {
+	return func() tea.Msg {
+		conversations, err := m.parent.store.GetConversations(m.parent.userID)
+		return conversationsLoadedMsg{conversations: conversations, err: err}
+	}
+}
+
+// loadMessagesCmd carrega as mensagens da conversa e as marca como lidas.
+func (m *messagesModel) loadMessagesCmd(conversationID int64) tea.Cmd {
+	return func() tea.Msg {
+		messages, err := m.parent.store.GetConversationMessages(m.parent.userID, conversationID)
+		if err == nil {
+			err = m.parent.store.MarkConversationRead(m.parent.userID, conversationID)
+		}
+		return conversationMessagesLoadedMsg{conversationID: conversationID, messages: messages, err: err}
+	}
+}
+
+func (m *messagesModel) loadBlockedCmd() tea.Cmd {
+	return func() tea.Msg {
+		users, err := m.parent.store.GetBlockedUsers(m.parent.userID)
+		return blockedUsersLoadedMsg{users: users, err: err}
+	}
+}
+
+func (m *messagesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case conversationsLoadedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		m.conversations = msg.conversations
+		m.loaded = true
+		m.cursor = max(min(m.cursor, len(m.conversations)-1), 0)
+		return m, nil
+	case conversationMessagesLoadedMsg:
+		if msg.err != nil {
+			m.mode = inboxMode
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		if m.conversation == nil || m.conversation.ID != msg.conversationID {
+			return m, nil
+		}
+		m.messages = msg.messages
+		m.render()
+		m.viewport.GotoBottom()
+		// A conversa foi marcada como lida: atualiza o contador do cabeçalho.
+		return m, m.parent.loadUnreadMessagesCmd
+	case blockedUsersLoadedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		m.blocked = msg.users
+		m.blockedCursor = max(min(m.blockedCursor, len(m.blocked)-1), 0)
+		return m, nil
+	case tea.KeyMsg:
+		if m.confirming != "" {
+			switch msg.String() {
+			case "s", "S":
+				action := m.confirmAction
+				m.confirming, m.confirmAction = "", nil
+				return m, action()
+			case "n", "N", "esc":
+				m.confirming, m.confirmAction = "", nil
+			}
+			return m, nil
+		}
+		switch m.mode {
+		case conversationMode:
+			return m.updateConversation(msg)
+		case blockedMode:
+			return m.updateBlocked(msg)
+		}
+		return m.updateInbox(msg)
+	}
+	return m, nil
+}
+
+func (m *messagesModel) updateInbox(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.conversations)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if len(m.conversations) > 0 {
+			c := m.conversations[m.cursor]
+			m.conversation = &c
+			m.messages = nil
+			m.mode = conversationMode
+			m.render()
+			return m, m.loadMessagesCmd(c.ID)
+		}
+	case key.Matches(msg, m.keys.New):
+		m.composing = true
+		return m, nil // O mainModel irá lidar com a navegação
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.conversations) > 0 {
+			m.confirmDelete(m.conversations[m.cursor])
+		}
+	case key.Matches(msg, m.keys.Block):
+		m.mode = blockedMode
+		return m, m.loadBlockedCmd()
+	case key.Matches(msg, m.keys.Back):
+		return m, func() tea.Msg { return navigateBackMsg{} }
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *messagesModel) updateConversation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Reply):
+		m.replying = true
+		return m, nil // O mainModel irá lidar com a navegação
+	case key.Matches(msg, m.keys.Delete):
+		m.confirmDelete(*m.conversation)
+	case key.Matches(msg, m.keys.Block):
+		if len(m.conversation.Participants) > 0 {
+			m.confirmBlock(m.conversation.Participants)
+		}
+	case key.Matches(msg, m.keys.Top):
+		m.viewport.GotoTop()
+	case key.Matches(msg, m.keys.Bottom):
+		m.viewport.GotoBottom()
+	case key.Matches(msg, m.keys.Back):
+		m.mode = inboxMode
+		m.conversation = nil
+		return m, m.loadConversationsCmd()
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	default:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *messagesModel) updateBlocked(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.blockedCursor > 0 {
+			m.blockedCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.blockedCursor < len(m.blocked)-1 {
+			m.blockedCursor++
+		}
+	case key.Matches(msg, m.keys.New):
+		m.blockingUser = true
+		return m, nil // O mainModel irá lidar com a navegação
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.blocked) > 0 {
+			username := m.blocked[m.blockedCursor].Username
+			m.confirming = fmt.Sprintf("Desbloquear %s? (s/n)", username)
+			m.confirmAction = func() tea.Cmd {
+				return m.actionCmd(fmt.Sprintf("%s foi desbloqueado.", username), func() error {
+					return m.parent.store.UnblockUser(m.parent.userID, username)
+				})
+			}
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.mode = inboxMode
+		return m, m.loadConversationsCmd()
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// confirmDelete pede confirmação para excluir a conversa da caixa do usuário.
+func (m *messagesModel) confirmDelete(c database.Conversation) {
+	m.confirming = fmt.Sprintf("Excluir a conversa '%s'? (s/n)", c.Subject)
+	m.confirmAction = func() tea.Cmd {
+		m.mode = inboxMode
+		m.conversation = nil
+		return m.actionCmd("Conversa excluída.", func() error {
+			return m.parent.store.DeleteConversation(m.parent.userID, c.ID)
+		})
+	}
+}
+
+// confirmBlock pede confirmação para bloquear os remetentes informados.
+func (m *messagesModel) confirmBlock(usernames []string) {
+	names := strings.Join(usernames, ", ")
+	m.confirming = fmt.Sprintf("Bloquear as mensagens de %s? (s/n)", names)
+	m.confirmAction = func() tea.Cmd {
+		return m.actionCmd(fmt.Sprintf("%s foi bloqueado.", names), func() error {
+			for _, username := range usernames {
+				if err := m.parent.store.BlockUser(m.parent.userID, username); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// actionCmd executa a ação e informa o resultado ao mainModel.
+func (m *messagesModel) actionCmd(status string, action func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return errorMsg{err}
+		}
+		return messageActionMsg{status: status}
+	}
+}
+
+// render monta o conteúdo do viewport com as mensagens da conversa aberta.
+func (m *messagesModel) render() {
+	if m.conversation == nil {
+		return
+	}
+	width := m.viewport.Width
+	var b strings.Builder
+	for i, msg := range m.messages {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		author := fmt.Sprintf("%s em %s", msg.Sender, msg.CreatedAt.Local().Format("02/01/2006 15:04"))
+		b.WriteString(m.parent.styles.header.Render(author) + "\n")
+		b.WriteString(m.markdown.render(msg.Body, width-2))
+	}
+	m.viewport.SetContent(b.String())
+}
+
+func (m *messagesModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	switch m.mode {
+	case conversationMode:
+		b.WriteString(m.parent.styles.header.Render(m.conversation.Subject) + "\n")
+		b.WriteString(m.parent.styles.footer.Render("Conversa com "+strings.Join(m.conversation.Participants, ", ")) + "\n\n")
+		b.WriteString(m.viewport.View() + "\n")
+	case blockedMode:
+		b.WriteString(m.parent.styles.header.Render("Remetentes bloqueados") + "\n\n")
+		if len(m.blocked) == 0 {
+			b.WriteString("Nenhum usuário bloqueado.\n")
+		}
+		for i, u := range m.blocked {
+			if i == m.blockedCursor {
+				b.WriteString(m.parent.styles.selectedItem.Render("> " + u.Username))
+			} else {
+				b.WriteString(m.parent.styles.item.Render("  " + u.Username))
+			}
+			b.WriteString("\n")
+		}
+	default:
+		b.WriteString(m.viewInbox())
+	}
+
+	if m.confirming != "" {
+		b.WriteString("\n" + m.parent.styles.errorStatusMessage.Render(m.confirming) + "\n")
+	}
+	return b.String()
+}
+
+func (m *messagesModel) viewInbox() string {
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Caixa de Entrada") + "\n\n")
+
+	if !m.loaded {
+		return b.String()
+	}
+	if len(m.conversations) == 0 {
+		b.WriteString("Nenhuma mensagem. Pressione 'n' para escrever uma.\n")
+		return b.String()
+	}
+
+	for i, c := range m.conversations {
+		line := fmt.Sprintf("%s • com %s • %s em %s", c.Subject, strings.Join(c.Participants, ", "),
+			c.LastSender, c.LastMessageAt.Local().Format("02/01/2006 15:04"))
+		if c.Unread > 0 {
+			line = fmt.Sprintf("[%d novas] %s", c.Unread, line)
+		}
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (m *messagesModel) helpView() string {
+	if m.confirming != "" {
+		return "s confirmar • n cancelar"
+	}
+	var help []string
+	switch m.mode {
+	case conversationMode:
+		help = []string{
+			m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " rolar",
+			m.keys.Reply.Help().Key + " " + m.keys.Reply.Help().Desc,
+			m.keys.Delete.Help().Key + " excluir",
+			m.keys.Block.Help().Key + " bloquear remetente",
+			m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		}
+	case blockedMode:
+		help = []string{
+			m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+			m.keys.New.Help().Key + " bloquear usuário",
+			m.keys.Delete.Help().Key + " desbloquear",
+			m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		}
+	default:
+		help = []string{
+			m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+			m.keys.Enter.Help().Key + " ler",
+			m.keys.New.Help().Key + " nova mensagem",
+			m.keys.Delete.Help().Key + " excluir",
+			m.keys.Block.Help().Key + " bloqueados",
+			m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+			m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+		}
+	}
+	return strings.Join(help, " • ")
+}
+
+// usernameSuggestions retorna os nomes de usuário para o autocompletar, sem o próprio usuário.
+// As sugestões são opcionais: em caso de erro, o campo funciona sem elas.
+func usernameSuggestions(store database.Store, exclude string) []string {
+	users, err := store.GetAllUsers()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		if u.Username != exclude {
+			names = append(names, u.Username)
+		}
+	}
+	return names
+}
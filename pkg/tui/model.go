@@ -2,7 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"modern-bbs/internal/chat"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
+	"modern-bbs/internal/session"
 	"strings"
 	"time"
 
@@ -11,14 +14,52 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	headerStyle           = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("77"))
-	selectedItemStyle     = lipgloss.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170")).Background(lipgloss.Color("57"))
-	itemStyle             = lipgloss.NewStyle().PaddingLeft(2)
-	footerStyle           = lipgloss.NewStyle().Faint(true)
-	statusMessageStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))  // Verde
-	errorStatusMessageStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")) // Vermelho
-)
+// styles agrupa os estilos da TUI. Cada sessão cria os seus a partir do próprio renderer,
+// para que o perfil de cores siga o terminal do cliente e não o do servidor.
+type styles struct {
+	header             lipgloss.Style
+	selectedItem       lipgloss.Style
+	item               lipgloss.Style
+	footer             lipgloss.Style
+	statusMessage      lipgloss.Style
+	errorStatusMessage lipgloss.Style
+	adminTitle         lipgloss.Style
+	spinner            lipgloss.Style
+	highlight          lipgloss.Style
+	diffInsert         lipgloss.Style
+	diffDelete         lipgloss.Style
+
+	// Markdown dos posts
+	mdHeading  lipgloss.Style
+	mdCode     lipgloss.Style
+	mdQuote    lipgloss.Style
+	mdLink     lipgloss.Style
+	mdStrong   lipgloss.Style
+	mdEmphasis lipgloss.Style
+}
+
+func newStyles(r *lipgloss.Renderer) *styles {
+	return &styles{
+		header:             r.NewStyle().Bold(true).Foreground(lipgloss.Color("77")),
+		selectedItem:       r.NewStyle().PaddingLeft(2).Foreground(lipgloss.Color("170")).Background(lipgloss.Color("57")),
+		item:               r.NewStyle().PaddingLeft(2),
+		footer:             r.NewStyle().Faint(true),
+		statusMessage:      r.NewStyle().Foreground(lipgloss.Color("2")), // Verde
+		errorStatusMessage: r.NewStyle().Foreground(lipgloss.Color("9")), // Vermelho
+		adminTitle:         r.NewStyle().MarginLeft(2),
+		spinner:            r.NewStyle().Foreground(lipgloss.Color("205")),
+		highlight:          r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")), // Amarelo
+		diffInsert:         r.NewStyle().Foreground(lipgloss.Color("2")),             // Verde
+		diffDelete:         r.NewStyle().Foreground(lipgloss.Color("9")),             // Vermelho
+
+		mdHeading:  r.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+		mdCode:     r.NewStyle().Foreground(lipgloss.Color("203")).Background(lipgloss.Color("236")),
+		mdQuote:    r.NewStyle().Foreground(lipgloss.Color("245")),
+		mdLink:     r.NewStyle().Underline(true).Foreground(lipgloss.Color("39")),
+		mdStrong:   r.NewStyle().Bold(true),
+		mdEmphasis: r.NewStyle().Italic(true),
+	}
+}
 
 type view int
 
@@ -32,6 +73,14 @@ const (
 	userManagementView
 	forumManagementView
 	adminView
+	searchView
+	unreadView
+	messagesView
+	chatView
+	whoView
+	sysopView
+	revisionsView
+	trashView
 )
 
 // Mensagens para comunicação entre modelos e para operações assíncronas.
@@ -55,71 +104,373 @@ type usersLoadedMsg struct{ users []database.User }
 type passwordUpdatedMsg struct{}
 type topicCreatedMsg struct{ forum *database.Forum }
 type postCreatedMsg struct{ topic *database.Topic }
+type postEditedMsg struct{}
 type userCreatedMsg struct{}
 type navigateBackMsg struct{}
 
 // mainModel é o modelo principal que gerencia as visões da aplicação.
 type mainModel struct {
-	User                string
-	Role                string // 'user', 'moderator', 'admin'
-	currentView         view
-	Choices             []string
-	Cursor              int
-	forumsModel         *forumsModel
-	topicsModel         *topicsModel
-	postsModel          *postsModel
-	formModel           *formModel
-	settingsModel       *settingsModel
-	userManagementModel *userManagementModel
+	store                database.Store
+	User                 string
+	userID               int64  // ID do usuário no banco, usado pelo estado de leitura
+	Role                 string // 'user', 'moderator', 'admin' ou GuestRole
+	currentView          view
+	Choices              []string
+	Cursor               int
+	forumsModel          *forumsModel
+	topicsModel          *topicsModel
+	postsModel           *postsModel
+	formModel            *formModel
+	settingsModel        *settingsModel
+	userManagementModel  *userManagementModel
 	forumManagementModel *forumManagementModel
-	adminModel          *adminModel
+	adminModel           *adminModel
+	searchModel          *searchModel
+	unreadModel          *unreadModel
+	messagesModel        *messagesModel
+	chatModel            *chatModel
+	whoModel             *whoModel
+	sysopModel           *sysopModel
+	revisionsModel       *revisionsModel
+	trashModel           *trashModel
 
 	// UX Enhancements
-	spinner       spinner.Model
-	isLoading     bool
-	statusMessage string
-	breadcrumbs   []string
+	spinner        spinner.Model
+	isLoading      bool
+	statusMessage  string
+	breadcrumbs    []string
+	unreadMessages int // Mensagens privadas não lidas, exibidas no cabeçalho
+
+	// Cliente do chat em tempo real; nil quando a sessão não tem acesso ao hub do servidor.
+	chat *chat.Client
+
+	// Registro de sessões do servidor e a sessão atual; nil fora do servidor SSH.
+	sessions *session.Registry
+	session  *session.Session
+
+	// Avisos da administração: sysop é nil fora do servidor SSH. O aviso e a contagem
+	// regressiva do desligamento aparecem abaixo do cabeçalho em todas as telas.
+	sysop       Sysop
+	banner      *BroadcastMsg
+	bannerSeq   int
+	shutdown    ShutdownMsg
+	shutdownSeq int
+	goodbye     string // Motivo da desconexão pelo servidor, exibido ao sair
+
+	// Configuração do cadastro de novas contas; define quem pode criar convites.
+	registration *Registration
+	// O papel do usuário exige a verificação em duas etapas.
+	twoFactorRequired bool
+	// Login de cadastro sugerido aos visitantes; vazio se o cadastro estiver fechado.
+	signupLogin string
+	// Prazo para os autores editarem os próprios posts; zero não limita.
+	editWindow time.Duration
+
+	// Aparência e dimensões do terminal da sessão
+	renderer *lipgloss.Renderer
+	styles   *styles
+	width    int
+	height   int
 }
 
-// InitialModel cria o nosso modelo inicial com o nome e o papel do usuário.
-func InitialModel(user, role string) *mainModel {
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+// Option configura o mainModel criado por InitialModel.
+type Option func(*mainModel)
 
-	choices := []string{"Ver Fóruns", "Configurações"}
-	if role == "admin" {
-		choices = append(choices, "Administração")
+// WithRenderer define o renderer usado pelos estilos da sessão. O servidor SSH cria um
+// renderer por conexão a partir do TERM do cliente; sem esta opção, usa-se o renderer padrão.
+func WithRenderer(r *lipgloss.Renderer) Option {
+	return func(m *mainModel) {
+		m.renderer = r
+	}
+}
+
+// WithChat habilita a tela de chat com o cliente da sessão no hub do servidor.
+func WithChat(client *chat.Client) Option {
+	return func(m *mainModel) {
+		m.chat = client
 	}
-	choices = append(choices, "Sair")
+}
+
+// WithSession registra a sessão no registro do servidor, habilitando a tela
+// "Quem está online" e o acompanhamento da atividade do usuário.
+func WithSession(reg *session.Registry, s *session.Session) Option {
+	return func(m *mainModel) {
+		m.sessions = reg
+		m.session = s
+	}
+}
+
+// WithSysop habilita os avisos da administração e a tela "Avisos do Sistema".
+func WithSysop(sysop Sysop) Option {
+	return func(m *mainModel) {
+		m.sysop = sysop
+	}
+}
+
+// WithRegistration informa a configuração do cadastro, habilitando a tela de convites
+// em Configurações para os papéis com cota na política RegistrationInvite.
+func WithRegistration(r *Registration) Option {
+	return func(m *mainModel) {
+		m.registration = r
+	}
+}
+
+// WithTwoFactorRequired indica que o papel do usuário exige a verificação em duas
+// etapas, que por isso não pode ser desativada em Configurações.
+func WithTwoFactorRequired() Option {
+	return func(m *mainModel) {
+		m.twoFactorRequired = true
+	}
+}
+
+// WithSignupLogin informa aos visitantes o login que abre o cadastro de novas contas.
+func WithSignupLogin(login string) Option {
+	return func(m *mainModel) {
+		m.signupLogin = login
+	}
+}
+
+// DefaultEditWindow é o prazo de edição dos posts pelos autores quando WithEditWindow
+// não é usada.
+const DefaultEditWindow = 30 * time.Minute
+
+// WithEditWindow define o prazo, contado da publicação, em que os autores podem editar
+// os próprios posts. Zero não limita o prazo.
+func WithEditWindow(d time.Duration) Option {
+	return func(m *mainModel) {
+		m.editWindow = d
+	}
+}
+
+// GuestRole é o papel dos visitantes, que entram sem conta: eles só leem os fóruns
+// abertos aos visitantes e não têm acesso a mensagens, chat, busca, quem está online nem
+// configurações.
+const GuestRole = database.GuestRole
+
+// readOnly informa se a sessão é de um visitante.
+func (m *mainModel) readOnly() bool {
+	return m.Role == GuestRole
+}
 
-	return &mainModel{
+// InitialModel cria o nosso modelo inicial com o Store da sessão e o nome e o papel do usuário.
+func InitialModel(store database.Store, user, role string, opts ...Option) *mainModel {
+	m := &mainModel{
+		store:       store,
 		User:        user,
 		Role:        role,
 		currentView: mainMenuView,
-		Choices:     choices,
-		spinner:     s,
 		isLoading:   false,
 		breadcrumbs: []string{"Home"},
+		renderer:    lipgloss.DefaultRenderer(),
+		editWindow:  DefaultEditWindow,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.readOnly() {
+		m.Choices = []string{"Ver Fóruns"}
+	} else {
+		m.Choices = []string{"Ver Fóruns", "Novidades", "Mensagens"}
+		if m.chat != nil {
+			m.Choices = append(m.Choices, "Chat")
+		}
+	}
+	if m.sessions != nil && !m.readOnly() {
+		m.Choices = append(m.Choices, "Quem está online")
+	}
+	if !m.readOnly() {
+		m.Choices = append(m.Choices, "Configurações")
+	}
+	if role == "admin" {
+		m.Choices = append(m.Choices, "Administração")
+	}
+	m.Choices = append(m.Choices, "Sair")
+	// Os visitantes não têm conta, então ficam com o ID 0 e sem estado de leitura.
+	if u, _, err := store.GetUserByUsername(user); err == nil && u != nil && !m.readOnly() {
+		m.userID = u.ID
+	}
+	m.styles = newStyles(m.renderer)
+
+	m.spinner = spinner.New()
+	m.spinner.Spinner = spinner.Dot
+	m.spinner.Style = m.styles.spinner
+
+	return m
 }
 
 // Init é a primeira função que é executada quando o programa inicia.
 func (m *mainModel) Init() tea.Cmd {
-	return m.spinner.Tick
+	cmds := []tea.Cmd{m.spinner.Tick}
+	if !m.readOnly() {
+		cmds = append(cmds, m.loadUnreadMessagesCmd)
+	}
+	// Quem entra durante a contagem regressiva também é avisado do desligamento.
+	if m.sysop != nil {
+		if at, reason, ok := m.sysop.PendingShutdown(); ok {
+			cmds = append(cmds, m.setShutdown(ShutdownMsg{At: at, Reason: reason}))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadUnreadMessagesCmd conta as mensagens privadas não lidas para o cabeçalho.
+func (m *mainModel) loadUnreadMessagesCmd() tea.Msg {
+	count, err := m.store.CountUnreadMessages(m.userID)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return unreadMessagesMsg{count: count}
+}
+
+// touchSession registra a atividade do usuário e a tela atual no registro de sessões.
+func (m *mainModel) touchSession() {
+	if m.session != nil {
+		m.session.Touch(strings.Join(m.breadcrumbs, " > "), m.sessionForumID())
+	}
+}
+
+// sessionForumID retorna o fórum da tela atual, ou 0 fora dos fóruns. Abaixo de "Fóruns",
+// o terceiro breadcrumb é o fórum aberto; abaixo da busca e das novidades, é o tópico.
+func (m *mainModel) sessionForumID() int64 {
+	if len(m.breadcrumbs) < 3 {
+		return 0
+	}
+	switch m.breadcrumbs[1] {
+	case "Fóruns":
+		if m.topicsModel != nil {
+			return m.topicsModel.forum.ID
+		}
+	case "Busca", "Novidades":
+		if m.postsModel != nil {
+			return int64(m.postsModel.topic.ForumID)
+		}
+	}
+	return 0
 }
 
 // Update lida com as entradas do usuário e atualiza o estado.
 func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if _, ok := msg.(tea.KeyMsg); ok && m.session != nil {
+		// A tela é registrada depois da tecla, já refletindo a navegação que ela causou.
+		defer m.touchSession()
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.adminModel != nil {
+			m.adminModel.setSize(msg.Width, msg.Height)
+		}
+		if m.postsModel != nil {
+			m.postsModel.setSize(msg.Width, msg.Height)
+		}
+		if m.messagesModel != nil {
+			m.messagesModel.setSize(msg.Width, msg.Height)
+		}
+		if m.chatModel != nil {
+			m.chatModel.setSize(msg.Width, msg.Height)
+		}
+		if m.revisionsModel != nil {
+			m.revisionsModel.setSize(msg.Width, msg.Height)
+		}
+		return m, nil
 	case tea.KeyMsg:
 		// Comandos globais, independentemente da view
 
 	case statusMessageTimeoutMsg:
 		m.statusMessage = ""
 		return m, nil
+	case chat.Event:
+		// Os eventos do chat chegam mesmo fora da tela de chat; são guardados para quando ela for aberta.
+		if m.chatModel != nil {
+			m.chatModel.receive(msg)
+		}
+		return m, nil
+	case events.Event:
+		// Só a tela aberta é atualizada; as demais são recarregadas ao voltar para elas.
+		switch m.currentView {
+		case forumsView:
+			cmd = m.forumsModel.handleEvent(msg)
+		case topicsView:
+			cmd = m.topicsModel.handleEvent(msg)
+		case postsView:
+			cmd = m.postsModel.handleEvent(msg)
+		case revisionsView:
+			cmd = m.revisionsModel.handleEvent(msg)
+		case trashView:
+			cmd = m.trashModel.handleEvent(msg)
+		}
+		return m, cmd
+	case unreadMessagesMsg:
+		m.unreadMessages = msg.count
+		return m, nil
+	case BroadcastMsg:
+		return m, m.showBanner(msg)
+	case bannerTimeoutMsg:
+		if msg.seq == m.bannerSeq {
+			m.banner = nil
+		}
+		return m, nil
+	case ShutdownMsg:
+		return m, m.setShutdown(msg)
+	case shutdownTickMsg:
+		// A contagem continua até o servidor desconectar a sessão ou cancelar o desligamento.
+		if msg.seq != m.shutdownSeq || m.shutdown.At.IsZero() {
+			return m, nil
+		}
+		return m, m.shutdownTick()
+	case DisconnectMsg:
+		m.goodbye = msg.Reason
+		return m, tea.Quit
+	case sysopActionMsg:
+		m.statusMessage = msg.status
+		// As ações enviadas por formulários voltam para a tela de avisos.
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+			m.currentView = sysopView
+		}
+		m.sysopModel.refresh()
+		return m, tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+	case forumAccessActionMsg:
+		m.statusMessage = msg.status
+		// Os formulários voltam para a tela de permissões ou de moderadores do fórum.
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+			m.currentView = forumManagementView
+		}
+		timeout := tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		return m, tea.Batch(timeout, m.forumManagementModel.refreshCmd())
+	case trashActionMsg:
+		m.statusMessage = msg.status
+		// Os formulários de motivo voltam para a tela em que o item foi apagado.
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+			m.currentView = msg.back
+		}
+		timeout := tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		switch m.currentView {
+		case postsView:
+			cmd = m.postsModel.reload()
+		case topicsView:
+			cmd = m.topicsModel.reload()
+		case forumManagementView:
+			cmd = m.forumManagementModel.Init()
+		case trashView:
+			cmd = m.trashModel.Init()
+		}
+		return m, tea.Batch(timeout, cmd)
+	case messageActionMsg:
+		m.statusMessage = msg.status
+		// Ações enviadas por formulários voltam para a caixa de mensagens.
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+			m.currentView = messagesView
+		}
+		timeout := tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		return m, tea.Batch(timeout, m.messagesModel.Init(), m.loadUnreadMessagesCmd)
 	case errorMsg:
 		m.isLoading = false
 		m.statusMessage = "Erro: " + msg.err.Error()
@@ -127,7 +478,13 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return statusMessageTimeoutMsg{}
 		})
 	case forumsLoadedMsg:
+		// A lista de fóruns também é carregada pelo gerenciamento de fóruns.
 		var newModel tea.Model
+		if m.currentView == forumManagementView {
+			newModel, cmd = m.forumManagementModel.Update(msg)
+			m.forumManagementModel = newModel.(*forumManagementModel)
+			return m, cmd
+		}
 		newModel, cmd = m.forumsModel.Update(msg)
 		m.forumsModel = newModel.(*forumsModel)
 		return m, cmd
@@ -142,16 +499,30 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Tick(time.Second*3, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
 	case topicCreatedMsg:
 		m.statusMessage = "Tópico criado com sucesso!"
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+		}
 		m.currentView = topicsView
 		tm := NewTopicsModel(m, msg.forum)
 		m.topicsModel = tm
 		return m, tm.Init()
 	case postCreatedMsg:
 		m.statusMessage = "Post criado com sucesso!"
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+		}
 		m.currentView = postsView
 		pm := NewPostsModel(m, msg.topic)
 		m.postsModel = pm
 		return m, pm.Init()
+	case postEditedMsg:
+		m.statusMessage = "Post editado com sucesso!"
+		if m.currentView == formView {
+			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
+			m.currentView = postsView
+		}
+		timeout := tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		return m, tea.Batch(timeout, m.postsModel.reload())
 	case statusMessage:
 		if msg.success {
 			m.statusMessage = msg.message
@@ -159,12 +530,23 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.statusMessage = "Erro: " + msg.message
 		}
 		m.currentView = settingsView // Volta para a tela de configurações após a ação
-		return m, tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		timeout := tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+		if m.settingsModel != nil && m.settingsModel.managingKeys {
+			return m, tea.Batch(timeout, m.settingsModel.loadKeysCmd)
+		}
+		if m.settingsModel != nil && m.settingsModel.managingInvites {
+			return m, tea.Batch(timeout, m.settingsModel.loadInvitesCmd)
+		}
+		if m.settingsModel != nil && m.settingsModel.managingTwoFactor {
+			return m, tea.Batch(timeout, m.settingsModel.loadTwoFactorCmd)
+		}
+		return m, timeout
 	case navigateBackMsg:
 		if len(m.breadcrumbs) > 1 {
 			m.breadcrumbs = m.breadcrumbs[:len(m.breadcrumbs)-1]
 			newView := m.breadcrumbs[len(m.breadcrumbs)-1]
 
+			// As listas são recarregadas ao voltar, para atualizar as contagens de não lidos.
 			switch newView {
 			case "Home":
 				m.currentView = mainMenuView
@@ -172,13 +554,43 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.currentView = adminView
 			case "Fóruns":
 				m.currentView = forumsView
+				cmd = m.forumsModel.Init()
 			case "Tópicos":
 				m.currentView = topicsView
 			case "Configurações":
 				m.currentView = settingsView
+			case "Busca":
+				m.currentView = searchView
+			case "Novidades":
+				m.currentView = unreadView
+				cmd = m.unreadModel.Init()
+			case "Mensagens":
+				m.currentView = messagesView
+				cmd = m.messagesModel.Init()
+			case "Quem está online":
+				m.currentView = whoView
+				cmd = m.whoModel.Init()
+			case "Avisos do Sistema":
+				m.currentView = sysopView
+				cmd = m.sysopModel.Init()
+			case "Gerenciamento de Fóruns":
+				m.currentView = forumManagementView
+				cmd = m.forumManagementModel.Init()
+			case "Lixeira":
+				m.currentView = trashView
+				cmd = m.trashModel.Init()
+			default:
+				// O breadcrumb da lista de tópicos é o nome do fórum, e o da leitura de
+				// posts, o título do tópico.
+				if m.topicsModel != nil && newView == m.topicsModel.forum.Name {
+					m.currentView = topicsView
+					cmd = m.topicsModel.Init()
+				} else if m.postsModel != nil && newView == m.postsModel.topic.Title {
+					m.currentView = postsView
+				}
 			}
 		}
-		return m, nil
+		return m, tea.Batch(cmd, m.loadUnreadMessagesCmd)
 	}
 
 	var newModel tea.Model
@@ -212,7 +624,36 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.forumManagementModel = newModel.(*forumManagementModel)
 	case settingsView:
 		newModel, cmd = m.settingsModel.Update(msg)
-		m.settingsModel = newModel.(*settingsModel)
+		if settings, ok := newModel.(*settingsModel); ok {
+			m.settingsModel = settings
+		} else {
+			// As opções que abrem outra tela retornam o mainModel, já com a nova view.
+			return newModel, cmd
+		}
+	case searchView:
+		newModel, cmd = m.searchModel.Update(msg)
+		m.searchModel = newModel.(*searchModel)
+	case unreadView:
+		newModel, cmd = m.unreadModel.Update(msg)
+		m.unreadModel = newModel.(*unreadModel)
+	case messagesView:
+		newModel, cmd = m.messagesModel.Update(msg)
+		m.messagesModel = newModel.(*messagesModel)
+	case chatView:
+		newModel, cmd = m.chatModel.Update(msg)
+		m.chatModel = newModel.(*chatModel)
+	case whoView:
+		newModel, cmd = m.whoModel.Update(msg)
+		m.whoModel = newModel.(*whoModel)
+	case sysopView:
+		newModel, cmd = m.sysopModel.Update(msg)
+		m.sysopModel = newModel.(*sysopModel)
+	case revisionsView:
+		newModel, cmd = m.revisionsModel.Update(msg)
+		m.revisionsModel = newModel.(*revisionsModel)
+	case trashView:
+		newModel, cmd = m.trashModel.Update(msg)
+		m.trashModel = newModel.(*trashModel)
 	default: // mainMenuView
 		return m.updateMainMenu(msg)
 	}
@@ -235,13 +676,41 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		cmd = m.forumManagementModel.Init()
 		m.adminModel.navigateToForumManagement = false
+	} else if m.adminModel != nil && m.adminModel.navigateToSysop {
+		m.currentView = sysopView
+		m.breadcrumbs = append(m.breadcrumbs, "Avisos do Sistema")
+		if m.sysopModel == nil {
+			m.sysopModel = NewSysopModel(m)
+		}
+		cmd = m.sysopModel.Init()
+		m.adminModel.navigateToSysop = false
+	} else if m.adminModel != nil && m.adminModel.navigateToTrash {
+		m.currentView = trashView
+		m.breadcrumbs = append(m.breadcrumbs, "Lixeira")
+		if m.trashModel == nil {
+			m.trashModel = NewTrashModel(m)
+		}
+		cmd = m.trashModel.Init()
+		m.adminModel.navigateToTrash = false
+	} else if m.sysopModel != nil && m.sysopModel.navigateToBroadcast {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Enviar Aviso")
+		m.formModel = NewBroadcastFormModel(m)
+		cmd = m.formModel.Init()
+		m.sysopModel.navigateToBroadcast = false
+	} else if m.sysopModel != nil && m.sysopModel.navigateToShutdown {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Programar Desligamento")
+		m.formModel = NewShutdownFormModel(m)
+		cmd = m.formModel.Init()
+		m.sysopModel.navigateToShutdown = false
 	} else if m.forumManagementModel != nil && m.forumManagementModel.navigateToForm {
 		m.currentView = formView
 		m.breadcrumbs = append(m.breadcrumbs, "Novo Fórum")
 
 		callback := func(values map[string]string) tea.Cmd {
 			return func() tea.Msg {
-				_, err := database.CreateForum(values["Nome"], values["Descrição"])
+				_, err := m.store.CreateForum(values["Nome"], values["Descrição"])
 				if err != nil {
 					return statusMessage{success: false, message: "Erro ao criar fórum: " + err.Error()}
 				}
@@ -258,6 +727,27 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.formModel = NewEditForumFormModel(m, m.forumManagementModel.selectedForum)
 		cmd = m.formModel.Init()
 		m.forumManagementModel.navigateToEditForm = false
+	} else if m.forumManagementModel != nil && m.forumManagementModel.navigateToGrantForm {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Conceder Permissão")
+		m.formModel = NewForumGrantFormModel(m, m.forumManagementModel.selectedForum, usernameSuggestions(m.store, m.User))
+		cmd = m.formModel.Init()
+		m.forumManagementModel.navigateToGrantForm = false
+	} else if m.forumManagementModel != nil && m.forumManagementModel.navigateToModeratorForm {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Designar Moderador")
+		m.formModel = NewForumModeratorFormModel(m, m.forumManagementModel.selectedForum, usernameSuggestions(m.store, ""))
+		cmd = m.formModel.Init()
+		m.forumManagementModel.navigateToModeratorForm = false
+	} else if m.forumManagementModel != nil && m.forumManagementModel.navigateToTrashForm {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Mover para a Lixeira")
+		forum := m.forumManagementModel.selectedForum
+		m.formModel = NewTrashFormModel(m, fmt.Sprintf("Mover o fórum '%s' para a Lixeira", forum.Name), "Fórum movido para a lixeira.", forumManagementView, func(reason string) error {
+			return m.store.DeleteForum(forum.ID, m.userID, reason)
+		})
+		cmd = m.formModel.Init()
+		m.forumManagementModel.navigateToTrashForm = false
 	} else if m.forumsModel != nil && m.forumsModel.navToTopics != nil {
 		m.currentView = topicsView
 		m.breadcrumbs = append(m.breadcrumbs, m.forumsModel.navToTopics.Name)
@@ -270,18 +760,88 @@ func (m *mainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.postsModel = NewPostsModel(m, m.topicsModel.navToPosts)
 		cmd = m.postsModel.Init()
 		m.topicsModel.navToPosts = nil
+	} else if m.searchModel != nil && m.searchModel.navToPosts != nil {
+		m.currentView = postsView
+		m.breadcrumbs = append(m.breadcrumbs, m.searchModel.navToPosts.Title)
+		m.postsModel = NewPostsModel(m, m.searchModel.navToPosts)
+		m.postsModel.focusPostID = m.searchModel.focusPostID
+		cmd = m.postsModel.Init()
+		m.searchModel.navToPosts = nil
+	} else if m.unreadModel != nil && m.unreadModel.navToPosts != nil {
+		m.currentView = postsView
+		m.breadcrumbs = append(m.breadcrumbs, m.unreadModel.navToPosts.Title)
+		m.postsModel = NewPostsModel(m, m.unreadModel.navToPosts)
+		m.postsModel.seekUnread = true
+		cmd = m.postsModel.Init()
+		m.unreadModel.navToPosts = nil
+	} else if m.messagesModel != nil && m.messagesModel.composing {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Nova Mensagem")
+		m.formModel = NewMessageFormModel(m, usernameSuggestions(m.store, m.User))
+		cmd = m.formModel.Init()
+		m.messagesModel.composing = false
+	} else if m.messagesModel != nil && m.messagesModel.replying {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Responder")
+		m.formModel = NewReplyFormModel(m, m.messagesModel.conversation)
+		cmd = m.formModel.Init()
+		m.messagesModel.replying = false
+	} else if m.messagesModel != nil && m.messagesModel.blockingUser {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Bloquear Remetente")
+		m.formModel = NewBlockUserFormModel(m, usernameSuggestions(m.store, m.User))
+		cmd = m.formModel.Init()
+		m.messagesModel.blockingUser = false
 	} else if m.topicsModel != nil && m.topicsModel.creatingTopic {
 		m.currentView = formView
 		m.breadcrumbs = append(m.breadcrumbs, "Novo Tópico")
 		m.formModel = NewTopicFormModel(m, m.topicsModel.forum)
 		cmd = m.formModel.Init()
 		m.topicsModel.creatingTopic = false
+	} else if m.topicsModel != nil && m.topicsModel.trashingTopic != nil {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Mover para a Lixeira")
+		topic, forumID := m.topicsModel.trashingTopic, m.topicsModel.forum.ID
+		m.formModel = NewTrashFormModel(m, fmt.Sprintf("Mover o tópico '%s' para a Lixeira", topic.Title), "Tópico movido para a lixeira.", topicsView, func(reason string) error {
+			// A moderação pode ter sido retirada depois que a lista foi carregada.
+			if err := m.checkModerate(forumID); err != nil {
+				return err
+			}
+			return m.store.DeleteTopic(topic.ID, m.userID, reason)
+		})
+		cmd = m.formModel.Init()
+		m.topicsModel.trashingTopic = nil
 	} else if m.postsModel != nil && m.postsModel.creatingPost {
 		m.currentView = formView
 		m.breadcrumbs = append(m.breadcrumbs, "Novo Post")
 		m.formModel = NewPostFormModel(m, m.postsModel.topic)
 		cmd = m.formModel.Init()
 		m.postsModel.creatingPost = false
+	} else if m.postsModel != nil && m.postsModel.editingPost != nil {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Editar Post")
+		m.formModel = NewEditPostFormModel(m, m.postsModel.topic, m.postsModel.editingPost)
+		cmd = m.formModel.Init()
+		m.postsModel.editingPost = nil
+	} else if m.postsModel != nil && m.postsModel.trashingPost != nil {
+		m.currentView = formView
+		m.breadcrumbs = append(m.breadcrumbs, "Mover para a Lixeira")
+		post, forumID := m.postsModel.trashingPost, int64(m.postsModel.topic.ForumID)
+		m.formModel = NewTrashFormModel(m, fmt.Sprintf("Mover o post de %s para a Lixeira", post.Username), "Post movido para a lixeira.", postsView, func(reason string) error {
+			// A moderação pode ter sido retirada depois que o tópico foi aberto.
+			if err := m.checkModerate(forumID); err != nil {
+				return err
+			}
+			return m.store.DeletePost(post.ID, m.userID, reason)
+		})
+		cmd = m.formModel.Init()
+		m.postsModel.trashingPost = nil
+	} else if m.postsModel != nil && m.postsModel.navToHistory != nil {
+		m.currentView = revisionsView
+		m.breadcrumbs = append(m.breadcrumbs, "Histórico")
+		m.revisionsModel = NewRevisionsModel(m, m.postsModel.navToHistory)
+		cmd = m.revisionsModel.Init()
+		m.postsModel.navToHistory = nil
 	}
 
 	return m, cmd
@@ -294,6 +854,17 @@ func (m *mainModel) updateMainMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
+		case "/":
+			// A busca alcança todos os fóruns, inclusive os fechados aos visitantes.
+			if m.readOnly() {
+				return m, nil
+			}
+			m.currentView = searchView
+			m.breadcrumbs = []string{"Home", "Busca"}
+			if m.searchModel == nil {
+				m.searchModel = NewSearchModel(m)
+			}
+			return m, m.searchModel.input.Focus()
 		case "up", "k":
 			if m.Cursor > 0 {
 				m.Cursor--
@@ -311,6 +882,34 @@ func (m *mainModel) updateMainMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.forumsModel = NewForumsModel(m)
 				}
 				return m, m.forumsModel.Init()
+			case "Novidades":
+				m.currentView = unreadView
+				m.breadcrumbs = []string{"Home", "Novidades"}
+				if m.unreadModel == nil {
+					m.unreadModel = NewUnreadModel(m)
+				}
+				return m, m.unreadModel.Init()
+			case "Mensagens":
+				m.currentView = messagesView
+				m.breadcrumbs = []string{"Home", "Mensagens"}
+				if m.messagesModel == nil {
+					m.messagesModel = NewMessagesModel(m)
+				}
+				return m, m.messagesModel.Init()
+			case "Chat":
+				m.currentView = chatView
+				m.breadcrumbs = []string{"Home", "Chat"}
+				if m.chatModel == nil {
+					m.chatModel = NewChatModel(m, m.chat)
+				}
+				return m, m.chatModel.Init()
+			case "Quem está online":
+				m.currentView = whoView
+				m.breadcrumbs = []string{"Home", "Quem está online"}
+				if m.whoModel == nil {
+					m.whoModel = NewWhoModel(m)
+				}
+				return m, m.whoModel.Init()
 			case "Configurações":
 				m.currentView = settingsView
 				m.breadcrumbs = []string{"Home", "Configurações"}
@@ -335,8 +934,16 @@ func (m *mainModel) updateMainMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renderiza a UI.
 func (m *mainModel) View() string {
-	// Renderiza o cabeçalho com breadcrumbs
+	// Sessão encerrada pelo servidor: a última tela exibida é a despedida.
+	if m.goodbye != "" {
+		return m.styles.errorStatusMessage.Render(m.goodbye) + "\n"
+	}
+
+	// Renderiza o cabeçalho com breadcrumbs e os avisos da administração
 	header := m.renderHeader()
+	if notices := m.renderNotices(); notices != "" {
+		header += "\n" + notices
+	}
 
 	// Renderiza a view atual
 	var currentViewContent string
@@ -359,6 +966,22 @@ func (m *mainModel) View() string {
 		currentViewContent = m.forumManagementModel.View()
 	case adminView:
 		currentViewContent = m.adminModel.View()
+	case searchView:
+		currentViewContent = m.searchModel.View()
+	case unreadView:
+		currentViewContent = m.unreadModel.View()
+	case messagesView:
+		currentViewContent = m.messagesModel.View()
+	case chatView:
+		currentViewContent = m.chatModel.View()
+	case whoView:
+		currentViewContent = m.whoModel.View()
+	case sysopView:
+		currentViewContent = m.sysopModel.View()
+	case revisionsView:
+		currentViewContent = m.revisionsModel.View()
+	case trashView:
+		currentViewContent = m.trashModel.View()
 	}
 
 	// Renderiza o rodapé
@@ -367,9 +990,9 @@ func (m *mainModel) View() string {
 	// Adiciona a mensagem de status se houver uma
 	statusMsg := ""
 	if m.statusMessage != "" {
-		style := statusMessageStyle
+		style := m.styles.statusMessage
 		if strings.HasPrefix(m.statusMessage, "Erro") {
-			style = errorStatusMessageStyle
+			style = m.styles.errorStatusMessage
 		}
 		statusMsg = style.Render(m.statusMessage)
 	}
@@ -379,8 +1002,13 @@ func (m *mainModel) View() string {
 }
 
 // renderHeader renderiza o cabeçalho da UI.
+// Ao lado dos breadcrumbs, indica as mensagens privadas não lidas.
 func (m *mainModel) renderHeader() string {
-	return headerStyle.Render(strings.Join(m.breadcrumbs, " > "))
+	header := m.styles.header.Render(strings.Join(m.breadcrumbs, " > "))
+	if m.unreadMessages > 0 {
+		header += "  " + m.styles.highlight.Render(fmt.Sprintf("✉ %d", m.unreadMessages))
+	}
+	return header
 }
 
 // renderFooter renderiza o rodapé da UI.
@@ -400,23 +1028,50 @@ func (m *mainModel) renderFooter() string {
 	case userManagementView:
 		help = m.userManagementModel.helpView()
 	case forumManagementView:
-		// help = m.forumManagementModel.helpView()
+		help = m.forumManagementModel.helpView()
+	case searchView:
+		help = m.searchModel.helpView()
+	case unreadView:
+		help = m.unreadModel.helpView()
+	case messagesView:
+		help = m.messagesModel.helpView()
+	case chatView:
+		help = m.chatModel.helpView()
+	case whoView:
+		help = m.whoModel.helpView()
+	case sysopView:
+		help = m.sysopModel.helpView()
+	case revisionsView:
+		help = m.revisionsModel.helpView()
+	case trashView:
+		help = m.trashModel.helpView()
 	default:
-		help = "Use as setas para navegar e 'enter' para selecionar. Pressione 'q' para sair."
+		if m.readOnly() {
+			help = "Use as setas para navegar e 'enter' para selecionar. Pressione 'q' para sair."
+		} else {
+			help = "Use as setas para navegar e 'enter' para selecionar. Pressione '/' para buscar e 'q' para sair."
+		}
 	}
 
-	return footerStyle.Render(help)
+	return m.styles.footer.Render(help)
 }
 
 // viewMainMenu renderiza a UI do menu principal.
 func (m *mainModel) viewMainMenu() string {
 	s := fmt.Sprintf("Bem-vindo ao Modern BBS, %s!\n\n", m.User)
+	if m.readOnly() {
+		s = "Bem-vindo ao Modern BBS! Você está navegando como visitante e pode ler os fóruns abertos.\n"
+		if m.signupLogin != "" {
+			s += fmt.Sprintf("Para participar, crie uma conta entrando com o login '%s'.\n", m.signupLogin)
+		}
+		s += "\n"
+	}
 
 	for i, choice := range m.Choices {
 		if m.Cursor == i {
-			s += selectedItemStyle.Render(fmt.Sprintf("> %s", choice))
+			s += m.styles.selectedItem.Render(fmt.Sprintf("> %s", choice))
 		} else {
-			s += itemStyle.Render(fmt.Sprintf("  %s", choice))
+			s += m.styles.item.Render(fmt.Sprintf("  %s", choice))
 		}
 		s += "\n"
 	}
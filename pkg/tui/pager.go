@@ -0,0 +1,59 @@
+package tui
+
+import "fmt"
+
+// pager guarda o estado da paginação de uma lista carregada sob demanda: os itens
+// chegam do banco em páginas de tamanho fixo, e apenas a página do cursor é exibida.
+type pager struct {
+	size    int  // Itens por página
+	total   int  // Total de itens no banco
+	loading bool // Há uma página sendo carregada
+	target  int  // Posição pedida pelo PgDn que ainda não foi carregada (-1 se nenhuma)
+	stale   bool // A lista mudou no banco durante um carregamento e deve ser recarregada
+}
+
+func newPager(size int) pager {
+	return pager{size: size, target: -1}
+}
+
+// page retorna a página (a partir de 1) em que está o cursor.
+func (p *pager) page(cursor int) int {
+	return cursor/p.size + 1
+}
+
+// pages retorna o número total de páginas.
+func (p *pager) pages() int {
+	return max((p.total+p.size-1)/p.size, 1)
+}
+
+// hasMore informa se ainda há itens no banco além dos já carregados.
+func (p *pager) hasMore(loaded int) bool {
+	return loaded < p.total
+}
+
+// bounds retorna o intervalo [start, end) dos itens carregados na página do cursor.
+func (p *pager) bounds(cursor, loaded int) (int, int) {
+	start := (p.page(cursor) - 1) * p.size
+	return min(start, loaded), min(start+p.size, loaded)
+}
+
+// deferReload informa se uma recarga pedida agora deve esperar o carregamento em
+// andamento; nesse caso, ela fica pendente até que takeStale seja chamada.
+func (p *pager) deferReload() bool {
+	if p.loading {
+		p.stale = true
+	}
+	return p.loading
+}
+
+// takeStale retorna e limpa a recarga pendente.
+func (p *pager) takeStale() bool {
+	stale := p.stale
+	p.stale = false
+	return stale
+}
+
+// status retorna o indicador "Página X de Y".
+func (p *pager) status(cursor int) string {
+	return fmt.Sprintf("Página %d de %d", p.page(cursor), p.pages())
+}
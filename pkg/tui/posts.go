@@ -1,47 +1,171 @@
 package tui
 
 import (
+	"cmp"
 	"fmt"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
 )
 
 // postsModel representa a visão dos posts de um tópico.
 type postsModel struct {
-	keys             *KeyMap
-	parent           *mainModel
-	topic            *database.Topic
-	posts            []*database.Post
-	cursor           int
-	quitting         bool
-	creatingPost     bool // Sinaliza se estamos criando um novo post (resposta)
-	confirmingDelete bool
+	keys         *KeyMap
+	parent       *mainModel
+	topic        *database.Topic
+	posts        []*database.Post
+	cursor       int
+	quitting     bool
+	creatingPost bool           // Sinaliza se estamos criando um novo post (resposta)
+	trashingPost *database.Post // Post a mover para a lixeira; abre o formulário do motivo
+	editingPost  *database.Post // Post a editar; abre o formulário de edição
+	navToHistory *database.Post // Post cujo histórico de edições será aberto
+	focusPostID  int            // Post a selecionar quando a lista for carregada (ex.: vindo da busca)
+	pager        pager
+	canReply     bool // Permissões do usuário no fórum, carregadas com os posts
+	canModerate  bool
+
+	// O leitor exibe a página atual em um viewport, com quebra de linha pela largura
+	// do terminal. postLines guarda a linha inicial e final de cada post da página.
+	viewport    viewport.Model
+	postLines   [][2]int
+	followPosts bool // Ajusta a rolagem para mostrar o post selecionado
+
+	markdown *markdownRenderer
+	rawPosts map[int]bool // Posts exibidos como texto-fonte, sem renderizar o Markdown
+
+	// Estado de leitura: unreadFrom é o último post lido quando o tópico foi aberto,
+	// e lastRead o último post já registrado como lido nesta visita.
+	readLoaded bool
+	unreadFrom int
+	lastRead   int
+	seekUnread bool // Selecionar o primeiro post não lido quando a lista for carregada
+
+	// Respostas de outros usuários publicadas com o tópico aberto e ainda não vistas.
+	newReplies int
 }
 
+// postsPageSize é o número de posts buscados e exibidos por página.
+const postsPageSize = 10
+
 type postsLoadedMsg struct {
-	posts []*database.Post
-	err   error
+	posts       []*database.Post
+	total       int
+	more        bool // Página seguinte, a ser anexada às já carregadas
+	lastRead    int  // Último post lido pelo usuário; -1 se não foi consultado
+	canReply    bool
+	canModerate bool
+	err         error
 }
 
 type reloadPostsMsg struct{}
 
+// checkModerate retorna ErrForumPermission se o usuário não puder moderar o fórum.
+func (m *mainModel) checkModerate(forumID int64) error {
+	ok, err := m.store.HasForumPermission(m.userID, forumID, database.PermissionModerate)
+	if err != nil || !ok {
+		return cmp.Or(err, database.ErrForumPermission)
+	}
+	return nil
+}
+
+// editError informa por que o usuário não pode editar o post, ou nil se ele puder, com as
+// mesmas regras de Store.UpdatePost: quem modera o fórum edita qualquer post a qualquer
+// momento; os autores que podem responder editam os próprios posts dentro do prazo.
+func (m *mainModel) editError(post *database.Post, canReply, canModerate bool) error {
+	switch {
+	case m.readOnly():
+		return database.ErrForumPermission
+	case canModerate:
+		return nil
+	case int64(post.UserID) != m.userID || !canReply:
+		return database.ErrForumPermission
+	case m.editWindow > 0 && time.Since(post.CreatedAt) > m.editWindow:
+		return database.ErrEditWindow
+	}
+	return nil
+}
+
 // NewPostsModel cria um novo modelo para a visão de posts.
 func NewPostsModel(parent *mainModel, topic *database.Topic) *postsModel {
-	return &postsModel{
-		keys:   DefaultKeyMap,
-		parent: parent,
-		topic:  topic,
+	m := &postsModel{
+		keys:     DefaultKeyMap,
+		parent:   parent,
+		topic:    topic,
+		pager:    newPager(postsPageSize),
+		viewport: viewport.New(0, 0),
+		markdown: &markdownRenderer{
+			styles: parent.styles,
+			// Terminais sem suporte a cores (ex.: TERM=dumb) recebem texto puro.
+			plain: parent.renderer.ColorProfile() == termenv.Ascii,
+		},
+		rawPosts: make(map[int]bool),
+	}
+	m.setSize(parent.width, parent.height)
+	return m
+}
+
+// setSize ajusta o viewport ao terminal, descontando o cabeçalho e o rodapé da
+// aplicação, o título do tópico, o indicador de página e a ajuda.
+func (m *postsModel) setSize(width, height int) {
+	if width == 0 || height == 0 {
+		width, height = 80, 24
 	}
+	helpHeight := strings.Count(m.renderHelp(width), "\n") + 1
+	m.viewport.Width = width
+	m.viewport.Height = max(height-9-helpHeight, 3)
+	m.followPosts = true
+	m.render()
 }
 
+// Init carrega a primeira página. Ao recarregar, busca de novo tantos posts quantos
+// já estavam carregados, para que o cursor continue na mesma página.
 func (m *postsModel) Init() tea.Cmd {
+	return m.loadPostsCmd(nil, max(len(m.posts), postsPageSize), false)
+}
+
+// loadMore busca a página seguinte aos posts já carregados.
+func (m *postsModel) loadMore() tea.Cmd {
+	if m.pager.loading || !m.pager.hasMore(len(m.posts)) || len(m.posts) == 0 {
+		return nil
+	}
+	return m.loadPostsCmd(m.posts[len(m.posts)-1].Cursor(), postsPageSize, true)
+}
+
+func (m *postsModel) loadPostsCmd(after *database.PageCursor, limit int, more bool) tea.Cmd {
+	m.pager.loading = true
+	topicID := m.topic.ID
+	withRead := !m.readLoaded
 	return func() tea.Msg {
-		posts, err := database.GetPostsByTopicID(m.topic.ID)
-		return postsLoadedMsg{posts: posts, err: err}
+		lastRead := -1
+		if withRead {
+			var err error
+			if lastRead, err = m.parent.store.GetLastReadPostID(m.parent.userID, topicID); err != nil {
+				return postsLoadedMsg{err: err}
+			}
+		}
+		total, err := m.parent.store.CountPostsByTopicID(m.parent.userID, topicID)
+		if err != nil {
+			return postsLoadedMsg{err: err}
+		}
+		posts, err := m.parent.store.GetPostsPageByTopicID(m.parent.userID, topicID, after, limit)
+		msg := postsLoadedMsg{posts: posts, total: total, more: more, lastRead: lastRead, err: err}
+		// Os visitantes só podem ler.
+		if err != nil || m.parent.readOnly() {
+			return msg
+		}
+		forumID := int64(m.topic.ForumID)
+		if msg.canReply, msg.err = m.parent.store.HasForumPermission(m.parent.userID, forumID, database.PermissionReply); msg.err != nil {
+			return msg
+		}
+		msg.canModerate, msg.err = m.parent.store.HasForumPermission(m.parent.userID, forumID, database.PermissionModerate)
+		return msg
 	}
 }
 
@@ -51,50 +175,89 @@ func (m *postsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			return m, tea.Quit // Tratar erro
 		}
-		m.posts = msg.posts
-		return m, nil
-	case reloadPostsMsg:
-		return m, m.Init()
-	case tea.KeyMsg:
-		if m.confirmingDelete {
-			switch msg.String() {
-			case "s", "S":
-				if len(m.posts) > 0 {
-					selectedPostID := m.posts[m.cursor].ID
-					cmd := func() tea.Msg {
-						err := database.DeletePost(selectedPostID)
-						if err != nil {
-							return statusMessage{success: false, message: "Erro ao deletar post: " + err.Error()}
-						}
-						return reloadPostsMsg{}
-					}
-					m.confirmingDelete = false
+		m.pager.loading = false
+		m.pager.total = msg.total
+		if msg.canReply != m.canReply || msg.canModerate != m.canModerate {
+			// A ajuda muda de tamanho, e com ela a altura do leitor.
+			m.canReply, m.canModerate = msg.canReply, msg.canModerate
+			m.setSize(m.parent.width, m.parent.height)
+		}
+		if msg.more {
+			m.posts = append(m.posts, msg.posts...)
+		} else {
+			m.posts = msg.posts
+		}
+		if msg.lastRead >= 0 && !m.readLoaded {
+			m.readLoaded = true
+			m.unreadFrom, m.lastRead = msg.lastRead, msg.lastRead
+		}
+		m.followPosts = true
+		if m.pager.target >= 0 {
+			if m.pager.target >= len(m.posts) {
+				if cmd := m.loadMore(); cmd != nil {
 					return m, cmd
 				}
-			case "n", "N":
-				m.confirmingDelete = false
-				return m, nil
 			}
-			return m, nil
+			m.cursor = m.pager.target
+			m.pager.target = -1
 		}
-
-		switch {
-		case key.Matches(msg, m.keys.Up):
-			if m.cursor > 0 {
-				m.cursor--
+		if m.focusPostID != 0 {
+			found := false
+			for i, post := range m.posts {
+				if post.ID == m.focusPostID {
+					m.cursor = i
+					found = true
+				}
+			}
+			// O post procurado pode estar em uma página ainda não carregada.
+			if !found {
+				if cmd := m.loadMore(); cmd != nil {
+					return m, cmd
+				}
 			}
-		case key.Matches(msg, m.keys.Down):
-			if m.cursor < len(m.posts)-1 {
-				m.cursor++
+			m.focusPostID = 0
+		}
+		if m.seekUnread {
+			m.seekUnread = false
+			if cmd := m.seekFirstUnread(); cmd != nil {
+				return m, cmd
 			}
+		}
+		m.cursor = max(min(m.cursor, len(m.posts)-1), 0)
+		m.render()
+		if m.pager.takeStale() {
+			return m, tea.Batch(m.markReadCmd(), m.reload())
+		}
+		return m, m.markReadCmd()
+	case reloadPostsMsg:
+		return m, m.Init()
+	case tea.KeyMsg:
+		if cmd, handled := m.updateReader(msg); handled {
+			m.render()
+			return m, tea.Batch(cmd, m.markReadCmd())
+		}
+
+		switch {
 		case key.Matches(msg, m.keys.New):
-			if m.parent.Role != "" {
+			if m.canReply {
 				m.creatingPost = true
 				return m, nil
 			}
 		case key.Matches(msg, m.keys.Delete):
-			if (m.parent.Role == "admin" || m.parent.Role == "moderator") && len(m.posts) > 0 {
-				m.confirmingDelete = true
+			if m.canModerate && len(m.posts) > 0 {
+				m.trashingPost = m.posts[m.cursor]
+			}
+		case key.Matches(msg, m.keys.Edit):
+			if !m.parent.readOnly() && len(m.posts) > 0 {
+				post := m.posts[m.cursor]
+				if err := m.parent.editError(post, m.canReply, m.canModerate); err != nil {
+					return m, func() tea.Msg { return errorMsg{err} }
+				}
+				m.editingPost = post
+			}
+		case key.Matches(msg, m.keys.History):
+			if len(m.posts) > 0 {
+				m.navToHistory = m.posts[m.cursor]
 			}
 		case key.Matches(msg, m.keys.Back):
 			return m, func() tea.Msg { return navigateBackMsg{} }
@@ -106,52 +269,311 @@ func (m *postsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleEvent atualiza o tópico aberto quando outra sessão altera os seus posts. As
+// respostas novas entram no fim da lista sem mover o cursor e são indicadas no rodapé.
+func (m *postsModel) handleEvent(ev events.Event) tea.Cmd {
+	switch ev.Kind {
+	case events.PostCreated:
+		// Os próprios posts já recarregam a tela ao serem criados.
+		if ev.TopicID != m.topic.ID || ev.UserID == int(m.parent.userID) {
+			return nil
+		}
+		m.newReplies++
+		return m.reload()
+	case events.PostEdited:
+		// As próprias edições já recarregam a tela ao serem salvas.
+		if ev.UserID == int(m.parent.userID) {
+			return nil
+		}
+		for _, post := range m.posts {
+			if post.ID == ev.PostID {
+				return m.reload()
+			}
+		}
+	case events.PostDeleted:
+		for _, post := range m.posts {
+			if post.ID == ev.PostID {
+				return m.reload()
+			}
+		}
+	case events.TopicDeleted:
+		if ev.TopicID == m.topic.ID {
+			m.parent.statusMessage = "Este tópico foi removido."
+			return tea.Batch(m.reload(), tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} }))
+		}
+	case events.ForumDeleted:
+		if ev.ForumID == int64(m.topic.ForumID) {
+			m.parent.statusMessage = "Este fórum foi removido."
+			return tea.Batch(m.reload(), tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} }))
+		}
+	case events.TrashRestored:
+		// Um post restaurado volta ao tópico; restaurar o tópico ou o fórum traz os posts de volta.
+		if ev.TopicID == m.topic.ID || (ev.TopicID == 0 && ev.ForumID == int64(m.topic.ForumID)) {
+			return m.reload()
+		}
+	}
+	return nil
+}
+
+// reload recarrega os posts já carregados, ou agenda a recarga se houver um
+// carregamento em andamento.
+func (m *postsModel) reload() tea.Cmd {
+	if m.pager.deferReload() {
+		return nil
+	}
+	return m.Init()
+}
+
+// updateReader trata as teclas de navegação e rolagem do leitor. Retorna false se a
+// tecla não for do leitor.
+func (m *postsModel) updateReader(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.followPosts = true
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.posts)-1 {
+			m.cursor++
+		}
+		m.followPosts = true
+		if m.cursor == len(m.posts)-1 {
+			return m.loadMore(), true
+		}
+	case key.Matches(msg, m.keys.PageUp):
+		m.cursor = max(m.cursor-postsPageSize, 0)
+		m.followPosts = true
+	case key.Matches(msg, m.keys.PageDown):
+		m.followPosts = true
+		target := m.cursor + postsPageSize
+		if target < len(m.posts) {
+			m.cursor = target
+		} else if cmd := m.loadMore(); cmd != nil {
+			m.pager.target = target
+			return cmd, true
+		} else {
+			m.cursor = max(len(m.posts)-1, 0)
+		}
+	case key.Matches(msg, m.keys.Top):
+		m.cursor = 0
+		m.followPosts = true
+	case key.Matches(msg, m.keys.Bottom):
+		m.followPosts = true
+		// Carrega de uma vez todos os posts que faltam até o último.
+		if missing := m.pager.total - len(m.posts); missing > 0 && !m.pager.loading && len(m.posts) > 0 {
+			m.pager.target = m.pager.total - 1
+			return m.loadPostsCmd(m.posts[len(m.posts)-1].Cursor(), missing, true), true
+		}
+		m.cursor = max(len(m.posts)-1, 0)
+	case key.Matches(msg, m.keys.Unread):
+		m.followPosts = true
+		return m.seekFirstUnread(), true
+	case key.Matches(msg, m.keys.Raw):
+		if len(m.posts) > 0 {
+			id := m.posts[m.cursor].ID
+			m.rawPosts[id] = !m.rawPosts[id]
+			m.followPosts = true
+		}
+	case key.Matches(msg, m.keys.HalfUp):
+		m.viewport.HalfPageUp()
+		m.selectVisiblePost()
+	case key.Matches(msg, m.keys.HalfDown):
+		m.viewport.HalfPageDown()
+		m.selectVisiblePost()
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// seekFirstUnread seleciona o primeiro post que não era lido quando o tópico foi aberto,
+// carregando as páginas seguintes se necessário. Sem posts não lidos, vai para o último.
+func (m *postsModel) seekFirstUnread() tea.Cmd {
+	for i, post := range m.posts {
+		if post.ID > m.unreadFrom && int64(post.UserID) != m.parent.userID {
+			m.cursor = i
+			return nil
+		}
+	}
+	if cmd := m.loadMore(); cmd != nil {
+		m.seekUnread = true
+		return cmd
+	}
+	m.cursor = max(len(m.posts)-1, 0)
+	return nil
+}
+
+// markReadCmd registra como lido o post mais recente visível na tela.
+func (m *postsModel) markReadCmd() tea.Cmd {
+	if !m.readLoaded || m.parent.userID == 0 {
+		return nil
+	}
+
+	start, _ := m.pager.bounds(m.cursor, len(m.posts))
+	lastID := 0
+	for i, lines := range m.postLines {
+		if m.isVisible(lines) {
+			lastID = max(lastID, m.posts[start+i].ID)
+		}
+	}
+	if lastID <= m.lastRead {
+		return nil
+	}
+	m.lastRead = lastID
+
+	store, userID, topicID := m.parent.store, m.parent.userID, m.topic.ID
+	return func() tea.Msg {
+		if err := store.MarkTopicRead(userID, topicID, lastID); err != nil {
+			return errorMsg{err}
+		}
+		return nil
+	}
+}
+
+// isVisible informa se alguma linha do intervalo aparece no viewport.
+func (m *postsModel) isVisible(lines [2]int) bool {
+	return lines[1] > m.viewport.YOffset && lines[0] < m.viewport.YOffset+m.viewport.Height
+}
+
+// render monta o conteúdo do viewport com os posts da página atual e, se a seleção
+// mudou, rola até o post selecionado.
+func (m *postsModel) render() {
+	width := m.viewport.Width
+	var b strings.Builder
+	m.postLines = m.postLines[:0]
+	line := 0
+
+	start, end := m.pager.bounds(m.cursor, len(m.posts))
+	for i := start; i < end; i++ {
+		post := m.posts[i]
+		style := m.parent.styles.item
+		if i == m.cursor {
+			style = m.parent.styles.selectedItem
+		}
+		authorLine := fmt.Sprintf("De: %s em %s", post.Username, post.CreatedAt.Format(time.RFC822))
+		if post.EditedAt != nil {
+			authorLine += " (editado)"
+		}
+		var content string
+		if m.rawPosts[post.ID] {
+			authorLine += " (fonte)"
+			content = m.parent.styles.item.Width(width).Render(post.Content)
+		} else {
+			// O item tem 2 colunas de recuo à esquerda.
+			content = m.parent.styles.item.Render(m.markdown.render(post.Content, width-2))
+		}
+		block := style.Width(width).Render(authorLine) + "\n" + content + "\n---"
+
+		b.WriteString(block)
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+		height := strings.Count(block, "\n") + 1
+		m.postLines = append(m.postLines, [2]int{line, line + height})
+		line += height
+	}
+	m.viewport.SetContent(b.String())
+
+	// As respostas novas foram vistas quando o último post do tópico é selecionado.
+	if m.newReplies > 0 && m.cursor == len(m.posts)-1 && !m.pager.hasMore(len(m.posts)) {
+		m.newReplies = 0
+	}
+
+	if m.followPosts {
+		m.scrollToCursor()
+		m.followPosts = false
+	}
+}
+
+// scrollToCursor rola o viewport o mínimo necessário para exibir o post selecionado.
+// Posts maiores que a tela são alinhados pelo início.
+func (m *postsModel) scrollToCursor() {
+	start, _ := m.pager.bounds(m.cursor, len(m.posts))
+	i := m.cursor - start
+	if i < 0 || i >= len(m.postLines) {
+		return
+	}
+	top, bottom := m.postLines[i][0], m.postLines[i][1]
+	switch {
+	case top < m.viewport.YOffset:
+		m.viewport.SetYOffset(top)
+	case bottom > m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(min(bottom-m.viewport.Height, top))
+	}
+}
+
+// selectVisiblePost move a seleção para o primeiro post visível quando a rolagem
+// deixa o post selecionado fora da tela.
+func (m *postsModel) selectVisiblePost() {
+	start, _ := m.pager.bounds(m.cursor, len(m.posts))
+	if i := m.cursor - start; i >= 0 && i < len(m.postLines) && m.isVisible(m.postLines[i]) {
+		return
+	}
+	for i, lines := range m.postLines {
+		if m.isVisible(lines) {
+			m.cursor = start + i
+			return
+		}
+	}
+}
+
 func (m *postsModel) View() string {
 	if m.quitting {
 		return ""
 	}
 
-	if m.confirmingDelete {
-		return fmt.Sprintf("Você tem certeza que quer deletar o post selecionado?\n\n(s/n)")
-	}
-
 	var b strings.Builder
-	b.WriteString(headerStyle.Render(fmt.Sprintf("Lendo: %s", m.topic.Title)) + "\n\n")
+	b.WriteString(m.parent.styles.header.Render(fmt.Sprintf("Lendo: %s", m.topic.Title)) + "\n\n")
 
 	if len(m.posts) == 0 {
 		b.WriteString("Nenhuma postagem neste tópico ainda.")
 	} else {
-		for i, post := range m.posts {
-			style := itemStyle
-			if i == m.cursor {
-				style = selectedItemStyle
-			}
-			authorLine := fmt.Sprintf("De: %s em %s", post.Username, post.CreatedAt.Format(time.RFC822))
-			b.WriteString(style.Render(authorLine))
-			b.WriteString("\n")
-			b.WriteString(style.Render(post.Content))
-			b.WriteString("\n---\n")
+		b.WriteString(m.viewport.View() + "\n")
+		b.WriteString(m.parent.styles.footer.Render(fmt.Sprintf("%s • %3.f%%", m.pager.status(m.cursor), m.viewport.ScrollPercent()*100)))
+		if m.pager.loading {
+			b.WriteString(m.parent.styles.footer.Render(" • carregando..."))
 		}
+		if m.newReplies > 0 {
+			b.WriteString(" • " + m.parent.styles.highlight.Render(fmt.Sprintf("%d nova(s) resposta(s) (%s para ver)", m.newReplies, m.keys.Bottom.Help().Key)))
+		}
+		b.WriteString("\n")
 	}
 
-	b.WriteString("\n" + footerStyle.Render(m.helpView()))
+	b.WriteString("\n" + m.renderHelp(m.viewport.Width))
 
 	return b.String()
 }
 
+// renderHelp renderiza a ajuda quebrando as linhas na largura do terminal.
+func (m *postsModel) renderHelp(width int) string {
+	return m.parent.styles.footer.Width(width).Render(m.helpView())
+}
+
 func (m *postsModel) helpView() string {
 	help := []string{
 		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+		m.keys.PageUp.Help().Key + "/" + m.keys.PageDown.Help().Key + " páginas",
+		m.keys.Top.Help().Key + "/" + m.keys.Bottom.Help().Key + " início/fim",
+		m.keys.HalfUp.Help().Key + "/" + m.keys.HalfDown.Help().Key + " rolar",
+		m.keys.Unread.Help().Key + " " + m.keys.Unread.Help().Desc,
+		m.keys.Raw.Help().Key + " " + m.keys.Raw.Help().Desc,
 	}
 
-	if m.parent.Role != "" {
+	if m.canReply {
 		help = append(help, m.keys.New.Help().Key+" "+m.keys.New.Help().Desc)
 	}
 
-	if m.parent.Role == "admin" || m.parent.Role == "moderator" {
+	if m.canModerate {
 		help = append(help, m.keys.Delete.Help().Key+" "+m.keys.Delete.Help().Desc)
 	}
 
+	// Os próprios posts podem ser editados por todos; os dos outros, por quem modera.
+	if !m.parent.readOnly() {
+		help = append(help, m.keys.Edit.Help().Key+" "+m.keys.Edit.Help().Desc)
+	}
+	help = append(help, m.keys.History.Help().Key+" "+m.keys.History.Help().Desc)
+
 	help = append(help, m.keys.Back.Help().Key+" "+m.keys.Back.Help().Desc)
 	help = append(help, m.keys.Quit.Help().Key+" "+m.keys.Quit.Help().Desc)
 
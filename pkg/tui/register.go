@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"modern-bbs/internal/database"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Políticas de cadastro de novas contas.
+const (
+	RegistrationOpen     = "open"     // A conta pode ser usada logo após o cadastro
+	RegistrationInvite   = "invite"   // O cadastro exige um código de convite
+	RegistrationApproval = "approval" // A conta aguarda a aprovação de um administrador
+	RegistrationClosed   = "closed"   // O cadastro fica desabilitado; é o padrão
+)
+
+// Registration configura o cadastro feito pelo próprio usuário, sem autenticação.
+type Registration struct {
+	Policy string
+	// InviteQuotas limita, por papel, quantos cadastros os convites em aberto de cada
+	// usuário podem permitir na política RegistrationInvite. Um valor negativo não impõe
+	// limite; papéis ausentes não podem convidar.
+	InviteQuotas map[string]int
+	Reserved     []string // Nomes que não podem ser cadastrados, como o próprio login de cadastro
+
+	// Throttle, se definida, é chamada antes de cada tentativa de criar a conta; um erro
+	// recusa a tentativa e é exibido no formulário.
+	Throttle func() error
+	// OnRegister, se definida, é chamada depois que uma conta é criada.
+	OnRegister func(user *database.User)
+}
+
+// ValidRegistrationPolicy informa se policy é uma das políticas que abrem o cadastro.
+func ValidRegistrationPolicy(policy string) bool {
+	switch policy {
+	case RegistrationOpen, RegistrationInvite, RegistrationApproval:
+		return true
+	}
+	return false
+}
+
+// InviteQuota retorna a cota de convites do papel (negativa se ilimitada, 0 se o papel
+// não pode convidar). Fora da política RegistrationInvite, ninguém convida.
+func (r *Registration) InviteQuota(role string) int {
+	if r == nil || r.Policy != RegistrationInvite {
+		return 0
+	}
+	return r.InviteQuotas[role]
+}
+
+// Regras dos dados do cadastro.
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]{2,19}$`)
+
+const minPasswordLength = 8
+
+// Nomes dos campos do formulário de cadastro.
+const (
+	registerUsername = "Usuário"
+	registerPassword = "Senha"
+	registerConfirm  = "Confirmação"
+	registerEmail    = "E-mail"
+	registerInvite   = "Convite"
+)
+
+// registrationModel é o programa executado pelo login de cadastro: um formulário que
+// cria a conta e, ao final, pede que o usuário entre novamente com ela.
+type registrationModel struct {
+	store      database.Store
+	config     Registration
+	styles     *styles
+	fields     []FormField
+	focusIndex int
+	err        string
+	submitting bool
+	created    *database.User
+}
+
+type registrationDoneMsg struct {
+	user *database.User
+	err  error
+}
+
+// NewRegistrationModel cria o programa de cadastro com a política informada.
+func NewRegistrationModel(store database.Store, config Registration, renderer *lipgloss.Renderer) tea.Model {
+	username := newTextInput("Nome de usuário (3 a 20 letras, números, _ . -)")
+	password := newTextInput(fmt.Sprintf("Senha (mínimo de %d caracteres)", minPasswordLength))
+	confirm := newTextInput("Confirme a senha")
+	email := newTextInput("E-mail (opcional)")
+	password.(*TextInput).EchoMode = textinput.EchoPassword
+	confirm.(*TextInput).EchoMode = textinput.EchoPassword
+	username.Focus()
+
+	fields := []FormField{
+		{Name: registerUsername, Input: username},
+		{Name: registerPassword, Input: password},
+		{Name: registerConfirm, Input: confirm},
+		{Name: registerEmail, Input: email},
+	}
+	if config.Policy == RegistrationInvite {
+		fields = append(fields, FormField{Name: registerInvite, Input: newTextInput("Código de convite")})
+	}
+
+	return &registrationModel{
+		store:  store,
+		config: config,
+		styles: newStyles(renderer),
+		fields: fields,
+	}
+}
+
+func (m *registrationModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *registrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case registrationDoneMsg:
+		m.submitting = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.created = msg.user
+		if m.config.OnRegister != nil {
+			m.config.OnRegister(msg.user)
+		}
+		return m, nil
+	case DisconnectMsg:
+		return m, tea.Quit
+	case tea.KeyMsg:
+		// Depois do cadastro, qualquer tecla encerra a sessão.
+		if m.created != nil {
+			return m, tea.Quit
+		}
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		}
+		if m.submitting {
+			return m, nil
+		}
+		switch msg.Type {
+		case tea.KeyEnter:
+			if m.focusIndex == len(m.fields)-1 {
+				return m, m.submit()
+			}
+			return m, m.focus(m.focusIndex + 1)
+		case tea.KeyCtrlS:
+			return m, m.submit()
+		case tea.KeyTab, tea.KeyDown:
+			return m, m.focus(m.focusIndex + 1)
+		case tea.KeyShiftTab, tea.KeyUp:
+			return m, m.focus(m.focusIndex - 1)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.fields[m.focusIndex].Input, cmd = m.fields[m.focusIndex].Input.Update(msg)
+	return m, cmd
+}
+
+func (m *registrationModel) focus(i int) tea.Cmd {
+	m.fields[m.focusIndex].Input.Blur()
+	m.focusIndex = (i + len(m.fields)) % len(m.fields)
+	return m.fields[m.focusIndex].Input.Focus()
+}
+
+// submit valida o formulário e cria a conta em segundo plano, já que o hash da senha é lento.
+func (m *registrationModel) submit() tea.Cmd {
+	values := make(map[string]string)
+	for _, field := range m.fields {
+		values[field.Name] = field.Input.Value()
+	}
+	username := strings.TrimSpace(values[registerUsername])
+	email := strings.TrimSpace(values[registerEmail])
+
+	if err := m.validate(username, values[registerPassword], values[registerConfirm], email, strings.TrimSpace(values[registerInvite])); err != nil {
+		m.err = err.Error()
+		return nil
+	}
+
+	m.err = ""
+	m.submitting = true
+	account := database.NewAccount{
+		Username:   username,
+		Password:   values[registerPassword],
+		Email:      email,
+		Pending:    m.config.Policy == RegistrationApproval,
+		InviteCode: strings.TrimSpace(values[registerInvite]),
+	}
+	return func() tea.Msg {
+		if m.config.Throttle != nil {
+			if err := m.config.Throttle(); err != nil {
+				return registrationDoneMsg{err: err}
+			}
+		}
+		user, err := m.store.RegisterUser(account)
+		if errors.Is(err, database.ErrUsernameTaken) {
+			err = fmt.Errorf("o nome de usuário '%s' já está em uso", username)
+		}
+		return registrationDoneMsg{user: user, err: err}
+	}
+}
+
+func (m *registrationModel) validate(username, password, confirm, email, invite string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("o nome de usuário deve ter de 3 a 20 caracteres, começar com uma letra e usar apenas letras, números, _ . -")
+	}
+	for _, reserved := range m.config.Reserved {
+		if strings.EqualFold(username, reserved) {
+			return fmt.Errorf("o nome de usuário '%s' é reservado", username)
+		}
+	}
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("a senha deve ter pelo menos %d caracteres", minPasswordLength)
+	}
+	if password != confirm {
+		return fmt.Errorf("a confirmação não confere com a senha")
+	}
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return fmt.Errorf("e-mail inválido")
+		}
+	}
+	// O código em si é conferido pelo RegisterUser, que o consome junto com a criação da conta.
+	if m.config.Policy == RegistrationInvite && invite == "" {
+		return fmt.Errorf("informe o código de convite")
+	}
+	return nil
+}
+
+func (m *registrationModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.styles.header.Render("Cadastro de Novo Usuário") + "\n\n")
+
+	if m.created != nil {
+		if m.created.Pending() {
+			b.WriteString(m.styles.statusMessage.Render(fmt.Sprintf("Cadastro de '%s' recebido!", m.created.Username)) + "\n\n")
+			b.WriteString("Você poderá entrar assim que um administrador aprovar a sua conta.\n")
+		} else {
+			b.WriteString(m.styles.statusMessage.Render(fmt.Sprintf("Conta '%s' criada com sucesso!", m.created.Username)) + "\n\n")
+			b.WriteString(fmt.Sprintf("Conecte-se novamente com o usuário '%s' e a senha cadastrada.\n", m.created.Username))
+		}
+		b.WriteString("\n" + m.styles.footer.Render("Pressione qualquer tecla para sair."))
+		return b.String()
+	}
+
+	switch m.config.Policy {
+	case RegistrationInvite:
+		b.WriteString("O cadastro exige um código de convite.\n\n")
+	case RegistrationApproval:
+		b.WriteString("A nova conta será liberada depois da aprovação de um administrador.\n\n")
+	}
+
+	for i := range m.fields {
+		b.WriteString(m.fields[i].Input.View() + "\n")
+	}
+
+	if m.submitting {
+		b.WriteString("\nCriando a conta...\n")
+	} else if m.err != "" {
+		b.WriteString("\n" + m.styles.errorStatusMessage.Render("Erro: "+m.err) + "\n")
+	}
+	b.WriteString("\n" + m.styles.footer.Render("(Enter avança, Ctrl+S cadastra, Esc cancela)"))
+	return b.String()
+}
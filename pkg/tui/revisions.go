@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"modern-bbs/internal/diff"
+	"modern-bbs/internal/events"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// revisionsListHeight é o número de edições exibidas de uma vez na lista.
+const revisionsListHeight = 5
+
+// revisionsModel mostra o histórico de edições de um post: a lista das edições, com quem
+// editou e quando, e as linhas alteradas pela edição selecionada.
+type revisionsModel struct {
+	keys      *KeyMap
+	parent    *mainModel
+	post      *database.Post
+	revisions []database.PostRevision
+	loaded    bool
+	quitting  bool
+
+	// A edição i transforma a revisão i na seguinte, ou no conteúdo atual se for a última.
+	cursor   int
+	viewport viewport.Model
+}
+
+type revisionsLoadedMsg struct {
+	post      *database.Post
+	revisions []database.PostRevision
+	err       error
+}
+
+// NewRevisionsModel cria a tela de histórico do post.
+func NewRevisionsModel(parent *mainModel, post *database.Post) *revisionsModel {
+	m := &revisionsModel{
+		keys:     DefaultKeyMap,
+		parent:   parent,
+		post:     post,
+		viewport: viewport.New(0, 0),
+	}
+	m.setSize(parent.width, parent.height)
+	return m
+}
+
+// setSize ajusta o viewport ao terminal, descontando o cabeçalho e o rodapé da
+// aplicação, o título, a lista de edições e o resumo da edição selecionada.
+func (m *revisionsModel) setSize(width, height int) {
+	if width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+	m.viewport.Width = width
+	m.viewport.Height = max(height-10-revisionsListHeight, 3)
+	m.render()
+}
+
+// Init carrega o post e as suas revisões.
+func (m *revisionsModel) Init() tea.Cmd {
+	store, viewerID, postID := m.parent.store, m.parent.userID, m.post.ID
+	return func() tea.Msg {
+		post, err := store.GetPostByID(postID)
+		if err != nil {
+			return revisionsLoadedMsg{err: err}
+		}
+		if post == nil {
+			return revisionsLoadedMsg{err: fmt.Errorf("o post foi removido")}
+		}
+		revisions, err := store.GetPostRevisions(viewerID, postID)
+		return revisionsLoadedMsg{post: post, revisions: revisions, err: err}
+	}
+}
+
+func (m *revisionsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case revisionsLoadedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		// Na primeira carga, a edição mais recente é a selecionada.
+		if !m.loaded {
+			m.cursor = len(msg.revisions) - 1
+		}
+		m.loaded = true
+		m.post, m.revisions = msg.post, msg.revisions
+		m.cursor = max(min(m.cursor, len(m.revisions)-1), 0)
+		m.render()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+				m.render()
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.revisions)-1 {
+				m.cursor++
+				m.render()
+			}
+		case key.Matches(msg, m.keys.PageUp), key.Matches(msg, m.keys.HalfUp):
+			m.viewport.HalfPageUp()
+		case key.Matches(msg, m.keys.PageDown), key.Matches(msg, m.keys.HalfDown):
+			m.viewport.HalfPageDown()
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// handleEvent recarrega o histórico quando o post é editado em outra sessão.
+func (m *revisionsModel) handleEvent(ev events.Event) tea.Cmd {
+	if ev.Kind == events.PostEdited && ev.PostID == m.post.ID {
+		return m.Init()
+	}
+	return nil
+}
+
+// versions retorna o conteúdo antes e depois da edição i.
+func (m *revisionsModel) versions(i int) (string, string) {
+	if i == len(m.revisions)-1 {
+		return m.revisions[i].Content, m.post.Content
+	}
+	return m.revisions[i].Content, m.revisions[i+1].Content
+}
+
+// render monta no viewport as linhas alteradas pela edição selecionada.
+func (m *revisionsModel) render() {
+	if len(m.revisions) == 0 {
+		m.viewport.SetContent("")
+		return
+	}
+	before, after := m.versions(m.cursor)
+	var b strings.Builder
+	for i, line := range diff.Lines(before, after) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		switch line.Kind {
+		case diff.Insert:
+			b.WriteString(m.parent.styles.diffInsert.Width(m.viewport.Width).Render("+ " + line.Text))
+		case diff.Delete:
+			b.WriteString(m.parent.styles.diffDelete.Width(m.viewport.Width).Render("- " + line.Text))
+		default:
+			b.WriteString(m.parent.styles.footer.Width(m.viewport.Width).Render("  " + line.Text))
+		}
+	}
+	m.viewport.SetContent(b.String())
+	m.viewport.GotoTop()
+}
+
+// editLabel descreve a edição i: quem editou e quando.
+func (m *revisionsModel) editLabel(i int) string {
+	r := m.revisions[i]
+	editor := r.Editor
+	if editor == "" {
+		editor = "(conta removida)"
+	}
+	return fmt.Sprintf("Edição %d por %s em %s", i+1, editor, r.EditedAt.Format(time.RFC822))
+}
+
+func (m *revisionsModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render(fmt.Sprintf("Histórico do post de %s em %s", m.post.Username, m.post.CreatedAt.Format(time.RFC822))) + "\n\n")
+
+	if !m.loaded {
+		return b.String() + "Carregando..."
+	}
+	if len(m.revisions) == 0 {
+		return b.String() + "Este post não foi editado."
+	}
+
+	// A lista acompanha a seleção quando há mais edições do que cabem na tela.
+	start := max(min(m.cursor-revisionsListHeight/2, len(m.revisions)-revisionsListHeight), 0)
+	end := min(start+revisionsListHeight, len(m.revisions))
+	for i := start; i < end; i++ {
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> "+m.editLabel(i)) + "\n")
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  "+m.editLabel(i)) + "\n")
+		}
+	}
+
+	added, removed := 0, 0
+	before, after := m.versions(m.cursor)
+	for _, line := range diff.Lines(before, after) {
+		switch line.Kind {
+		case diff.Insert:
+			added++
+		case diff.Delete:
+			removed++
+		}
+	}
+	b.WriteString("\n" + m.parent.styles.footer.Render(fmt.Sprintf("Edição %d de %d: %d linha(s) incluída(s), %d removida(s)", m.cursor+1, len(m.revisions), added, removed)) + "\n")
+	b.WriteString(m.viewport.View())
+	return b.String()
+}
+
+func (m *revisionsModel) helpView() string {
+	return strings.Join([]string{
+		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " edições",
+		m.keys.HalfUp.Help().Key + "/" + m.keys.HalfDown.Help().Key + " rolar",
+		m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+	}, " • ")
+}
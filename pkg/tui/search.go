@@ -0,0 +1,307 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchModel representa a visão de busca de posts e tópicos.
+type searchModel struct {
+	keys     *KeyMap
+	parent   *mainModel
+	input    textinput.Model
+	results  []database.SearchResult
+	cursor   int
+	offset   int  // Primeiro resultado visível
+	searched bool // Indica se ao menos uma busca foi feita
+	quitting bool
+
+	navToPosts  *database.Topic // Tópico para o qual navegar
+	focusPostID int             // Post a selecionar ao abrir o tópico
+}
+
+type searchResultsMsg struct {
+	results []database.SearchResult
+	err     error
+}
+
+type searchTopicLoadedMsg struct {
+	topic  *database.Topic
+	postID int
+}
+
+// NewSearchModel cria um novo modelo para a visão de busca.
+func NewSearchModel(parent *mainModel) *searchModel {
+	ti := textinput.New()
+	ti.Placeholder = "termos forum:nome autor:usuario desde:AAAA-MM-DD ate:AAAA-MM-DD"
+	ti.CharLimit = 200
+	ti.Width = 80
+	ti.Focus()
+
+	return &searchModel{
+		keys:   DefaultKeyMap,
+		parent: parent,
+		input:  ti,
+	}
+}
+
+func (m *searchModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// searchCmd interpreta os filtros digitados e executa a busca.
+func (m *searchModel) searchCmd(raw string) tea.Cmd {
+	store, userID := m.parent.store, m.parent.userID
+	return func() tea.Msg {
+		query, filters, err := parseSearchQuery(store, userID, raw)
+		if err != nil {
+			return searchResultsMsg{err: err}
+		}
+		results, err := store.Search(query, filters)
+		return searchResultsMsg{results: results, err: err}
+	}
+}
+
+func (m *searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case searchResultsMsg:
+		m.parent.isLoading = false
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		m.results = msg.results
+		m.cursor, m.offset = 0, 0
+		m.searched = true
+		if len(m.results) > 0 {
+			m.input.Blur()
+		}
+		return m, nil
+	case searchTopicLoadedMsg:
+		if msg.topic == nil {
+			return m, func() tea.Msg { return errorMsg{fmt.Errorf("o tópico não existe mais")} }
+		}
+		m.navToPosts = msg.topic
+		m.focusPostID = msg.postID
+		return m, nil // O mainModel irá lidar com a navegação
+	case tea.KeyMsg:
+		if m.input.Focused() {
+			switch msg.String() {
+			case "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			case "esc":
+				return m, func() tea.Msg { return navigateBackMsg{} }
+			case "enter":
+				if strings.TrimSpace(m.input.Value()) == "" {
+					return m, nil
+				}
+				m.parent.isLoading = true
+				return m, m.searchCmd(m.input.Value())
+			case "down", "tab":
+				if len(m.results) > 0 {
+					m.input.Blur()
+				}
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+
+		switch {
+		case msg.String() == "/" || msg.String() == "tab":
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			} else {
+				return m, m.input.Focus()
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Enter):
+			if len(m.results) > 0 {
+				result := m.results[m.cursor]
+				return m, func() tea.Msg {
+					topic, err := m.parent.store.GetVisibleTopic(m.parent.userID, result.TopicID)
+					if err != nil {
+						return errorMsg{err}
+					}
+					return searchTopicLoadedMsg{topic: topic, postID: result.PostID}
+				}
+			}
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// visibleResults calcula quantos resultados cabem na tela. Cada resultado ocupa três linhas.
+func (m *searchModel) visibleResults() int {
+	if m.parent.height == 0 {
+		return 10
+	}
+	return max((m.parent.height-10)/3, 1)
+}
+
+func (m *searchModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Buscar") + "\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	if !m.searched {
+		return b.String()
+	}
+	if len(m.results) == 0 {
+		b.WriteString("Nenhum resultado encontrado.\n")
+		return b.String()
+	}
+
+	// Mantém o cursor dentro da janela visível.
+	visible := m.visibleResults()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+	end := min(m.offset+visible, len(m.results))
+
+	b.WriteString(fmt.Sprintf("%d resultado(s)\n\n", len(m.results)))
+	for i := m.offset; i < end; i++ {
+		r := m.results[i]
+		style := m.parent.styles.item
+		cursor := " "
+		if i == m.cursor && !m.input.Focused() {
+			style = m.parent.styles.selectedItem
+			cursor = ">"
+		}
+
+		kind := "post"
+		if r.PostID == 0 {
+			kind = "tópico"
+		}
+		title := fmt.Sprintf("%s %s > %s (%s de %s, %s)", cursor, r.ForumName, r.TopicTitle, kind, r.Author, r.CreatedAt.Format("02/01/2006"))
+		b.WriteString(style.Render(title) + "\n")
+		b.WriteString(m.parent.styles.item.Render("  "+m.renderSnippet(r.Snippet)) + "\n\n")
+	}
+
+	return b.String()
+}
+
+// renderSnippet aplica o estilo de destaque aos termos marcados pelo banco de dados.
+func (m *searchModel) renderSnippet(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, database.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], database.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(snippet[:start])
+		b.WriteString(m.parent.styles.highlight.Render(snippet[start+len(database.HighlightStart) : end]))
+		snippet = snippet[end+len(database.HighlightEnd):]
+	}
+	b.WriteString(snippet)
+
+	return b.String()
+}
+
+func (m *searchModel) helpView() string {
+	if m.input.Focused() {
+		return "enter buscar • ↓/tab resultados • esc voltar"
+	}
+	help := []string{
+		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+		m.keys.Enter.Help().Key + " abrir",
+		"/ nova busca",
+		m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+	}
+	return strings.Join(help, " • ")
+}
+
+// parseSearchQuery separa os filtros (forum:, autor:, desde:, ate:) dos termos da busca.
+// O fórum pode ser informado pelo nome ou pelo ID; as datas, no formato AAAA-MM-DD. A
+// busca fica restrita aos fóruns que o usuário pode ver.
+func parseSearchQuery(store database.Store, userID int64, raw string) (string, database.SearchFilters, error) {
+	filters := database.SearchFilters{ViewerID: userID}
+	var terms []string
+
+	for _, field := range strings.Fields(raw) {
+		name, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			terms = append(terms, field)
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "forum", "fórum":
+			id, err := findForumID(store, userID, value)
+			if err != nil {
+				return "", filters, err
+			}
+			filters.ForumID = id
+		case "autor":
+			filters.Author = value
+		case "desde":
+			t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return "", filters, fmt.Errorf("data inválida em 'desde': use AAAA-MM-DD")
+			}
+			filters.Since = t
+		case "ate", "até":
+			t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return "", filters, fmt.Errorf("data inválida em 'ate': use AAAA-MM-DD")
+			}
+			// A data final é inclusiva.
+			filters.Until = t.AddDate(0, 0, 1)
+		default:
+			terms = append(terms, field)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", filters, fmt.Errorf("informe ao menos um termo para buscar")
+	}
+
+	return strings.Join(terms, " "), filters, nil
+}
+
+// findForumID localiza um fórum pelo ID ou pelo nome, sem diferenciar maiúsculas.
+func findForumID(store database.Store, userID int64, value string) (int64, error) {
+	forums, err := store.GetVisibleForums(userID)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := strconv.ParseInt(value, 10, 64)
+	for _, f := range forums {
+		if f.ID == id || strings.EqualFold(f.Name, value) {
+			return f.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("fórum '%s' não encontrado", value)
+}
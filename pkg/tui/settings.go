@@ -2,6 +2,8 @@ package tui
 
 import (
 	"fmt"
+	"modern-bbs/internal/database"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,6 +15,29 @@ type settingsModel struct {
 	keys    *KeyMap
 	choices []string
 	cursor  int
+	// Estado da tela de chaves SSH
+	managingKeys     bool
+	sshKeys          []database.UserKey
+	keyCursor        int
+	passwordDisabled bool
+	confirmingDelete bool
+	// Estado da tela de convites
+	managingInvites  bool
+	invites          []database.Invite
+	inviteCursor     int
+	confirmingRevoke bool
+	// Estado da tela de verificação em duas etapas
+	managingTwoFactor bool
+	twoFactorEnabled  bool
+	recoveryLeft      int
+	enrollment        *twoFactorEnrollment
+	recoveryCodes     []string // Códigos recém-gerados, exibidos uma única vez
+	confirmingDisable bool
+}
+
+type userKeysLoadedMsg struct {
+	keys             []database.UserKey
+	passwordDisabled bool
 }
 
 // NewSettingsModel cria um novo modelo para a visão de configurações.
@@ -23,7 +48,10 @@ func NewSettingsModel(parent *mainModel) *settingsModel {
 	}
 
 	// Define as opções com base no papel do usuário.
-	m.choices = append(m.choices, "Alterar Senha")
+	m.choices = append(m.choices, "Alterar Senha", "Chaves SSH", "Verificação em Duas Etapas")
+	if parent.registration.InviteQuota(parent.Role) != 0 {
+		m.choices = append(m.choices, "Convites")
+	}
 	if parent.Role == "moderator" || parent.Role == "admin" {
 		m.choices = append(m.choices, "Gerenciar Usuários")
 	}
@@ -38,9 +66,54 @@ func (m *settingsModel) Init() tea.Cmd {
 	return nil
 }
 
+// loadKeysCmd carrega as chaves SSH e a preferência de login por senha do usuário.
+func (m *settingsModel) loadKeysCmd() tea.Msg {
+	keys, err := m.parent.store.GetUserKeys(m.parent.User)
+	if err != nil {
+		return errorMsg{err}
+	}
+	disabled, err := m.parent.store.IsPasswordLoginDisabled(m.parent.User)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return userKeysLoadedMsg{keys: keys, passwordDisabled: disabled}
+}
+
 func (m *settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case invitesLoadedMsg:
+		m.invites = msg.invites
+		m.inviteCursor = max(min(m.inviteCursor, len(m.invites)-1), 0)
+		return m, nil
+	case twoFactorLoadedMsg:
+		m.twoFactorEnabled = msg.enabled
+		m.recoveryLeft = msg.recoveryLeft
+		return m, nil
+	case twoFactorEnabledMsg:
+		if m.enrollment != nil {
+			return m, m.enrollment.update(msg)
+		}
+		return m, nil
+	case recoveryCodesMsg:
+		m.recoveryCodes = msg.codes
+		return m, m.loadTwoFactorCmd
+	case userKeysLoadedMsg:
+		m.sshKeys = msg.keys
+		m.passwordDisabled = msg.passwordDisabled
+		if m.keyCursor >= len(m.sshKeys) {
+			m.keyCursor = max(len(m.sshKeys)-1, 0)
+		}
+		return m, nil
 	case tea.KeyMsg:
+		if m.managingKeys {
+			return m.updateKeys(msg)
+		}
+		if m.managingInvites {
+			return m.updateInvites(msg)
+		}
+		if m.managingTwoFactor {
+			return m.updateTwoFactor(msg)
+		}
 		switch {
 		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
@@ -56,6 +129,17 @@ func (m *settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.parent.currentView = formView
 				m.parent.formModel = NewChangePasswordFormModel(m.parent)
 				return m.parent, m.parent.formModel.Init()
+			case "Chaves SSH":
+				m.managingKeys = true
+				m.keyCursor = 0
+				return m, m.loadKeysCmd
+			case "Verificação em Duas Etapas":
+				m.managingTwoFactor = true
+				return m, m.loadTwoFactorCmd
+			case "Convites":
+				m.managingInvites = true
+				m.inviteCursor = 0
+				return m, m.loadInvitesCmd
 			case "Gerenciar Usuários":
 				m.parent.currentView = userManagementView
 				m.parent.breadcrumbs = append(m.parent.breadcrumbs, "Gerenciar Usuários")
@@ -74,22 +158,143 @@ func (m *settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateKeys lida com as teclas na tela de chaves SSH.
+func (m *settingsModel) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmingDelete {
+		switch msg.String() {
+		case "s", "S":
+			m.confirmingDelete = false
+			if len(m.sshKeys) == 0 {
+				return m, nil
+			}
+			fingerprint := m.sshKeys[m.keyCursor].Fingerprint
+			return m, func() tea.Msg {
+				if err := m.parent.store.RemoveUserKey(m.parent.User, fingerprint); err != nil {
+					return statusMessage{success: false, message: err.Error()}
+				}
+				return statusMessage{success: true, message: "Chave removida com sucesso!"}
+			}
+		case "n", "N":
+			m.confirmingDelete = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.keyCursor > 0 {
+			m.keyCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.keyCursor < len(m.sshKeys)-1 {
+			m.keyCursor++
+		}
+	case key.Matches(msg, m.keys.New):
+		m.parent.currentView = formView
+		m.parent.formModel = NewAddKeyFormModel(m.parent)
+		return m.parent, m.parent.formModel.Init()
+	case key.Matches(msg, m.keys.Delete):
+		if len(m.sshKeys) > 0 {
+			m.confirmingDelete = true
+		}
+	case msg.String() == "p":
+		disable := !m.passwordDisabled
+		return m, func() tea.Msg {
+			if err := m.parent.store.SetPasswordLoginDisabled(m.parent.User, disable); err != nil {
+				return statusMessage{success: false, message: err.Error()}
+			}
+			if disable {
+				return statusMessage{success: true, message: "Login por senha desabilitado."}
+			}
+			return statusMessage{success: true, message: "Login por senha habilitado."}
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.managingKeys = false
+		m.confirmingDelete = false
+	}
+	return m, nil
+}
+
 func (m *settingsModel) View() string {
+	if m.managingKeys {
+		return m.viewKeys()
+	}
+	if m.managingInvites {
+		return m.viewInvites()
+	}
+	if m.managingTwoFactor {
+		return m.viewTwoFactor()
+	}
+
 	body := "Selecione uma opção de configuração:\n\n"
 	for i, choice := range m.choices {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
-			body += selectedItemStyle.Render(fmt.Sprintf("%s %s", cursor, choice))
+			body += m.parent.styles.selectedItem.Render(fmt.Sprintf("%s %s", cursor, choice))
 		} else {
-			body += itemStyle.Render(fmt.Sprintf("%s %s", cursor, choice))
+			body += m.parent.styles.item.Render(fmt.Sprintf("%s %s", cursor, choice))
 		}
 		body += "\n"
 	}
 	return body
 }
 
+func (m *settingsModel) viewKeys() string {
+	var b strings.Builder
+	b.WriteString("Chaves SSH autorizadas:\n\n")
+
+	if len(m.sshKeys) == 0 {
+		b.WriteString("Nenhuma chave cadastrada.\n")
+	}
+	for i, k := range m.sshKeys {
+		lastUsed := "nunca usada"
+		if k.LastUsedAt != nil {
+			lastUsed = "último uso em " + k.LastUsedAt.Format("02/01/2006 15:04")
+		}
+		line := fmt.Sprintf("%s %s %s (adicionada em %s, %s)", k.KeyType, k.Fingerprint, k.Comment, k.AddedAt.Format("02/01/2006"), lastUsed)
+		if m.keyCursor == i {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	status := "habilitado"
+	if m.passwordDisabled {
+		status = "desabilitado"
+	}
+	b.WriteString(fmt.Sprintf("\nLogin por senha: %s\n", status))
+
+	if m.confirmingDelete && len(m.sshKeys) > 0 {
+		b.WriteString(fmt.Sprintf("\nTem certeza que deseja remover a chave %s? (s/n)\n", m.sshKeys[m.keyCursor].Fingerprint))
+	}
+
+	return b.String()
+}
+
 func (m *settingsModel) helpView() string {
+	if m.managingTwoFactor {
+		return "\n  " + m.twoFactorHelp()
+	}
+	if m.managingInvites {
+		return fmt.Sprintf("\n  %s • %s • %s • %s",
+			m.keys.New.Help().Key+" novo convite",
+			m.keys.Delete.Help().Key+" revogar convite",
+			m.keys.Back.Help().Key+" "+m.keys.Back.Help().Desc,
+			m.keys.Quit.Help().Key+" "+m.keys.Quit.Help().Desc,
+		)
+	}
+	if m.managingKeys {
+		return fmt.Sprintf("\n  %s • %s • %s • %s • %s",
+			m.keys.New.Help().Key+" adicionar chave",
+			m.keys.Delete.Help().Key+" remover chave",
+			"p habilitar/desabilitar senha",
+			m.keys.Back.Help().Key+" "+m.keys.Back.Help().Desc,
+			m.keys.Quit.Help().Key+" "+m.keys.Quit.Help().Desc,
+		)
+	}
 	return fmt.Sprintf("\n  %s • %s • %s • %s",
 		m.keys.Up.Help().Key+" "+m.keys.Up.Help().Desc,
 		m.keys.Down.Help().Key+" "+m.keys.Down.Help().Desc,
@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sysop reúne as ações de administração que afetam todas as sessões do servidor.
+// É implementada pelo servidor SSH e repassada à TUI com WithSysop.
+type Sysop interface {
+	// Broadcast exibe um aviso no topo da tela de todas as sessões.
+	Broadcast(from, text string) error
+	// ScheduleShutdown programa o desligamento do servidor; as sessões veem a contagem
+	// regressiva e são desconectadas ao fim do prazo.
+	ScheduleShutdown(from string, delay time.Duration, reason string) error
+	CancelShutdown(from string) error
+	// PendingShutdown retorna o desligamento programado, se houver.
+	PendingShutdown() (at time.Time, reason string, ok bool)
+}
+
+// BroadcastMsg é um aviso da administração, exibido no topo da tela por bannerDuration.
+type BroadcastMsg struct {
+	From string
+	Text string
+	Time time.Time
+}
+
+// ShutdownMsg anuncia o desligamento programado do servidor. At zerado indica que o
+// desligamento foi cancelado.
+type ShutdownMsg struct {
+	At     time.Time
+	Reason string
+}
+
+// DisconnectMsg encerra a sessão exibindo o motivo ao usuário.
+type DisconnectMsg struct {
+	Reason string
+}
+
+// bannerDuration é o tempo em que um aviso da administração fica na tela.
+const bannerDuration = time.Minute
+
+type bannerTimeoutMsg struct{ seq int }
+type shutdownTickMsg struct{ seq int }
+
+// sysopActionMsg informa o resultado de uma ação da tela de avisos.
+type sysopActionMsg struct{ status string }
+
+// showBanner exibe o aviso e agenda a sua remoção. Um aviso novo substitui o anterior.
+func (m *mainModel) showBanner(msg BroadcastMsg) tea.Cmd {
+	m.banner = &msg
+	m.bannerSeq++
+	seq := m.bannerSeq
+	return tea.Tick(bannerDuration, func(time.Time) tea.Msg { return bannerTimeoutMsg{seq: seq} })
+}
+
+// setShutdown registra o desligamento anunciado e inicia a contagem regressiva.
+func (m *mainModel) setShutdown(msg ShutdownMsg) tea.Cmd {
+	m.shutdown = msg
+	m.shutdownSeq++
+	if msg.At.IsZero() {
+		return nil
+	}
+	return m.shutdownTick()
+}
+
+func (m *mainModel) shutdownTick() tea.Cmd {
+	seq := m.shutdownSeq
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return shutdownTickMsg{seq: seq} })
+}
+
+// renderNotices renderiza o aviso e a contagem regressiva do desligamento, se houver.
+func (m *mainModel) renderNotices() string {
+	var lines []string
+	if m.banner != nil {
+		lines = append(lines, m.styles.highlight.Render(
+			fmt.Sprintf("» Aviso de %s (%s): %s", m.banner.From, m.banner.Time.Local().Format("15:04"), m.banner.Text)))
+	}
+	if !m.shutdown.At.IsZero() {
+		line := fmt.Sprintf("» O servidor será desligado em %s", formatCountdown(time.Until(m.shutdown.At)))
+		if m.shutdown.Reason != "" {
+			line += ": " + m.shutdown.Reason
+		}
+		lines = append(lines, m.styles.errorStatusMessage.Render(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatCountdown formata o tempo restante como "m:ss" (ou "h:mm:ss").
+func formatCountdown(d time.Duration) string {
+	d = max(d.Round(time.Second), 0)
+	h, mins, secs := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
+
+// sysopModel representa a tela "Avisos do Sistema", em que administradores enviam
+// avisos a todas as sessões e programam o desligamento do servidor.
+type sysopModel struct {
+	keys     *KeyMap
+	parent   *mainModel
+	choices  []string
+	cursor   int
+	quitting bool
+
+	navigateToBroadcast bool
+	navigateToShutdown  bool
+}
+
+// NewSysopModel cria a tela de avisos do sistema.
+func NewSysopModel(parent *mainModel) *sysopModel {
+	m := &sysopModel{
+		keys:   DefaultKeyMap,
+		parent: parent,
+	}
+	m.refresh()
+	return m
+}
+
+func (m *sysopModel) Init() tea.Cmd {
+	m.refresh()
+	return nil
+}
+
+// refresh monta as opções conforme haja ou não um desligamento programado.
+func (m *sysopModel) refresh() {
+	m.choices = []string{"Enviar aviso a todos"}
+	if _, _, pending := m.parent.sysop.PendingShutdown(); pending {
+		m.choices = append(m.choices, "Cancelar desligamento")
+	} else {
+		m.choices = append(m.choices, "Programar desligamento")
+	}
+	m.cursor = min(m.cursor, len(m.choices)-1)
+}
+
+func (m *sysopModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.choices)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Enter):
+			switch m.choices[m.cursor] {
+			case "Enviar aviso a todos":
+				m.navigateToBroadcast = true
+			case "Programar desligamento":
+				m.navigateToShutdown = true
+			case "Cancelar desligamento":
+				sysop, user := m.parent.sysop, m.parent.User
+				return m, func() tea.Msg {
+					if err := sysop.CancelShutdown(user); err != nil {
+						return errorMsg{err}
+					}
+					return sysopActionMsg{status: "Desligamento cancelado."}
+				}
+			}
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *sysopModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Avisos do Sistema") + "\n\n")
+	if at, reason, pending := m.parent.sysop.PendingShutdown(); pending {
+		status := fmt.Sprintf("Desligamento programado para %s", at.Local().Format("15:04:05"))
+		if reason != "" {
+			status += ": " + reason
+		}
+		b.WriteString(status + "\n\n")
+	} else {
+		b.WriteString("Nenhum desligamento programado.\n\n")
+	}
+
+	for i, choice := range m.choices {
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + choice))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + choice))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m *sysopModel) helpView() string {
+	return strings.Join([]string{
+		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+		m.keys.Enter.Help().Key + " selecionar",
+		m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+	}, " • ")
+}
@@ -3,7 +3,9 @@ package tui
 import (
 	"fmt"
 	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,14 +19,33 @@ type topicsModel struct {
 	topics        []*database.Topic
 	cursor        int
 	quitting      bool
-	navToPosts       *database.Topic // Tópico para o qual navegar
-	creatingTopic    bool            // Sinaliza se estamos criando um novo tópico
-	confirmingDelete bool
+	navToPosts    *database.Topic // Tópico para o qual navegar
+	creatingTopic bool            // Sinaliza se estamos criando um novo tópico
+	trashingTopic *database.Topic // Tópico a mover para a lixeira; abre o formulário do motivo
+	pager         pager
+	unread        map[int]int // Posts não lidos por tópico
+	canCreate     bool        // Permissões do usuário no fórum, carregadas com os tópicos
+	canModerate   bool
+	moderators    []string // Moderadores do fórum, exibidos no cabeçalho
+
+	// Tópicos de outros usuários criados com a lista aberta. Eles entram no topo e o
+	// cursor continua no tópico selecionado (keepTopicID) durante a recarga.
+	newTopics   int
+	keepTopicID int
 }
 
+// topicsPageSize é o número de tópicos buscados e exibidos por página.
+const topicsPageSize = 20
+
 type topicsLoadedMsg struct {
-	topics []*database.Topic
-	err    error
+	topics      []*database.Topic
+	unread      map[int]int
+	total       int
+	more        bool // Página seguinte, a ser anexada às já carregadas
+	canCreate   bool
+	canModerate bool
+	moderators  []string
+	err         error
 }
 
 type reloadTopicsMsg struct{}
@@ -35,13 +56,60 @@ func NewTopicsModel(parent *mainModel, forum *database.Forum) *topicsModel {
 		keys:   DefaultKeyMap,
 		parent: parent,
 		forum:  forum,
+		pager:  newPager(topicsPageSize),
 	}
 }
 
+// Init carrega a primeira página. Ao recarregar, busca de novo tantos tópicos quantos
+// já estavam carregados, para que o cursor continue na mesma página.
 func (m *topicsModel) Init() tea.Cmd {
+	return m.loadTopicsCmd(nil, max(len(m.topics), topicsPageSize), false)
+}
+
+// loadMore busca a página seguinte aos tópicos já carregados.
+func (m *topicsModel) loadMore() tea.Cmd {
+	if m.pager.loading || !m.pager.hasMore(len(m.topics)) || len(m.topics) == 0 {
+		return nil
+	}
+	return m.loadTopicsCmd(m.topics[len(m.topics)-1].Cursor(), topicsPageSize, true)
+}
+
+func (m *topicsModel) loadTopicsCmd(after *database.PageCursor, limit int, more bool) tea.Cmd {
+	m.pager.loading = true
+	forumID := int(m.forum.ID)
 	return func() tea.Msg {
-		topics, err := database.GetTopicsByForumID(int(m.forum.ID))
-		return topicsLoadedMsg{topics: topics, err: err}
+		total, err := m.parent.store.CountTopicsByForumID(m.parent.userID, forumID)
+		if err != nil {
+			return topicsLoadedMsg{err: err}
+		}
+		topics, err := m.parent.store.GetTopicsPageByForumID(m.parent.userID, forumID, after, limit)
+		if err != nil {
+			return topicsLoadedMsg{err: err}
+		}
+		ids := make([]int, len(topics))
+		for i, t := range topics {
+			ids[i] = t.ID
+		}
+		msg := topicsLoadedMsg{topics: topics, total: total, more: more}
+		moderators, err := m.parent.store.GetForumModerators(m.forum.ID)
+		if err != nil {
+			return topicsLoadedMsg{err: err}
+		}
+		for _, u := range moderators {
+			msg.moderators = append(msg.moderators, u.Username)
+		}
+		// Os visitantes não têm estado de leitura nem permissões além de ler.
+		if m.parent.readOnly() {
+			return msg
+		}
+		if msg.unread, msg.err = m.parent.store.GetTopicUnreadCounts(m.parent.userID, ids); msg.err != nil {
+			return msg
+		}
+		if msg.canCreate, msg.err = m.parent.store.HasForumPermission(m.parent.userID, m.forum.ID, database.PermissionTopic); msg.err != nil {
+			return msg
+		}
+		msg.canModerate, msg.err = m.parent.store.HasForumPermission(m.parent.userID, m.forum.ID, database.PermissionModerate)
+		return msg
 	}
 }
 
@@ -52,55 +120,80 @@ func (m *topicsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// TODO: Tratar o erro de forma mais elegante
 			return m, tea.Quit
 		}
-		m.topics = msg.topics
+		m.pager.loading = false
+		m.pager.total = msg.total
+		m.canCreate, m.canModerate = msg.canCreate, msg.canModerate
+		m.moderators = msg.moderators
+		if msg.more {
+			m.topics = append(m.topics, msg.topics...)
+		} else {
+			m.topics = msg.topics
+			m.unread = make(map[int]int)
+			for i, t := range m.topics {
+				if t.ID == m.keepTopicID {
+					m.cursor = i
+				}
+			}
+			m.keepTopicID = 0
+		}
+		for id, n := range msg.unread {
+			m.unread[id] = n
+		}
+		if m.pager.target >= 0 {
+			m.cursor = m.pager.target
+			m.pager.target = -1
+		}
+		m.cursor = max(min(m.cursor, len(m.topics)-1), 0)
+		if m.pager.takeStale() {
+			return m, m.reload()
+		}
 		return m, nil
 	case reloadTopicsMsg:
 		return m, m.Init()
 	case tea.KeyMsg:
-		if m.confirmingDelete {
-			switch msg.String() {
-			case "s", "S":
-				if len(m.topics) > 0 {
-					selectedTopicID := m.topics[m.cursor].ID
-					cmd := func() tea.Msg {
-						err := database.DeleteTopic(selectedTopicID)
-						if err != nil {
-							return statusMessage{success: false, message: "Erro ao deletar tópico: " + err.Error()}
-						}
-						return reloadTopicsMsg{}
-					}
-					m.confirmingDelete = false
-					return m, cmd
-				}
-			case "n", "N":
-				m.confirmingDelete = false
-				return m, nil
-			}
-			return m, nil // Não processa outras teclas durante a confirmação
-		}
-
 		switch {
 		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
 			}
+			if m.cursor == 0 {
+				m.newTopics = 0
+			}
 		case key.Matches(msg, m.keys.Down):
 			if m.cursor < len(m.topics)-1 {
 				m.cursor++
 			}
+			if m.cursor == len(m.topics)-1 {
+				return m, m.loadMore()
+			}
+		case key.Matches(msg, m.keys.PageUp):
+			m.cursor = max(m.cursor-topicsPageSize, 0)
+			if m.cursor == 0 {
+				m.newTopics = 0
+			}
+		case key.Matches(msg, m.keys.PageDown):
+			target := m.cursor + topicsPageSize
+			if target < len(m.topics) {
+				m.cursor = target
+			} else if cmd := m.loadMore(); cmd != nil {
+				m.pager.target = target
+				return m, cmd
+			} else {
+				m.cursor = max(len(m.topics)-1, 0)
+			}
 		case key.Matches(msg, m.keys.Enter):
 			if len(m.topics) > 0 {
 				m.navToPosts = m.topics[m.cursor]
 				return m, nil // Retorna para o mainModel que irá lidar com a navegação
 			}
 		case key.Matches(msg, m.keys.New):
-			if m.parent.Role == "admin" || m.parent.Role == "moderator" {
+			if m.canCreate {
 				m.creatingTopic = true
 				return m, nil // O mainModel irá lidar com a transição para o formulário
 			}
 		case key.Matches(msg, m.keys.Delete):
-			if (m.parent.Role == "admin" || m.parent.Role == "moderator") && len(m.topics) > 0 {
-				m.confirmingDelete = true
+			if m.canModerate && len(m.topics) > 0 {
+				m.trashingTopic = m.topics[m.cursor]
 			}
 		case key.Matches(msg, m.keys.Back):
 			return m, func() tea.Msg { return navigateBackMsg{} }
@@ -112,35 +205,95 @@ func (m *topicsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// handleEvent atualiza a lista quando outra sessão cria ou remove tópicos do fórum, ou
+// altera os seus posts, o que muda as contagens de não lidos.
+func (m *topicsModel) handleEvent(ev events.Event) tea.Cmd {
+	switch ev.Kind {
+	case events.TopicCreated:
+		// Os próprios tópicos já recarregam a tela ao serem criados.
+		if ev.ForumID != m.forum.ID || ev.UserID == int(m.parent.userID) {
+			return nil
+		}
+		m.newTopics++
+	case events.TopicDeleted, events.PostDeleted:
+		if ev.ForumID != m.forum.ID {
+			return nil
+		}
+	case events.PostCreated:
+		if ev.ForumID != m.forum.ID || ev.UserID == int(m.parent.userID) {
+			return nil
+		}
+	case events.PostEdited, events.TrashPurged:
+		// A lista de tópicos não mostra o conteúdo dos posts, e os itens removidos
+		// definitivamente já estavam ocultos.
+		return nil
+	case events.ForumDeleted:
+		if ev.ForumID != m.forum.ID {
+			return nil
+		}
+		m.parent.statusMessage = "Este fórum foi removido."
+		return tea.Batch(m.reload(), tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} }))
+	case events.TrashRestored:
+		if ev.ForumID != m.forum.ID {
+			return nil
+		}
+	}
+	return m.reload()
+}
+
+// reload recarrega os tópicos mantendo o cursor no tópico selecionado, ou agenda a
+// recarga se houver um carregamento em andamento.
+func (m *topicsModel) reload() tea.Cmd {
+	if m.pager.deferReload() {
+		return nil
+	}
+	if len(m.topics) > 0 {
+		m.keepTopicID = m.topics[m.cursor].ID
+	}
+	return m.Init()
+}
+
 func (m *topicsModel) View() string {
 	if m.quitting {
 		return ""
 	}
 
-	header := headerStyle.Render(fmt.Sprintf("Tópicos em '%s'", m.forum.Name))
+	header := m.parent.styles.header.Render(fmt.Sprintf("Tópicos em '%s'", m.forum.Name))
+	if len(m.moderators) > 0 {
+		header += "\n" + m.parent.styles.footer.Render("Moderadores: "+strings.Join(m.moderators, ", "))
+	}
 
 	body := ""
 	if len(m.topics) == 0 {
 		body = "Nenhum tópico encontrado.\n"
 	} else {
-		for i, topic := range m.topics {
+		start, end := m.pager.bounds(m.cursor, len(m.topics))
+		for i := start; i < end; i++ {
+			topic := m.topics[i]
+			line := fmt.Sprintf("%s (por %s)", topic.Title, topic.Username)
+			if n := m.unread[topic.ID]; n > 0 {
+				line += fmt.Sprintf(" • %d novos", n)
+			}
 			cursor := " "
 			if m.cursor == i {
 				cursor = ">"
-				body += selectedItemStyle.Render(fmt.Sprintf("%s %s (por %s)", cursor, topic.Title, topic.Username))
+				body += m.parent.styles.selectedItem.Render(fmt.Sprintf("%s %s", cursor, line))
 			} else {
-				body += itemStyle.Render(fmt.Sprintf("%s %s (por %s)", cursor, topic.Title, topic.Username))
+				body += m.parent.styles.item.Render(fmt.Sprintf("%s %s", cursor, line))
 			}
 			body += "\n"
 		}
+		body += "\n" + m.parent.styles.footer.Render(m.pager.status(m.cursor))
+		if m.pager.loading {
+			body += m.parent.styles.footer.Render(" • carregando...")
+		}
+		if m.newTopics > 0 {
+			body += " • " + m.parent.styles.highlight.Render(fmt.Sprintf("%d novo(s) tópico(s) no topo", m.newTopics))
+		}
+		body += "\n"
 	}
 
-	footer := footerStyle.Render(m.helpView())
-
-	if m.confirmingDelete && len(m.topics) > 0 {
-		topicTitle := m.topics[m.cursor].Title
-		body += fmt.Sprintf("\n\nTem certeza que deseja deletar o tópico '%s'? (s/n)", topicTitle)
-	}
+	footer := m.parent.styles.footer.Render(m.helpView())
 
 	return fmt.Sprintf("%s\n\n%s\n%s", header, body, footer)
 }
@@ -149,10 +302,13 @@ func (m *topicsModel) helpView() string {
 	help := []string{
 		m.keys.Up.Help().Key + " " + m.keys.Up.Help().Desc,
 		m.keys.Down.Help().Key + " " + m.keys.Down.Help().Desc,
+		m.keys.PageUp.Help().Key + "/" + m.keys.PageDown.Help().Key + " páginas",
 	}
 
-	if m.parent.Role == "admin" || m.parent.Role == "moderator" {
+	if m.canCreate {
 		help = append(help, m.keys.New.Help().Key+" "+m.keys.New.Help().Desc)
+	}
+	if m.canModerate {
 		help = append(help, m.keys.Delete.Help().Key+" "+m.keys.Delete.Help().Desc)
 	}
 
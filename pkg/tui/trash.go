@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"modern-bbs/internal/events"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trashTitleWidth é o número máximo de caracteres do título exibido na lista.
+const trashTitleWidth = 60
+
+// trashModel representa a tela "Lixeira", em que administradores restauram ou removem
+// definitivamente os fóruns, tópicos e posts apagados.
+type trashModel struct {
+	keys            *KeyMap
+	parent          *mainModel
+	items           []database.TrashItem
+	cursor          int
+	loaded          bool
+	confirmingPurge bool
+	quitting        bool
+}
+
+type trashLoadedMsg struct {
+	items []database.TrashItem
+	err   error
+}
+
+// trashActionMsg informa o resultado de uma ação sobre a lixeira: mover um item para
+// ela, restaurá-lo ou removê-lo. Os formulários voltam para a tela back.
+type trashActionMsg struct {
+	status string
+	back   view
+}
+
+// NewTrashModel cria a tela da lixeira.
+func NewTrashModel(parent *mainModel) *trashModel {
+	return &trashModel{
+		keys:   DefaultKeyMap,
+		parent: parent,
+	}
+}
+
+// Init carrega os itens da lixeira.
+func (m *trashModel) Init() tea.Cmd {
+	store := m.parent.store
+	return func() tea.Msg {
+		items, err := store.GetTrash()
+		return trashLoadedMsg{items: items, err: err}
+	}
+}
+
+// handleEvent recarrega a lixeira quando outra sessão apaga, restaura ou remove itens.
+func (m *trashModel) handleEvent(ev events.Event) tea.Cmd {
+	switch ev.Kind {
+	case events.ForumDeleted, events.TopicDeleted, events.PostDeleted, events.TrashRestored, events.TrashPurged:
+		return m.Init()
+	}
+	return nil
+}
+
+func (m *trashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case trashLoadedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		m.loaded = true
+		m.items = msg.items
+		m.cursor = max(min(m.cursor, len(m.items)-1), 0)
+	case tea.KeyMsg:
+		if m.confirmingPurge {
+			switch msg.String() {
+			case "s", "S":
+				m.confirmingPurge = false
+				if len(m.items) > 0 {
+					item := m.items[m.cursor]
+					return m, func() tea.Msg {
+						if err := m.parent.store.PurgeTrashItem(item.Kind, item.ID); err != nil {
+							return errorMsg{err}
+						}
+						return trashActionMsg{status: fmt.Sprintf("%s removido definitivamente.", capitalize(item.Kind.Label())), back: trashView}
+					}
+				}
+			case "n", "N":
+				m.confirmingPurge = false
+			}
+			return m, nil // Não processa outras teclas durante a confirmação
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Restore):
+			if len(m.items) > 0 {
+				item := m.items[m.cursor]
+				return m, func() tea.Msg {
+					if err := m.parent.store.RestoreTrashItem(item.Kind, item.ID); err != nil {
+						return errorMsg{err}
+					}
+					return trashActionMsg{status: fmt.Sprintf("%s restaurado.", capitalize(item.Kind.Label())), back: trashView}
+				}
+			}
+		case key.Matches(msg, m.keys.Delete):
+			if len(m.items) > 0 {
+				m.confirmingPurge = true
+			}
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// capitalize coloca em maiúscula a primeira letra de s.
+func capitalize(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+// trashItemLine descreve o item em uma linha: o tipo, o título (ou o início do post) e
+// onde ele estava.
+func trashItemLine(item database.TrashItem) string {
+	title := []rune(strings.Join(strings.Fields(item.Title), " "))
+	if len(title) > trashTitleWidth {
+		title = append(title[:trashTitleWidth-1], '…')
+	}
+	line := fmt.Sprintf("[%s] %s", item.Kind.Label(), string(title))
+	switch item.Kind {
+	case database.TrashTopic:
+		line += fmt.Sprintf(" (em %s, por %s)", item.Forum, item.Author)
+	case database.TrashPost:
+		line += fmt.Sprintf(" (em %s > %s, por %s)", item.Forum, item.Topic, item.Author)
+	}
+	return line
+}
+
+func (m *trashModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Lixeira") + "\n\n")
+
+	if !m.loaded {
+		return b.String() + "Carregando..."
+	}
+	if len(m.items) == 0 {
+		return b.String() + "A lixeira está vazia.\n"
+	}
+
+	for i, item := range m.items {
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + trashItemLine(item)))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + trashItemLine(item)))
+		}
+		b.WriteString("\n")
+	}
+
+	item := m.items[m.cursor]
+	deletedBy := item.DeletedBy
+	if deletedBy == "" {
+		deletedBy = "administração"
+	}
+	details := fmt.Sprintf("Apagado por %s em %s", deletedBy, item.DeletedAt.Local().Format(time.RFC822))
+	if item.Reason != "" {
+		details += ". Motivo: " + item.Reason
+	}
+	b.WriteString("\n" + m.parent.styles.footer.Render(details) + "\n")
+
+	if m.confirmingPurge {
+		b.WriteString(fmt.Sprintf("\nRemover definitivamente o %s selecionado? Não será possível desfazer. (s/n)\n", item.Kind.Label()))
+	}
+	return b.String()
+}
+
+func (m *trashModel) helpView() string {
+	return strings.Join([]string{
+		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+		m.keys.Restore.Help().Key + " " + m.keys.Restore.Help().Desc,
+		m.keys.Delete.Help().Key + " remover definitivamente",
+		m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+	}, " • ")
+}
@@ -0,0 +1,373 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"modern-bbs/internal/qr"
+	"modern-bbs/internal/totp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// twoFactorIssuer identifica o BBS nos aplicativos autenticadores.
+const twoFactorIssuer = "Modern BBS"
+
+// twoFactorEnrollment conduz a ativação da verificação em duas etapas: mostra o segredo
+// e o código QR, confere o primeiro código gerado pelo aplicativo e, só então, grava o
+// segredo e exibe os códigos de recuperação. É usada em Configurações e na ativação
+// obrigatória feita antes de entrar no BBS.
+type twoFactorEnrollment struct {
+	store    database.Store
+	username string
+	secret   string
+	qr       string // Código QR já desenhado, vazio se o endereço não couber
+	input    textinput.Model
+	err      string
+	codes    []string // Códigos de recuperação, preenchidos quando a ativação termina
+}
+
+type twoFactorEnabledMsg struct {
+	codes []string
+	err   error
+}
+
+func newTwoFactorEnrollment(store database.Store, username string) (*twoFactorEnrollment, error) {
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	input := textinput.New()
+	input.Placeholder = fmt.Sprintf("Código de %d dígitos", totp.Digits)
+	input.CharLimit = totp.Digits
+	input.Width = 20
+	input.Focus()
+
+	e := &twoFactorEnrollment{store: store, username: username, secret: secret, input: input}
+	if code, err := qr.Encode(totp.URI(twoFactorIssuer, username, secret)); err == nil {
+		e.qr = renderQR(code)
+	}
+	return e, nil
+}
+
+// done informa se a verificação já foi ativada.
+func (e *twoFactorEnrollment) done() bool {
+	return e.codes != nil
+}
+
+func (e *twoFactorEnrollment) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case twoFactorEnabledMsg:
+		if msg.err != nil {
+			e.err = msg.err.Error()
+			return nil
+		}
+		e.codes = msg.codes
+		return nil
+	case tea.KeyMsg:
+		if e.done() {
+			return nil
+		}
+		if msg.Type == tea.KeyEnter {
+			return e.confirm()
+		}
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return cmd
+}
+
+// confirm confere o código digitado e, se ele estiver certo, ativa a verificação.
+func (e *twoFactorEnrollment) confirm() tea.Cmd {
+	step, ok := totp.Verify(e.secret, e.input.Value(), time.Now())
+	if !ok {
+		e.err = "código incorreto; confira o relógio do aparelho"
+		e.input.SetValue("")
+		return nil
+	}
+	e.err = ""
+
+	store, username, secret := e.store, e.username, e.secret
+	return func() tea.Msg {
+		codes, err := totp.NewRecoveryCodes()
+		if err != nil {
+			return twoFactorEnabledMsg{err: err}
+		}
+		if err := store.EnableTOTP(username, secret, codes); err != nil {
+			return twoFactorEnabledMsg{err: err}
+		}
+		// O código usado na confirmação não pode ser reaproveitado no próximo login.
+		if _, err := store.UseTOTPStep(username, step); err != nil {
+			return twoFactorEnabledMsg{err: err}
+		}
+		return twoFactorEnabledMsg{codes: codes}
+	}
+}
+
+func (e *twoFactorEnrollment) view(st *styles) string {
+	if e.done() {
+		return st.statusMessage.Render("Verificação em duas etapas ativada!") + "\n\n" + viewRecoveryCodes(e.codes)
+	}
+
+	var b strings.Builder
+	b.WriteString("1. Leia o código QR com um aplicativo autenticador\n")
+	b.WriteString("   (Google Authenticator, Aegis, 1Password...).\n\n")
+	b.WriteString("2. Se preferir, digite o segredo no aplicativo:\n\n")
+	b.WriteString("   " + st.highlight.Render(groupSecret(e.secret)) + "\n\n")
+	b.WriteString("3. Informe o código exibido pelo aplicativo:\n\n")
+	b.WriteString("   " + e.input.View() + "\n")
+	if e.err != "" {
+		b.WriteString("\n" + st.errorStatusMessage.Render("Erro: "+e.err) + "\n")
+	}
+
+	if e.qr == "" {
+		return b.String()
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, e.qr, "  ", b.String())
+}
+
+// groupSecret separa o segredo em grupos de quatro letras, para facilitar a digitação.
+func groupSecret(secret string) string {
+	var groups []string
+	for len(secret) > 4 {
+		groups = append(groups, secret[:4])
+		secret = secret[4:]
+	}
+	return strings.Join(append(groups, secret), " ")
+}
+
+// viewRecoveryCodes exibe os códigos de recuperação, que só são mostrados uma vez.
+func viewRecoveryCodes(codes []string) string {
+	var b strings.Builder
+	b.WriteString("Guarde estes códigos de recuperação em um lugar seguro. Cada um permite\n")
+	b.WriteString("entrar uma vez sem o aplicativo, e eles não serão exibidos novamente:\n\n")
+	for i := 0; i < len(codes); i += 2 {
+		b.WriteString("   " + codes[i])
+		if i+1 < len(codes) {
+			b.WriteString("    " + codes[i+1])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderQR desenha o código com meios blocos, duas linhas de módulos por linha do
+// terminal. Os módulos claros são desenhados com a cor do texto, o que pressupõe um
+// terminal de fundo escuro; a margem de dois módulos fica clara.
+func renderQR(code *qr.Code) string {
+	const margin = 2
+	var b strings.Builder
+	for y := -margin; y < code.Size+margin; y += 2 {
+		for x := -margin; x < code.Size+margin; x++ {
+			top, bottom := !code.Dark(x, y), !code.Dark(x, y+1) && y+1 < code.Size+margin
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// twoFactorSetupModel é o programa executado quando o papel do usuário exige a
+// verificação em duas etapas e ela ainda não foi ativada. Depois da ativação, o usuário
+// se conecta novamente, já passando pelo código do aplicativo.
+type twoFactorSetupModel struct {
+	enrollment *twoFactorEnrollment
+	styles     *styles
+	err        error
+}
+
+// NewTwoFactorSetupModel cria o programa de ativação obrigatória da verificação em duas etapas.
+func NewTwoFactorSetupModel(store database.Store, username string, renderer *lipgloss.Renderer) tea.Model {
+	enrollment, err := newTwoFactorEnrollment(store, username)
+	return &twoFactorSetupModel{enrollment: enrollment, styles: newStyles(renderer), err: err}
+}
+
+func (m *twoFactorSetupModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *twoFactorSetupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DisconnectMsg:
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if m.err != nil || m.enrollment.done() {
+			return m, tea.Quit
+		}
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		}
+	}
+	if m.err != nil {
+		return m, nil
+	}
+	return m, m.enrollment.update(msg)
+}
+
+func (m *twoFactorSetupModel) View() string {
+	var b strings.Builder
+	b.WriteString(m.styles.header.Render("Verificação em Duas Etapas") + "\n\n")
+	if m.err != nil {
+		b.WriteString(m.styles.errorStatusMessage.Render("Erro: "+m.err.Error()) + "\n")
+		return b.String()
+	}
+
+	if m.enrollment.done() {
+		b.WriteString(m.enrollment.view(m.styles))
+		b.WriteString("\n" + m.styles.footer.Render("Pressione qualquer tecla para sair e conecte-se novamente usando o código do aplicativo."))
+		return b.String()
+	}
+	b.WriteString("O seu papel exige a verificação em duas etapas. Ative-a para continuar.\n\n")
+	b.WriteString(m.enrollment.view(m.styles))
+	b.WriteString("\n" + m.styles.footer.Render("(Enter confirma, Esc cancela)"))
+	return b.String()
+}
+
+type twoFactorLoadedMsg struct {
+	enabled      bool
+	recoveryLeft int
+}
+
+// recoveryCodesMsg traz os códigos de recuperação recém-gerados em Configurações.
+type recoveryCodesMsg struct{ codes []string }
+
+// loadTwoFactorCmd verifica se o usuário ativou a verificação em duas etapas e quantos
+// códigos de recuperação ainda restam.
+func (m *settingsModel) loadTwoFactorCmd() tea.Msg {
+	secret, err := m.parent.store.GetTOTPSecret(m.parent.User)
+	if err != nil {
+		return errorMsg{err}
+	}
+	left, err := m.parent.store.CountRecoveryCodes(m.parent.User)
+	if err != nil {
+		return errorMsg{err}
+	}
+	return twoFactorLoadedMsg{enabled: secret != "", recoveryLeft: left}
+}
+
+// updateTwoFactor lida com as teclas na tela de verificação em duas etapas.
+func (m *settingsModel) updateTwoFactor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.recoveryCodes != nil || (m.enrollment != nil && m.enrollment.done()) {
+		// Os códigos de recuperação ficam na tela até que o usuário pressione uma tecla.
+		m.recoveryCodes = nil
+		m.enrollment = nil
+		return m, m.loadTwoFactorCmd
+	}
+	if m.enrollment != nil {
+		if key.Matches(msg, m.keys.Back) {
+			m.enrollment = nil
+			return m, nil
+		}
+		return m, m.enrollment.update(msg)
+	}
+
+	if m.confirmingDisable {
+		switch msg.String() {
+		case "s", "S":
+			m.confirmingDisable = false
+			return m, func() tea.Msg {
+				if err := m.parent.store.DisableTOTP(m.parent.User); err != nil {
+					return statusMessage{success: false, message: err.Error()}
+				}
+				return statusMessage{success: true, message: "Verificação em duas etapas desativada."}
+			}
+		case "n", "N":
+			m.confirmingDisable = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.String() == "a" && !m.twoFactorEnabled:
+		enrollment, err := newTwoFactorEnrollment(m.parent.store, m.parent.User)
+		if err != nil {
+			return m, func() tea.Msg { return errorMsg{err} }
+		}
+		m.enrollment = enrollment
+	case msg.String() == "r" && m.twoFactorEnabled:
+		return m, func() tea.Msg {
+			codes, err := totp.NewRecoveryCodes()
+			if err != nil {
+				return errorMsg{err}
+			}
+			if err := m.parent.store.SetRecoveryCodes(m.parent.User, codes); err != nil {
+				return statusMessage{success: false, message: err.Error()}
+			}
+			return recoveryCodesMsg{codes: codes}
+		}
+	case key.Matches(msg, m.keys.Delete) && m.twoFactorEnabled:
+		if m.parent.twoFactorRequired {
+			return m, func() tea.Msg {
+				return statusMessage{success: false, message: "a verificação em duas etapas é obrigatória para o seu papel"}
+			}
+		}
+		m.confirmingDisable = true
+	case key.Matches(msg, m.keys.Back):
+		m.managingTwoFactor = false
+		m.confirmingDisable = false
+	}
+	return m, nil
+}
+
+func (m *settingsModel) viewTwoFactor() string {
+	if m.recoveryCodes != nil {
+		return viewRecoveryCodes(m.recoveryCodes)
+	}
+	if m.enrollment != nil {
+		return m.enrollment.view(m.parent.styles)
+	}
+
+	var b strings.Builder
+	b.WriteString("Verificação em duas etapas:\n\n")
+	if !m.twoFactorEnabled {
+		b.WriteString("Desativada. Com ela, o login pede também um código gerado por um\n")
+		b.WriteString("aplicativo autenticador, depois da senha ou da chave SSH.\n")
+		if m.parent.twoFactorRequired {
+			b.WriteString("\nO seu papel exige a verificação em duas etapas.\n")
+		}
+		return b.String()
+	}
+
+	b.WriteString("Ativada. O login pede o código do aplicativo autenticador depois da senha\n")
+	b.WriteString("ou da chave SSH; um código de recuperação pode substituí-lo.\n\n")
+	left := fmt.Sprintf("Códigos de recuperação restantes: %d de %d", m.recoveryLeft, totp.RecoveryCodeCount)
+	if m.recoveryLeft <= 2 {
+		left = m.parent.styles.errorStatusMessage.Render(left + " (gere novos códigos)")
+	}
+	b.WriteString(left + "\n")
+
+	if m.confirmingDisable {
+		b.WriteString("\nTem certeza que deseja desativar a verificação em duas etapas? (s/n)\n")
+	}
+	return b.String()
+}
+
+func (m *settingsModel) twoFactorHelp() string {
+	back := m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc
+	switch {
+	case m.recoveryCodes != nil || (m.enrollment != nil && m.enrollment.done()):
+		return "qualquer tecla continua"
+	case m.enrollment != nil:
+		return "enter confirma • " + m.keys.Back.Help().Key + " cancela"
+	case m.twoFactorEnabled:
+		return fmt.Sprintf("r gerar novos códigos de recuperação • %s desativar • %s",
+			m.keys.Delete.Help().Key, back)
+	}
+	return "a ativar • " + back
+}
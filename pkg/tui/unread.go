@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/database"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// unreadTopicsLimit limita a lista de novidades aos tópicos com atividade mais recente.
+const unreadTopicsLimit = 100
+
+// unreadModel representa a visão "Novidades": os tópicos com posts ainda não lidos.
+type unreadModel struct {
+	keys       *KeyMap
+	parent     *mainModel
+	topics     []database.UnreadTopic
+	cursor     int
+	loaded     bool
+	quitting   bool
+	navToPosts *database.Topic // Tópico para o qual navegar
+}
+
+type unreadTopicsLoadedMsg struct {
+	topics []database.UnreadTopic
+	err    error
+}
+
+// NewUnreadModel cria um novo modelo para a visão de novidades.
+func NewUnreadModel(parent *mainModel) *unreadModel {
+	return &unreadModel{
+		keys:   DefaultKeyMap,
+		parent: parent,
+	}
+}
+
+func (m *unreadModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		topics, err := m.parent.store.GetUnreadTopics(m.parent.userID, unreadTopicsLimit)
+		return unreadTopicsLoadedMsg{topics: topics, err: err}
+	}
+}
+
+func (m *unreadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case unreadTopicsLoadedMsg:
+		if msg.err != nil {
+			return m, func() tea.Msg { return errorMsg{msg.err} }
+		}
+		m.topics = msg.topics
+		m.loaded = true
+		m.cursor = max(min(m.cursor, len(m.topics)-1), 0)
+		return m, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.topics)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Enter):
+			if len(m.topics) > 0 {
+				topic := m.topics[m.cursor].Topic
+				m.navToPosts = &topic
+				return m, nil // O mainModel irá lidar com a navegação
+			}
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *unreadModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Novidades desde a última visita") + "\n\n")
+
+	if !m.loaded {
+		return b.String()
+	}
+	if len(m.topics) == 0 {
+		b.WriteString("Nenhum post novo. Você está em dia!\n")
+		return b.String()
+	}
+
+	for i, t := range m.topics {
+		line := fmt.Sprintf("%s > %s (por %s) • %d novos", t.ForumName, t.Title, t.Username, t.Unread)
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (m *unreadModel) helpView() string {
+	help := []string{
+		m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar",
+		m.keys.Enter.Help().Key + " ler a partir do primeiro não lido",
+		m.keys.Back.Help().Key + " " + m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key + " " + m.keys.Quit.Help().Desc,
+	}
+	return strings.Join(help, " • ")
+}
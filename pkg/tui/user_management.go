@@ -8,13 +8,12 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-
 type userManagementModel struct {
-	parent          *mainModel
-	users           []database.User
-	cursor          int
-	keys            *KeyMap
-	selectedUser    *database.User
+	parent       *mainModel
+	users        []database.User
+	cursor       int
+	keys         *KeyMap
+	selectedUser *database.User
 	// State for action selection
 	isSelectingAction bool
 	actionCursor      int
@@ -36,7 +35,7 @@ func NewUserManagementModel(parent *mainModel) *userManagementModel {
 
 func (m *userManagementModel) Init() tea.Cmd {
 	return func() tea.Msg {
-		users, err := database.GetAllUsers()
+		users, err := m.parent.store.GetAllUsers()
 		if err != nil {
 			return errorMsg{err}
 		}
@@ -49,13 +48,13 @@ func (m *userManagementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case usersLoadedMsg:
 		m.users = msg.users
 	case tea.KeyMsg:
-			if m.isSelectingRole {
-				return m.updateRoleSelection(msg)
-			} else if m.isSelectingAction {
-				return m.updateActionSelection(msg)
-			} else {
-				return m.updateUserList(msg)
-			}
+		if m.isSelectingRole {
+			return m.updateRoleSelection(msg)
+		} else if m.isSelectingAction {
+			return m.updateActionSelection(msg)
+		} else {
+			return m.updateUserList(msg)
+		}
 	}
 	return m, nil
 }
@@ -75,6 +74,10 @@ func (m *userManagementModel) updateUserList(msg tea.KeyMsg) (tea.Model, tea.Cmd
 			m.selectedUser = &m.users[m.cursor]
 			m.isSelectingAction = true
 			m.actionCursor = 0 // Reset cursor
+			m.actionChoices = []string{"Alterar Papel", "Deletar Usuário", "Resetar Senha"}
+			if m.selectedUser.Pending() {
+				m.actionChoices = append([]string{"Aprovar Cadastro"}, m.actionChoices...)
+			}
 		}
 	case key.Matches(msg, m.keys.Back):
 		return m, func() tea.Msg { return navigateBackMsg{} }
@@ -95,6 +98,17 @@ func (m *userManagementModel) updateActionSelection(msg tea.KeyMsg) (tea.Model,
 	case key.Matches(msg, m.keys.Enter):
 		selectedAction := m.actionChoices[m.actionCursor]
 		switch selectedAction {
+		case "Aprovar Cadastro":
+			username := m.selectedUser.Username
+			err := m.parent.store.ApproveUser(username)
+			m.isSelectingAction = false
+			m.selectedUser = nil
+			if err != nil {
+				return m, func() tea.Msg { return statusMessage{success: false, message: err.Error()} }
+			}
+			return m, tea.Sequence(m.Init(), func() tea.Msg {
+				return statusMessage{success: true, message: fmt.Sprintf("Cadastro de %s aprovado", username)}
+			})
 		case "Alterar Papel":
 			m.isSelectingAction = false
 			m.isSelectingRole = true
@@ -102,7 +116,7 @@ func (m *userManagementModel) updateActionSelection(msg tea.KeyMsg) (tea.Model,
 		case "Deletar Usuário":
 			// Lógica para deletar usuário aqui
 			username := m.selectedUser.Username
-			err := database.DeleteUser(username)
+			err := m.parent.store.DeleteUser(username)
 			m.isSelectingAction = false
 			m.selectedUser = nil
 			if err != nil {
@@ -114,7 +128,7 @@ func (m *userManagementModel) updateActionSelection(msg tea.KeyMsg) (tea.Model,
 		case "Resetar Senha":
 			// Lógica para resetar senha aqui
 			username := m.selectedUser.Username
-			err := database.AdminResetPassword(username, "password") // Nova senha padrão
+			err := m.parent.store.AdminResetPassword(username, "password") // Nova senha padrão
 			m.isSelectingAction = false
 			m.selectedUser = nil
 			if err != nil {
@@ -145,7 +159,7 @@ func (m *userManagementModel) updateRoleSelection(msg tea.KeyMsg) (tea.Model, te
 		selectedRole := m.roleChoices[m.roleCursor]
 		username := m.selectedUser.Username // Salva o nome de usuário antes de limpar
 
-		err := database.SetUserRole(username, selectedRole)
+		err := m.parent.store.SetUserRole(username, selectedRole)
 		m.isSelectingRole = false
 		m.selectedUser = nil
 
@@ -171,13 +185,25 @@ func (m *userManagementModel) View() string {
 		return m.viewActionSelection()
 	}
 
+	names := make(map[int64]string, len(m.users))
+	for _, user := range m.users {
+		names[user.ID] = user.Username
+	}
+
 	body := "Gerenciamento de Usuários:\n\n"
 	for i, user := range m.users {
 		cursor := " "
 		if m.cursor == i {
 			cursor = ">"
 		}
-		body += fmt.Sprintf("%s %s (%s)\n", cursor, user.Username, user.Role)
+		status := ""
+		if user.Pending() {
+			status = ", aguardando aprovação"
+		}
+		if name, ok := names[user.InvitedBy]; ok {
+			status += ", convidado por " + name
+		}
+		body += fmt.Sprintf("%s %s (%s%s)\n", cursor, user.Username, user.Role, status)
 	}
 	return body
 }
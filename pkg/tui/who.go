@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+	"modern-bbs/internal/session"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// whoRefreshInterval é o intervalo de atualização da lista de quem está online.
+const whoRefreshInterval = 5 * time.Second
+
+// whoModel representa a tela "Quem está online". Administradores podem desconectar sessões.
+type whoModel struct {
+	keys       *KeyMap
+	parent     *mainModel
+	sessions   []session.Info
+	cursor     int
+	quitting   bool
+	confirming bool
+	generation int // Descarta os ticks de aberturas anteriores da tela
+}
+
+type whoTickMsg struct{ generation int }
+
+// NewWhoModel cria a tela de quem está online.
+func NewWhoModel(parent *mainModel) *whoModel {
+	return &whoModel{
+		keys:   DefaultKeyMap,
+		parent: parent,
+	}
+}
+
+// Init carrega a lista e agenda as atualizações periódicas.
+func (m *whoModel) Init() tea.Cmd {
+	m.generation++
+	m.parent.touchSession() // A própria sessão já aparece nesta tela
+	m.refresh()
+	return m.tick()
+}
+
+func (m *whoModel) tick() tea.Cmd {
+	generation := m.generation
+	return tea.Tick(whoRefreshInterval, func(time.Time) tea.Msg { return whoTickMsg{generation: generation} })
+}
+
+func (m *whoModel) refresh() {
+	m.sessions = m.parent.sessions.List()
+
+	// A tela de quem está em um fórum que o usuário não pode ver fica em branco.
+	visible := make(map[int64]bool)
+	if forums, err := m.parent.store.GetVisibleForums(m.parent.userID); err == nil {
+		for _, f := range forums {
+			visible[f.ID] = true
+		}
+	}
+	for i := range m.sessions {
+		if id := m.sessions[i].ForumID; id != 0 && !visible[id] {
+			m.sessions[i].View = ""
+		}
+	}
+	m.cursor = max(min(m.cursor, len(m.sessions)-1), 0)
+}
+
+func (m *whoModel) canKick() bool {
+	return m.parent.Role == "admin"
+}
+
+func (m *whoModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case whoTickMsg:
+		if msg.generation != m.generation || m.parent.currentView != whoView {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.tick()
+	case tea.KeyMsg:
+		if m.confirming {
+			switch msg.String() {
+			case "s", "S":
+				m.confirming = false
+				target := m.sessions[m.cursor]
+				if err := m.parent.sessions.Kick(target.ID); err != nil {
+					return m, func() tea.Msg { return errorMsg{err} }
+				}
+				m.refresh()
+				m.parent.statusMessage = fmt.Sprintf("Sessão de %s desconectada.", target.Username)
+				return m, tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return statusMessageTimeoutMsg{} })
+			case "n", "N", "esc":
+				m.confirming = false
+			}
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.sessions)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, m.keys.Delete):
+			if m.canKick() && len(m.sessions) > 0 {
+				if m.sessions[m.cursor].ID == m.parent.session.ID() {
+					return m, func() tea.Msg { return errorMsg{fmt.Errorf("você não pode desconectar a própria sessão")} }
+				}
+				m.confirming = true
+			}
+		case key.Matches(msg, m.keys.Back):
+			return m, func() tea.Msg { return navigateBackMsg{} }
+		case key.Matches(msg, m.keys.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *whoModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.parent.styles.header.Render("Quem está online") + "\n\n")
+	b.WriteString(fmt.Sprintf("%d sessão(ões) conectada(s)\n\n", len(m.sessions)))
+
+	// O endereço de origem só aparece para a equipe.
+	staff := m.parent.Role == "admin" || m.parent.Role == "moderator"
+	columns := func(user, addr, connected, idle, view string) string {
+		if staff {
+			return fmt.Sprintf("%-16s %-21s %-9s %-9s %s", user, addr, connected, idle, view)
+		}
+		return fmt.Sprintf("%-16s %-9s %-9s %s", user, connected, idle, view)
+	}
+	b.WriteString(m.parent.styles.footer.Render("  "+columns("Usuário", "Endereço", "Conectado", "Inativo", "Tela")) + "\n")
+
+	now := time.Now()
+	for i, s := range m.sessions {
+		user := s.Username
+		if s.ID == m.parent.session.ID() {
+			user += " (você)"
+		}
+		line := columns(user, s.RemoteAddr, formatDuration(now.Sub(s.ConnectedAt)),
+			formatDuration(now.Sub(s.LastActivity)), s.View)
+		if i == m.cursor {
+			b.WriteString(m.parent.styles.selectedItem.Render("> " + line))
+		} else {
+			b.WriteString(m.parent.styles.item.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	if m.confirming {
+		b.WriteString("\n" + m.parent.styles.errorStatusMessage.Render(
+			fmt.Sprintf("Desconectar a sessão de %s? (s/n)", m.sessions[m.cursor].Username)) + "\n")
+	}
+	return b.String()
+}
+
+func (m *whoModel) helpView() string {
+	if m.confirming {
+		return "s confirmar • n cancelar"
+	}
+	help := []string{m.keys.Up.Help().Key + "/" + m.keys.Down.Help().Key + " navegar"}
+	if m.canKick() {
+		help = append(help, m.keys.Delete.Help().Key+" desconectar")
+	}
+	help = append(help,
+		m.keys.Back.Help().Key+" "+m.keys.Back.Help().Desc,
+		m.keys.Quit.Help().Key+" "+m.keys.Quit.Help().Desc,
+	)
+	return strings.Join(help, " • ")
+}
+
+// formatDuration resume uma duração para a lista de sessões (ex.: "45s", "12min", "3h05").
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dmin", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+}